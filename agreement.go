@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// sourceAgreement summarizes how many cited sources the models have in common.
+type sourceAgreement struct {
+	Models    []Provider // Successful models with citations, in result order
+	Sources   int        // Distinct sources across all models
+	Consensus int        // Sources cited by two or more models
+	Shared    [][]int    // Shared[i][j]: sources cited by both Models[i] and Models[j]; Shared[i][i] is Models[i]'s total
+}
+
+// Percent is the share of sources cited by two or more models.
+func (a sourceAgreement) Percent() float64 {
+	if a.Sources == 0 {
+		return 0
+	}
+	return float64(a.Consensus) / float64(a.Sources) * 100
+}
+
+// trackingParams are query parameters dropped by normalizeURL.
+var trackingParams = map[string]bool{"fbclid": true, "gclid": true, "ref": true, "ref_src": true}
+
+// normalizeURL reduces a URL to a comparison key: scheme, "www.", fragment,
+// trailing slash, and tracking parameters (utm_*, fbclid, ...) are dropped and
+// the remaining parameters sorted.
+func normalizeURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return rawURL
+	}
+	q := u.Query()
+	for k := range q {
+		if strings.HasPrefix(k, "utm_") || trackingParams[k] {
+			q.Del(k)
+		}
+	}
+	key := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.") + strings.TrimSuffix(u.EscapedPath(), "/")
+	if len(q) > 0 {
+		key += "?" + q.Encode()
+	}
+	return key
+}
+
+// computeAgreement groups every model's citations into sources, matching by
+// normalized URL or as the same story under another URL, and counts which
+// models cite each one.
+func computeAgreement(results []ModelResult) sourceAgreement {
+	type source struct {
+		keys   map[string]bool
+		rep    Citation
+		models map[int]bool
+	}
+	var a sourceAgreement
+	var sources []*source
+
+	for _, mr := range results {
+		if mr.Result.Error != nil || len(mr.Result.Citations) == 0 {
+			continue
+		}
+		m := len(a.Models)
+		a.Models = append(a.Models, mr.Provider)
+
+		for _, c := range mr.Result.Citations {
+			key := normalizeURL(c.URL)
+			var match *source
+			for _, s := range sources {
+				if s.keys[key] || sameStory(s.rep, c) {
+					match = s
+					break
+				}
+			}
+			if match == nil {
+				match = &source{keys: make(map[string]bool), rep: c, models: make(map[int]bool)}
+				sources = append(sources, match)
+			}
+			match.keys[key] = true
+			match.models[m] = true
+		}
+	}
+
+	a.Sources = len(sources)
+	a.Shared = make([][]int, len(a.Models))
+	for i := range a.Shared {
+		a.Shared[i] = make([]int, len(a.Models))
+	}
+	for _, s := range sources {
+		if len(s.models) >= 2 {
+			a.Consensus++
+		}
+		for i := range s.models {
+			for j := range s.models {
+				a.Shared[i][j]++
+			}
+		}
+	}
+	return a
+}
+
+// printSourceAgreement shows consensus vs. single-model sources and, with three
+// or more models, a pairwise overlap matrix. Needs at least two models with citations.
+func printSourceAgreement(results []ModelResult) {
+	a := computeAgreement(results)
+	if len(a.Models) < 2 {
+		return
+	}
+
+	fmt.Println()
+	fmt.Println("🤝 Source Agreement:")
+	fmt.Println(strings.Repeat("─", 70))
+	fmt.Printf("   %d of %d sources cited by 2+ models (%.0f%% consensus), %d unique to one model\n",
+		a.Consensus, a.Sources, a.Percent(), a.Sources-a.Consensus)
+
+	switch {
+	case a.Consensus == 0:
+		fmt.Printf("   %s\n", yellow("⚠️  No shared sources: models relied on entirely different evidence"))
+	case a.Percent() >= 50:
+		fmt.Printf("   %s\n", green("✅ High overlap: the topic looks well covered"))
+	}
+
+	if len(a.Models) < 3 {
+		return
+	}
+	width := 0
+	for _, p := range a.Models {
+		width = max(width, len(p.Name()))
+	}
+	fmt.Printf("\n   %-*s", width, "")
+	for _, p := range a.Models {
+		fmt.Printf("  %*s", width, p.Name())
+	}
+	fmt.Println()
+	for i, p := range a.Models {
+		fmt.Printf("   %-*s", width, p.Name())
+		for j := range a.Models {
+			cell := fmt.Sprintf("%*d", width, a.Shared[i][j])
+			if i == j {
+				cell = dim(cell)
+			}
+			fmt.Printf("  %s", cell)
+		}
+		fmt.Println()
+	}
+	fmt.Printf("   %s\n", dim("(shared sources per pair; diagonal is each model's total)"))
+}
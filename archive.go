@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	archiveMaxBytes    = 10 << 20 // Largest page saved; bigger pages are truncated
+	archiveConcurrency = 8
+	archiveTimeout     = 15 * time.Second
+)
+
+// -archive settings
+var (
+	archiveEnabled bool
+	archiveDir     = "web-search-archive"
+)
+
+// archiveManifest is written as manifest.json in each archive directory.
+type archiveManifest struct {
+	Query    string         `json:"query"`
+	Created  time.Time      `json:"created"`
+	Archived int            `json:"archived"`
+	Entries  []archiveEntry `json:"entries"`
+}
+
+type archiveEntry struct {
+	URL       string   `json:"url"`
+	File      string   `json:"file,omitempty"` // Relative to the archive directory
+	Status    int      `json:"status,omitempty"`
+	Bytes     int      `json:"bytes,omitempty"`
+	Truncated bool     `json:"truncated,omitempty"`
+	Providers []string `json:"providers"`
+	Skipped   string   `json:"skipped,omitempty"` // Why the page wasn't saved
+}
+
+// saveArchive snapshots cited pages for -archive, if requested.
+func saveArchive(ctx context.Context, query string, results []ModelResult) {
+	if !archiveEnabled || dryRun {
+		return
+	}
+	dir, m, err := archiveCitations(ctx, query, results, archiveDir)
+	if err != nil {
+		fmt.Printf("⚠️  Archive error: %v\n", err)
+		return
+	}
+	fmt.Printf("🗄️  Archived %d of %d cited pages to %s\n", m.Archived, len(m.Entries), dir)
+}
+
+// archiveCitations GETs every healthy cited URL and saves the body as
+// domain-<hash>.html in a new timestamped directory under base, with a
+// manifest.json mapping URLs to files. Links that failed the HEAD check are
+// listed in the manifest but not fetched. Link checks come from the shared
+// cache, so URLs the judge already checked aren't requested twice.
+func archiveCitations(ctx context.Context, query string, results []ModelResult, base string) (string, archiveManifest, error) {
+	m := archiveManifest{Query: query, Created: time.Now()}
+
+	dir := filepath.Join(base, m.Created.Format("2006-01-02T150405"))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", m, fmt.Errorf("create archive dir: %w", err)
+	}
+
+	// Collect unique URLs with the providers that cited them, in citation order.
+	byURL := make(map[string]*archiveEntry)
+	var urls []string
+	for _, mr := range results {
+		if mr.Result.Error != nil {
+			continue
+		}
+		for _, c := range mr.Result.Citations {
+			e, ok := byURL[c.URL]
+			if !ok {
+				e = &archiveEntry{URL: c.URL}
+				byURL[c.URL] = e
+				urls = append(urls, c.URL)
+			}
+			e.Providers = append(e.Providers, mr.Provider.Name())
+		}
+	}
+
+	for _, checks := range checkAllCitations(ctx, results) {
+		for _, check := range checks {
+			if e := byURL[check.URL]; e != nil && !check.Healthy {
+				e.Skipped = "unhealthy link"
+				if check.StatusCode != 0 {
+					e.Skipped = fmt.Sprintf("unhealthy link (status %d)", check.StatusCode)
+				}
+			}
+		}
+	}
+
+	client := newHTTPClient(archiveTimeout)
+	sem := make(chan struct{}, archiveConcurrency)
+	var wg sync.WaitGroup
+	for _, u := range urls {
+		e := byURL[u]
+		if e.Skipped != "" {
+			continue
+		}
+		wg.Add(1)
+		go func(e *archiveEntry) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			if err := archivePage(ctx, client, dir, e); err != nil {
+				e.Skipped = err.Error()
+			}
+		}(e)
+	}
+	wg.Wait()
+
+	for _, u := range urls {
+		e := byURL[u]
+		sort.Strings(e.Providers)
+		if e.File != "" {
+			m.Archived++
+		}
+		m.Entries = append(m.Entries, *e)
+	}
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return dir, m, fmt.Errorf("marshal manifest: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "manifest.json"), data, 0o644); err != nil {
+		return dir, m, fmt.Errorf("write manifest: %w", err)
+	}
+	return dir, m, nil
+}
+
+// archivePage fetches e.URL and writes the body into dir, filling in e.
+func archivePage(ctx context.Context, client *http.Client, dir string, e *archiveEntry) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", e.URL, nil)
+	if err != nil {
+		return fmt.Errorf("request error: %w", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("fetch error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	e.Status = resp.StatusCode
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("fetch returned status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, archiveMaxBytes+1))
+	if err != nil {
+		return fmt.Errorf("read error: %w", err)
+	}
+	if len(body) > archiveMaxBytes {
+		body = body[:archiveMaxBytes]
+		e.Truncated = true
+	}
+
+	name := archiveFileName(e.URL)
+	if err := os.WriteFile(filepath.Join(dir, name), body, 0o644); err != nil {
+		return fmt.Errorf("write error: %w", err)
+	}
+	e.File = name
+	e.Bytes = len(body)
+	return nil
+}
+
+// archiveFileName returns domain-<hash>.html, where the hash of the full URL
+// keeps pages from the same domain apart.
+func archiveFileName(rawURL string) string {
+	domain := domainFromURL(rawURL)
+	if domain == "" {
+		domain = "page"
+	}
+	domain = strings.NewReplacer(":", "_", "/", "_").Replace(domain)
+	sum := sha256.Sum256([]byte(rawURL))
+	return domain + "-" + hex.EncodeToString(sum[:6]) + ".html"
+}
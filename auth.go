@@ -0,0 +1,124 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// AuthError is returned by CheckAuth: a short reason plus an actionable setup hint.
+type AuthError struct {
+	Reason string // What's wrong, e.g. "ANTHROPIC_API_KEY not set"
+	Hint   string // How to fix it, e.g. "export ANTHROPIC_API_KEY=sk-ant-..."
+}
+
+func (e *AuthError) Error() string {
+	if e.Hint == "" {
+		return e.Reason
+	}
+	return e.Reason + " (" + e.Hint + ")"
+}
+
+// authStatus is one row of the startup auth table.
+type authStatus struct {
+	Provider Provider
+	Err      error // nil when ready
+}
+
+// checkProviders runs CheckAuth for each named provider in parallel, since some
+// checks make network calls, and returns the ready providers plus a status row for
+// every provider in names order. Under -dry-run auth isn't checked. Providers that
+// can't honor -answer-schema are skipped without checking.
+func checkProviders(names []string) ([]Provider, []authStatus) {
+	statuses := make([]authStatus, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		p, _ := Get(name)
+		statuses[i].Provider = p
+		switch {
+		case answerSchema != nil && !supportsAnswerSchema(p):
+			statuses[i].Err = &AuthError{Reason: "no structured output support (-answer-schema)"}
+		case dryRun:
+		default:
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				statuses[i].Err = statuses[i].Provider.CheckAuth()
+			}(i)
+		}
+	}
+	wg.Wait()
+
+	var available []Provider
+	for _, s := range statuses {
+		if s.Err == nil {
+			available = append(available, s.Provider)
+		}
+	}
+	return available, statuses
+}
+
+// noProvidersError says what was asked for when none of the requested
+// providers can run, why each was skipped, and which providers exist. The auth
+// table goes to stdout and may be silenced (-quiet, -format json), so the
+// reasons are repeated here.
+func noProvidersError(statuses []authStatus) error {
+	requested := make([]string, len(statuses))
+	for i, s := range statuses {
+		requested[i] = s.Provider.Name()
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "No runnable providers: none of %s can run.", strings.Join(requested, ", "))
+	for _, s := range statuses {
+		reason := s.Err.Error()
+		var ae *AuthError
+		if errors.As(s.Err, &ae) {
+			reason = ae.Reason
+		}
+		fmt.Fprintf(&b, "\n   %s: %s", s.Provider.Name(), reason)
+	}
+	fmt.Fprintf(&b, "\n   Available providers: %s. Set credentials for one, or pick others with -providers.", strings.Join(All(), ", "))
+	return &exitError{Code: noProvidersExitCode(), Err: errors.New(b.String())}
+}
+
+// failNoProviders exits with noProvidersError on stderr.
+func failNoProviders(statuses []authStatus) {
+	exitOnError(noProvidersError(statuses))
+}
+
+// printAuthTable lists each provider as ready or skipped, with the reason and
+// a setup hint for skipped ones.
+func printAuthTable(statuses []authStatus) {
+	if len(statuses) == 0 {
+		return
+	}
+	width := 0
+	for _, s := range statuses {
+		width = max(width, len(s.Provider.DisplayName()))
+	}
+
+	fmt.Println("🔑 Providers:")
+	for _, s := range statuses {
+		name := fmt.Sprintf("%s %-*s", s.Provider.Emoji(), width, s.Provider.DisplayName())
+		if s.Err == nil {
+			status := "ready"
+			if dryRun {
+				status = "ready (dry-run, auth not checked)"
+			}
+			fmt.Printf("   ✅ %s  %s\n", name, green(status))
+			continue
+		}
+
+		reason, hint := s.Err.Error(), ""
+		var ae *AuthError
+		if errors.As(s.Err, &ae) {
+			reason, hint = ae.Reason, ae.Hint
+		}
+		fmt.Printf("   ⏭️  %s  %s\n", name, yellow("skipped: "+reason))
+		if hint != "" {
+			fmt.Printf("      %s  %s\n", strings.Repeat(" ", width+3), dim("→ "+hint))
+		}
+	}
+	fmt.Println()
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+)
+
+// loadQueries reads -queries-file: one query per line, skipping blank lines
+// and # comments.
+func loadQueries(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open queries file: %w", err)
+	}
+	defer f.Close()
+
+	var queries []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		queries = append(queries, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read queries file: %w", err)
+	}
+	if len(queries) == 0 {
+		return nil, fmt.Errorf("queries file %s has no queries", path)
+	}
+	return queries, nil
+}
+
+// runBatch queries each prompt in turn, printing the running spend, then
+// judges every query in a single JudgeBatch call and prints each query's
+// ranked results, appending them to out. Ctrl-C stops the batch after the
+// interrupted query, which is shown unjudged. -fastest races each query as it
+// goes, without a judge. Ends with a cost report across the whole batch, and
+// returns all results so provider errors anywhere in the batch set the exit code.
+func runBatch(ctx context.Context, model string, queries []string, out *jsonlWriter) []ModelResult {
+	var batch []QueryResults
+	var spent float64
+	interrupted := false
+	for i, q := range queries {
+		fmt.Printf("📝 Query %d/%d: %s\n\n", i+1, len(queries), q)
+		var results []ModelResult
+		var err error
+		if fastest {
+			results, err = runQuery(ctx, model, q)
+			exitOnError(err)
+			appendJSONL(out, q, results)
+			interrupted = batchInterrupted(results)
+		} else {
+			results, interrupted, err = collectQuery(ctx, model, q)
+			exitOnError(err)
+		}
+		batch = append(batch, QueryResults{Query: q, Results: results})
+
+		cost := totalEstimatedCost(results)
+		spent += cost
+		fmt.Printf("💰 Batch spend: ~$%.4f this query, ~$%.4f total after %d/%d queries\n", cost, spent, i+1, len(queries))
+		fmt.Println()
+
+		if interrupted {
+			fmt.Printf("⏹️  Batch stopped after %d of %d queries\n", i+1, len(queries))
+			break
+		}
+	}
+
+	var all []ModelResult
+	if !fastest {
+		judgeAndPresentBatch(ctx, model, batch, interrupted, out)
+	}
+	for _, qr := range batch {
+		all = append(all, qr.Results...)
+	}
+	printBatchCostReport(summarizeBatchCosts(all), len(batch))
+	return all
+}
+
+// judgeAndPresentBatch judges the batch's completed queries together, then
+// prints and appends each query's results in order. If interrupted, the last
+// query is left unjudged. Results are updated in place.
+func judgeAndPresentBatch(ctx context.Context, model string, batch []QueryResults, interrupted bool, out *jsonlWriter) {
+	judgeable := batch
+	if interrupted {
+		judgeable = batch[:len(batch)-1]
+	}
+	judgeErrs := make([]error, len(batch))
+	if !skipJudge && len(judgeable) > 0 {
+		printJudgingBanner(fmt.Sprintf("⚖️  Judging %d %s...", len(judgeable), plural(len(judgeable), "query", "queries")))
+		// Ctrl-C while judging cancels the judge calls; results are still
+		// shown, ranked without judge scores.
+		judgeCtx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+		judged, errs := JudgeBatch(judgeCtx, judgeable)
+		stop()
+		copy(batch, judged)
+		copy(judgeErrs, errs)
+		fmt.Println()
+	}
+
+	for i := range batch {
+		qr := &batch[i]
+		fmt.Printf("📝 Results %d/%d: %s\n", i+1, len(batch), qr.Query)
+		if err := judgeErrs[i]; err != nil {
+			printJudgeError(err)
+		}
+		if isSingleModel(model) {
+			presentSingleResult(ctx, qr.Results[0], qr.Query)
+			fmt.Println()
+		} else {
+			fmt.Println()
+			presentResults(ctx, qr.Results, qr.Query, interrupted && i == len(batch)-1)
+		}
+		appendJSONL(out, qr.Query, qr.Results)
+	}
+}
+
+func totalEstimatedCost(results []ModelResult) float64 {
+	var total float64
+	for _, mr := range results {
+		total += mr.Result.EstimatedCost(mr.Provider.Name())
+	}
+	return total
+}
+
+// batchCost is one provider's spend across a batch.
+type batchCost struct {
+	Provider Provider
+	Queries  int // Queries the provider ran, including failed ones
+	Total    float64
+}
+
+// summarizeBatchCosts totals EstimatedCost per provider, most expensive first.
+func summarizeBatchCosts(results []ModelResult) []batchCost {
+	byName := make(map[string]*batchCost)
+	var costs []*batchCost
+	for _, mr := range results {
+		c, ok := byName[mr.Provider.Name()]
+		if !ok {
+			c = &batchCost{Provider: mr.Provider}
+			byName[mr.Provider.Name()] = c
+			costs = append(costs, c)
+		}
+		c.Queries++
+		c.Total += mr.Result.EstimatedCost(mr.Provider.Name())
+	}
+	sort.SliceStable(costs, func(i, j int) bool { return costs[i].Total > costs[j].Total })
+
+	out := make([]batchCost, len(costs))
+	for i, c := range costs {
+		out[i] = *c
+	}
+	return out
+}
+
+// printBatchCostReport shows total spend, each provider's total and share,
+// and average cost per query over the queries run.
+func printBatchCostReport(costs []batchCost, queries int) {
+	if len(costs) == 0 || queries == 0 {
+		return
+	}
+	var total float64
+	for _, c := range costs {
+		total += c.Total
+	}
+
+	fmt.Println("╔══════════════════════════════════════════════════════════════════════╗")
+	fmt.Println("║                          BATCH COST REPORT                           ║")
+	fmt.Println("╠══════════════════════════════════════════════════════════════════════╣")
+	fmt.Printf("║ %-26s %8s %11s %7s %12s ║\n", "Model", "Queries", "Total", "Share", "Avg/query")
+	fmt.Println("╠══════════════════════════════════════════════════════════════════════╣")
+	for _, c := range costs {
+		share := 0.0
+		if total > 0 {
+			share = c.Total / total * 100
+		}
+		// The emoji is two columns wide, so the name pads one less.
+		total, avg := fmt.Sprintf("~$%.4f", c.Total), fmt.Sprintf("~$%.4f", c.Total/float64(c.Queries))
+		if costUnknown(c.Provider.Name()) {
+			total, avg = "unknown", "unknown"
+		}
+		fmt.Printf("║ %-25s %8d %11s %6.1f%% %12s ║\n", fmt.Sprintf("%s %s", c.Provider.Emoji(), c.Provider.DisplayName()),
+			c.Queries, total, share, avg)
+	}
+	fmt.Println("╠══════════════════════════════════════════════════════════════════════╣")
+	fmt.Printf("║ %-25s %8d %11s %7s %12s ║\n", "💰 TOTAL", queries, fmt.Sprintf("~$%.4f", total), "100%", fmt.Sprintf("~$%.4f", total/float64(queries)))
+	fmt.Println("╚══════════════════════════════════════════════════════════════════════╝")
+	fmt.Println()
+}
+
+func batchInterrupted(results []ModelResult) bool {
+	for _, mr := range results {
+		if errors.Is(mr.Result.Error, errInterrupted) {
+			return true
+		}
+	}
+	return false
+}
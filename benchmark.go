@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// benchmarkStats summarizes repeated runs of one provider. The warmup run is excluded.
+type benchmarkStats struct {
+	Provider Provider
+	Runs     int
+	Errors   int
+	Min      time.Duration
+	Median   time.Duration
+	P95      time.Duration
+	Max      time.Duration
+	MeanCost float64
+	CostStd  float64 // Standard deviation of per-run estimated cost
+}
+
+// runBenchmark runs query n times against each provider, discarding the first run
+// as warmup, and prints latency percentiles and cost variance. Providers run in
+// parallel; runs for one provider are sequential so they don't contend.
+func runBenchmark(ctx context.Context, names []string, query string, n int) {
+	available, statuses := checkProviders(names)
+	printAuthTable(statuses)
+	warnUnsupportedFlags(available)
+
+	if len(available) == 0 {
+		failNoProviders(statuses)
+	}
+
+	fmt.Printf("⏱️  Benchmarking %d providers × %d runs (first run is warmup)...\n", len(available), n)
+	fmt.Println(strings.Repeat("═", 65))
+	fmt.Println()
+
+	stats := make([]benchmarkStats, len(available))
+	var wg sync.WaitGroup
+	for i, p := range available {
+		wg.Add(1)
+		go func(i int, p Provider) {
+			defer wg.Done()
+			results := make([]Result, 0, n)
+			for run := range n {
+				r := withProviderTimeout(ctx, p, func(ctx context.Context) Result {
+					return p.Query(ctx, query, verbosity)
+				})
+				checkEmptyResponse(&r)
+				slog.Debug("benchmark run", "provider", p.Name(), "run", run+1, "duration", r.Duration, "error", r.Error)
+				results = append(results, r)
+			}
+			stats[i] = summarizeBenchmark(p, results[1:])
+		}(i, p)
+	}
+	wg.Wait()
+
+	printBenchmarkTable(stats)
+}
+
+// summarizeBenchmark computes latency percentiles and cost spread over successful runs.
+func summarizeBenchmark(p Provider, results []Result) benchmarkStats {
+	s := benchmarkStats{Provider: p, Runs: len(results)}
+
+	var durations []time.Duration
+	var costs []float64
+	for _, r := range results {
+		if r.Error != nil {
+			s.Errors++
+			continue
+		}
+		durations = append(durations, r.Duration)
+		costs = append(costs, r.EstimatedCost(p.Name()))
+	}
+	if len(durations) == 0 {
+		return s
+	}
+
+	slices.Sort(durations)
+	s.Min = durations[0]
+	s.Max = durations[len(durations)-1]
+	s.Median = percentile(durations, 0.50)
+	s.P95 = percentile(durations, 0.95)
+
+	for _, c := range costs {
+		s.MeanCost += c
+	}
+	s.MeanCost /= float64(len(costs))
+	var variance float64
+	for _, c := range costs {
+		variance += (c - s.MeanCost) * (c - s.MeanCost)
+	}
+	s.CostStd = math.Sqrt(variance / float64(len(costs)))
+
+	return s
+}
+
+// percentile returns the nearest-rank percentile of sorted durations.
+func percentile(sorted []time.Duration, q float64) time.Duration {
+	idx := int(math.Ceil(q*float64(len(sorted)))) - 1
+	return sorted[max(idx, 0)]
+}
+
+func printBenchmarkTable(stats []benchmarkStats) {
+	fmt.Println("╔══════════════════════════════════════════════════════════════════════════════════════╗")
+	fmt.Println("║                                     BENCHMARK                                        ║")
+	fmt.Println("╠══════════════════════════════════════════════════════════════════════════════════════╣")
+	fmt.Printf("║ %-27s %4s %3s %7s %7s %7s %7s  %-14s ║\n", "Model", "Runs", "Err", "Min", "Median", "P95", "Max", "Cost (mean±sd)")
+	fmt.Println("╠══════════════════════════════════════════════════════════════════════════════════════╣")
+
+	round := func(d time.Duration) string {
+		return fmt.Sprintf("%.2fs", d.Seconds())
+	}
+	for _, s := range stats {
+		name := fmt.Sprintf("%s %s", s.Provider.Emoji(), s.Provider.DisplayName())
+		if s.Errors == s.Runs {
+			fmt.Printf("║ %-27s %4d %3d   %-46s ║\n", name, s.Runs, s.Errors, "all runs failed")
+			continue
+		}
+		fmt.Printf("║ %-27s %4d %3d %7s %7s %7s %7s  $%.4f±%.4f ║\n",
+			name, s.Runs, s.Errors, round(s.Min), round(s.Median), round(s.P95), round(s.Max), s.MeanCost, s.CostStd)
+	}
+
+	fmt.Println("╠══════════════════════════════════════════════════════════════════════════════════════╣")
+	fmt.Println("║ ⚠️  Warmup run excluded. Errored runs are counted but excluded from timings.         ║")
+	fmt.Println("╚══════════════════════════════════════════════════════════════════════════════════════╝")
+	fmt.Println()
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// maxTokenEstimateConfig holds the config file's max_token_estimate section as
+// a -max-token-estimate spec; it is applied before the flag, so the flag wins.
+var maxTokenEstimateConfig string
+
+// applyMaxTokenEstimates parses a "name=input:output,..." list and overrides
+// those providers' MaxTokenEstimate entries. Names must be registered
+// providers (including -providers-file entries and model variants).
+func applyMaxTokenEstimates(spec string) error {
+	overrides := make(map[string]TokenUsage)
+	for _, pair := range strings.Split(spec, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		name, tokens, ok := strings.Cut(pair, "=")
+		name = strings.TrimSpace(name)
+		in, out, ok2 := strings.Cut(tokens, ":")
+		if !ok || !ok2 {
+			return fmt.Errorf("invalid entry %q (want name=input:output)", pair)
+		}
+		if _, ok := Get(name); !ok {
+			return fmt.Errorf("unknown provider %q (available: %s)", name, strings.Join(All(), ", "))
+		}
+		input, err := strconv.Atoi(strings.TrimSpace(in))
+		if err != nil || input < 0 {
+			return fmt.Errorf("invalid entry %q: input must be a non-negative token count", pair)
+		}
+		output, err := strconv.Atoi(strings.TrimSpace(out))
+		if err != nil || output < 0 {
+			return fmt.Errorf("invalid entry %q: output must be a non-negative token count", pair)
+		}
+		overrides[name] = TokenUsage{Input: input, Output: output}
+	}
+	for name, worst := range overrides {
+		MaxTokenEstimate[name] = worst
+	}
+	return nil
+}
+
+// applyBudget selects providers in ascending worst-case cost order until the next one
+// would push the running total over budget. A budget <= 0 means unlimited.
+// Providers whose cost is unknown can't be kept within a budget and are dropped.
+func applyBudget(available []Provider, budget float64) (selected, dropped []Provider, projected float64) {
+	if budget <= 0 {
+		return available, nil, 0
+	}
+
+	var sorted []Provider
+	for _, p := range available {
+		if costUnknown(p.Name()) {
+			dropped = append(dropped, p)
+		} else {
+			sorted = append(sorted, p)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return WorstCaseCost(sorted[i].Name()) < WorstCaseCost(sorted[j].Name())
+	})
+
+	for i, p := range sorted {
+		cost := WorstCaseCost(p.Name())
+		if projected+cost > budget {
+			return selected, append(sorted[i:], dropped...), projected
+		}
+		projected += cost
+		selected = append(selected, p)
+	}
+	return selected, dropped, projected
+}
+
+func printBudgetDropped(dropped []Provider, budget float64) {
+	if len(dropped) == 0 {
+		return
+	}
+	fmt.Printf("💸 Skipping providers to stay within budget ($%.4f):\n", budget)
+	for _, p := range dropped {
+		if costUnknown(p.Name()) {
+			fmt.Printf("   %s %s: cost unknown (no price for %s)\n", p.Emoji(), p.DisplayName(), modelIDOf(p))
+			continue
+		}
+		fmt.Printf("   %s %s: worst case ~$%.4f\n", p.Emoji(), p.DisplayName(), WorstCaseCost(p.Name()))
+	}
+	fmt.Println()
+}
+
+// warnIfOverBudget prints a warning when the actual estimated cost exceeded the budget.
+func warnIfOverBudget(results []ModelResult, budget float64) {
+	if budget <= 0 {
+		return
+	}
+	var total float64
+	for _, mr := range results {
+		total += mr.Result.EstimatedCost(mr.Provider.Name())
+	}
+	if total > budget {
+		fmt.Printf("⚠️  Estimated cost ~$%.4f exceeded budget $%.4f\n", total, budget)
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"maps"
+	"testing"
+)
+
+func TestApplyMaxTokenEstimates(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    map[string]TokenUsage // Entries expected afterwards
+		wantErr bool
+	}{
+		{spec: "", want: map[string]TokenUsage{"claude": MaxTokenEstimate["claude"]}},
+		{spec: "claude=60000:4096", want: map[string]TokenUsage{"claude": {Input: 60000, Output: 4096}}},
+		{
+			spec: " claude = 1000:200 , gemini=0:8192,",
+			want: map[string]TokenUsage{"claude": {Input: 1000, Output: 200}, "gemini": {Input: 0, Output: 8192}},
+		},
+		{spec: "ollama=2000:1000", want: map[string]TokenUsage{"ollama": {Input: 2000, Output: 1000}}},
+		{spec: "nosuch=1:1", wantErr: true},
+		{spec: "claude=60000", wantErr: true},
+		{spec: "claude:60000:4096", wantErr: true},
+		{spec: "claude=-1:10", wantErr: true},
+		{spec: "claude=10:many", wantErr: true},
+		// A bad entry leaves earlier ones in the same spec unapplied.
+		{spec: "gemini=1:1,claude=x:1", want: map[string]TokenUsage{"gemini": MaxTokenEstimate["gemini"]}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			saved := maps.Clone(MaxTokenEstimate)
+			t.Cleanup(func() { MaxTokenEstimate = saved })
+
+			err := applyMaxTokenEstimates(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("applyMaxTokenEstimates(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			}
+			for name, want := range tt.want {
+				if got := MaxTokenEstimate[name]; got != want {
+					t.Errorf("MaxTokenEstimate[%s] = %+v, want %+v", name, got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// defaultCacheTTL is how long a cached response is reused under -cache-responses.
+const defaultCacheTTL = 24 * time.Hour
+
+// Response cache settings. With -cache-responses, each successful answer is
+// stored on disk keyed by provider, model, and query, and an identical query
+// within cacheTTL is answered from disk at no cost. -refresh skips lookups but
+// still stores fresh answers. Unrelated to linkCheckCache, which only lives
+// for one run.
+var (
+	cacheResponses bool
+	cacheTTL       = defaultCacheTTL
+	cacheRefresh   bool
+)
+
+// ModelIDProvider is implemented by providers that can name the exact model
+// they call, so a cached answer isn't reused after the model changes. Others
+// are keyed by DisplayName.
+type ModelIDProvider interface {
+	ModelID() string
+}
+
+// modelIDOf returns p's model ID, or its display name.
+func modelIDOf(p Provider) string {
+	if mp, ok := p.(ModelIDProvider); ok {
+		return mp.ModelID()
+	}
+	return p.DisplayName()
+}
+
+// cacheEntry is one cached response file.
+type cacheEntry struct {
+	Provider string    `json:"provider"`
+	Model    string    `json:"model"`
+	Query    string    `json:"query"`
+	Stored   time.Time `json:"stored"`
+	Result   Result    `json:"result"` // Error is always nil; failures aren't cached
+}
+
+// responseCacheDir returns where cached responses live.
+func responseCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("locate cache dir: %w", err)
+	}
+	return filepath.Join(dir, "web-search", "responses"), nil
+}
+
+// normalizeCacheQuery folds case and whitespace so trivially different
+// spellings of a query share an entry.
+func normalizeCacheQuery(query string) string {
+	return strings.ToLower(strings.Join(strings.Fields(query), " "))
+}
+
+// responseCacheKey hashes everything that shapes p's answer to query: the
+// provider, its model, the normalized query, and the flags that change the
+// request or how the result is filtered.
+func responseCacheKey(p Provider, query string) string {
+	var images []string
+	for _, img := range queryImages {
+		sum := sha256.Sum256(img.Data)
+		images = append(images, hex.EncodeToString(sum[:]))
+	}
+	settings, _ := json.Marshal(map[string]any{
+		"system":         systemPrompt,
+		"reasoning":      reasoning,
+		"schema":         answerSchema,
+		"allow":          allowDomains,
+		"block":          blockDomains,
+		"since":          sinceTime,
+		"location":       userLocation,
+		"lang":           responseLang,
+		"max_searches":   maxSearches,
+		"min_citations":  minCitations,
+		"ungrounded_ok":  allowUngroundedFallback,
+		"images":         images,
+		"prepared_query": prepareQuery(p, normalizeCacheQuery(query)),
+	})
+	sum := sha256.Sum256([]byte(strings.Join([]string{p.Name(), modelIDOf(p), normalizeCacheQuery(query), string(settings)}, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+// responseCacheEnabled reports whether this query may use the response cache.
+// Multi-turn sessions aren't cached: the answer depends on earlier turns.
+func responseCacheEnabled() bool {
+	if !cacheResponses || dryRun {
+		return false
+	}
+	conversationsMu.Lock()
+	defer conversationsMu.Unlock()
+	return conversations == nil
+}
+
+// loadCachedResponse returns p's cached answer to query if there is a fresh
+// one. Hits are marked Cached so they show a badge and cost nothing.
+func loadCachedResponse(p Provider, query string) (Result, bool) {
+	if !responseCacheEnabled() || cacheRefresh {
+		return Result{}, false
+	}
+	dir, err := responseCacheDir()
+	if err != nil {
+		return Result{}, false
+	}
+	path := filepath.Join(dir, responseCacheKey(p, query)+".json")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Result{}, false
+	}
+	var e cacheEntry
+	if err := json.Unmarshal(data, &e); err != nil {
+		slog.Warn("ignoring unreadable cache entry", "file", path, "error", err)
+		return Result{}, false
+	}
+	if age := time.Since(e.Stored); age > cacheTTL {
+		slog.Debug("cache entry expired", "provider", p.Name(), "age", age.Round(time.Second))
+		return Result{}, false
+	}
+	slog.Info("cache hit", "provider", p.Name(), "age", time.Since(e.Stored).Round(time.Second))
+	e.Result.Cached = true
+	return e.Result, true
+}
+
+// storeCachedResponse saves a successful answer for later runs. Failures are
+// logged rather than failing the query.
+func storeCachedResponse(p Provider, query string, r Result) {
+	if !responseCacheEnabled() || r.Error != nil || r.Cached {
+		return
+	}
+	dir, err := responseCacheDir()
+	if err == nil {
+		err = os.MkdirAll(dir, 0o755)
+	}
+	if err != nil {
+		slog.Warn("response not cached", "provider", p.Name(), "error", err)
+		return
+	}
+	data, err := json.Marshal(cacheEntry{
+		Provider: p.Name(),
+		Model:    modelIDOf(p),
+		Query:    query,
+		Stored:   time.Now(),
+		Result:   r,
+	})
+	if err != nil {
+		slog.Warn("response not cached", "provider", p.Name(), "error", fmt.Errorf("marshal: %w", err))
+		return
+	}
+	path := filepath.Join(dir, responseCacheKey(p, query)+".json")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		slog.Warn("response not cached", "provider", p.Name(), "error", err)
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Capabilities lists the optional features a provider honors. Features without
+// one are ignored by that provider, or (domain and recency filters) fall back
+// to a prompt instruction.
+type Capabilities struct {
+	SystemPrompt     bool // -system is sent in a native system slot
+	DomainFilter     bool // -allow-domains / -block-domains enforced by the search tool
+	RecencyFilter    bool // -since enforced by the search tool
+	StructuredOutput bool // -answer-schema
+	Reasoning        bool // -reasoning effort is applied
+	Location         bool // -location biases search results
+	SearchLimit      bool // -max-searches caps search calls
+	Conversation     bool // Prior turns sent as native messages (-repl, -min-citations)
+	TokenCount       bool // Native tokenizer for -estimate
+	Vision           bool // -image attachments
+}
+
+// CapabilityProvider is implemented by providers to declare capabilities that
+// have no dedicated optional interface. capabilitiesOf fills in the rest.
+type CapabilityProvider interface {
+	Capabilities() Capabilities
+}
+
+// capabilitiesOf returns everything p supports, combining its declared
+// Capabilities with the optional interfaces it implements.
+func capabilitiesOf(p Provider) Capabilities {
+	var c Capabilities
+	if cp, ok := p.(CapabilityProvider); ok {
+		c = cp.Capabilities()
+	}
+	if fp, ok := p.(DomainFilterProvider); ok && fp.SupportsDomainFilter() {
+		c.DomainFilter = true
+	}
+	if rp, ok := p.(RecencyProvider); ok && rp.SupportsRecencyFilter() {
+		c.RecencyFilter = true
+	}
+	c.StructuredOutput = supportsAnswerSchema(p)
+	_, c.Conversation = p.(ConversationProvider)
+	_, c.TokenCount = p.(TokenCounter)
+	return c
+}
+
+// capabilityColumns are the -capabilities matrix columns, in display order.
+var capabilityColumns = []struct {
+	name string
+	has  func(Capabilities) bool
+}{
+	{"System", func(c Capabilities) bool { return c.SystemPrompt }},
+	{"Domains", func(c Capabilities) bool { return c.DomainFilter }},
+	{"Recency", func(c Capabilities) bool { return c.RecencyFilter }},
+	{"Schema", func(c Capabilities) bool { return c.StructuredOutput }},
+	{"Reasoning", func(c Capabilities) bool { return c.Reasoning }},
+	{"Location", func(c Capabilities) bool { return c.Location }},
+	{"MaxSearch", func(c Capabilities) bool { return c.SearchLimit }},
+	{"Turns", func(c Capabilities) bool { return c.Conversation }},
+	{"Tokens", func(c Capabilities) bool { return c.TokenCount }},
+	{"Images", func(c Capabilities) bool { return c.Vision }},
+}
+
+// printCapabilities prints the providers × features matrix for -capabilities.
+func printCapabilities(names []string) {
+	fmt.Println(bold("🧩 Provider Capabilities"))
+	// Names start with an emoji two columns wide, so the header pads one more.
+	fmt.Println(strings.Repeat("─", 29+10*len(capabilityColumns)))
+	fmt.Printf("%-29s", "Model")
+	for _, col := range capabilityColumns {
+		fmt.Printf("%10s", col.name)
+	}
+	fmt.Println()
+	fmt.Println(strings.Repeat("─", 29+10*len(capabilityColumns)))
+
+	for _, name := range names {
+		p, _ := Get(name)
+		c := capabilitiesOf(p)
+		fmt.Printf("%-28s", fmt.Sprintf("%s %s", p.Emoji(), p.DisplayName()))
+		for _, col := range capabilityColumns {
+			mark := dim("·")
+			if col.has(c) {
+				mark = green("✓")
+			}
+			fmt.Printf("%s%s", strings.Repeat(" ", 9), mark)
+		}
+		fmt.Println()
+	}
+	fmt.Println()
+	fmt.Println(dim("Domains and Recency fall back to a prompt instruction where not native; Schema-less providers are skipped with -answer-schema."))
+	fmt.Println()
+}
+
+// warnUnsupportedFlags names the selected providers that will ignore a flag
+// the user set, so a setting never silently does nothing.
+func warnUnsupportedFlags(providers []Provider) {
+	flags := []struct {
+		flag string
+		set  bool
+		has  func(Capabilities) bool
+	}{
+		{"-system", systemPrompt != "", func(c Capabilities) bool { return c.SystemPrompt }},
+		{"-reasoning", reasoning != ReasoningOff, func(c Capabilities) bool { return c.Reasoning }},
+		{"-location", userLocation != nil, func(c Capabilities) bool { return c.Location }},
+		{"-max-searches", maxSearches > 0, func(c Capabilities) bool { return c.SearchLimit }},
+	}
+
+	for _, f := range flags {
+		if !f.set {
+			continue
+		}
+		var ignoring []string
+		for _, p := range providers {
+			if !f.has(capabilitiesOf(p)) {
+				ignoring = append(ignoring, p.DisplayName())
+			}
+		}
+		if len(ignoring) > 0 {
+			fmt.Printf("⚠️  %s is not supported by %s (ignored)\n", f.flag, strings.Join(ignoring, ", "))
+		}
+	}
+}
@@ -3,49 +3,136 @@ package main
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/anthropics/anthropic-sdk-go/option"
 )
 
-const claudeModelID = "claude-sonnet-4-5-20250929"
+const (
+	claudeModelID           = "claude-sonnet-4-5-20250929"
+	anthropicDefaultBaseURL = "https://api.anthropic.com"
+)
+
+// Anthropic client settings shared by the Claude provider and the judge.
+// anthropicBaseURL overrides ANTHROPIC_BASE_URL when set via
+// -anthropic-base-url; anthropicAPIKey, read from -anthropic-api-key-file,
+// overrides ANTHROPIC_API_KEY.
+var (
+	anthropicBaseURL string
+	anthropicAPIKey  string
+)
+
+// claudeThinkingBudget maps -reasoning levels to extended thinking token budgets.
+var claudeThinkingBudget = map[string]int64{
+	ReasoningLow:    1024,
+	ReasoningMedium: 4096,
+	ReasoningHigh:   16384,
+}
+
+// claudeAnswerTool is the custom tool Claude calls with its final answer when
+// -answer-schema is set; its input schema is the answer schema.
+const claudeAnswerTool = "answer"
 
 func init() {
 	Register(&ClaudeProvider{})
 }
 
 // ClaudeProvider implements Provider for Claude via Anthropic API.
-type ClaudeProvider struct{}
+// The API client is created on first use and reused across queries.
+type ClaudeProvider struct {
+	clientOnce sync.Once
+	client     anthropic.Client
+	variant    modelVariant
+}
+
+func (p *ClaudeProvider) Name() string { return p.variant.name("claude") }
+func (p *ClaudeProvider) DisplayName() string {
+	return p.variant.displayName(p.Name(), "Claude 4.5 Sonnet")
+}
+func (p *ClaudeProvider) Emoji() string   { return "🟣" }
+func (p *ClaudeProvider) ModelID() string { return p.variant.modelOr(claudeModelID) }
+
+// WithModel returns a Claude instance for another model, e.g. an Opus snapshot.
+func (p *ClaudeProvider) WithModel(label, model string) (Provider, error) {
+	return &ClaudeProvider{variant: modelVariant{label: label, model: model}}, nil
+}
+
+// SupportsDomainFilter is true: web_search takes allowed/blocked domain lists.
+func (p *ClaudeProvider) SupportsDomainFilter() bool { return true }
 
-func (p *ClaudeProvider) Name() string        { return "claude" }
-func (p *ClaudeProvider) DisplayName() string { return "Claude 4.5 Sonnet" }
-func (p *ClaudeProvider) Emoji() string       { return "🟣" }
+// SupportsAnswerSchema is true: the answer is returned as a tool call.
+func (p *ClaudeProvider) SupportsAnswerSchema() bool { return true }
+
+// Capabilities: the web_search tool takes a location and a max_uses cap, and
+// -reasoning maps to a thinking budget.
+func (p *ClaudeProvider) Capabilities() Capabilities {
+	return Capabilities{SystemPrompt: true, Reasoning: true, Location: true, SearchLimit: true, Vision: true}
+}
+
+// BaseURL returns the Anthropic API base from -anthropic-base-url,
+// ANTHROPIC_BASE_URL, or the default.
+func (p *ClaudeProvider) BaseURL() string { return anthropicBase() }
+
+func anthropicBase() string {
+	return resolveBaseURL(anthropicBaseURL, "ANTHROPIC_BASE_URL", anthropicDefaultBaseURL)
+}
 
 func (p *ClaudeProvider) CheckAuth() error {
-	if os.Getenv("ANTHROPIC_API_KEY") == "" {
-		return fmt.Errorf("ANTHROPIC_API_KEY not set")
+	if anthropicKey() == "" {
+		return &AuthError{Reason: "ANTHROPIC_API_KEY not set", Hint: "export ANTHROPIC_API_KEY=sk-ant-... (console.anthropic.com), or pass -anthropic-api-key-file"}
 	}
 	return nil
 }
 
-func (p *ClaudeProvider) Query(ctx context.Context, query string, verbose bool) Result {
-	start := time.Now()
-	result := Result{}
+// anthropicKey returns the -anthropic-api-key-file key, else ANTHROPIC_API_KEY.
+func anthropicKey() string {
+	if anthropicAPIKey != "" {
+		return anthropicAPIKey
+	}
+	return os.Getenv("ANTHROPIC_API_KEY")
+}
 
-	client := anthropic.NewClient()
+// newAnthropicClient creates a client with the configured key and base URL,
+// so the provider and the judge go through the same gateway.
+func newAnthropicClient(opts ...option.RequestOption) anthropic.Client {
+	base := []option.RequestOption{option.WithBaseURL(anthropicBase())}
+	if key := anthropicKey(); key != "" {
+		base = append(base, option.WithAPIKey(key))
+	}
+	return anthropic.NewClient(append(base, opts...)...)
+}
 
-	if verbose {
-		fmt.Printf("  [Claude] Sending request with web_search tool...\n")
+// loadAnthropicKey reads an API key file for -anthropic-api-key-file. A file
+// keeps the key out of shell history and the process list.
+func loadAnthropicKey(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("read API key: %w", err)
+	}
+	key := strings.TrimSpace(string(data))
+	if key == "" {
+		return "", fmt.Errorf("API key file %s is empty", path)
 	}
+	return key, nil
+}
 
-	message, err := client.Messages.New(ctx, anthropic.MessageNewParams{
-		Model:     claudeModelID,
+func (p *ClaudeProvider) Query(ctx context.Context, query string, v Verbosity) Result {
+	return p.QueryConversation(ctx, []Message{{Role: RoleUser, Text: query}}, v)
+}
+
+func (p *ClaudeProvider) QueryConversation(ctx context.Context, history []Message, v Verbosity) Result {
+	start := time.Now()
+	result := Result{}
+
+	params := anthropic.MessageNewParams{
+		Model:     anthropic.Model(p.ModelID()),
 		MaxTokens: 4096,
-		Messages: []anthropic.MessageParam{
-			anthropic.NewUserMessage(anthropic.NewTextBlock(query)),
-		},
+		Messages:  claudeMessages(history),
 		Tools: []anthropic.ToolUnionParam{
 			{
 				OfWebSearchTool20250305: &anthropic.WebSearchTool20250305Param{
@@ -54,7 +141,50 @@ func (p *ClaudeProvider) Query(ctx context.Context, query string, verbose bool)
 				},
 			},
 		},
-	})
+	}
+
+	// The API accepts only one list; an allow list already excludes everything else.
+	search := params.Tools[0].OfWebSearchTool20250305
+	if len(allowDomains) > 0 {
+		search.AllowedDomains = nativeAllowedDomains()
+	} else if len(blockDomains) > 0 {
+		search.BlockedDomains = blockDomains
+	}
+	if maxSearches > 0 {
+		search.MaxUses = anthropic.Int(int64(maxSearches))
+	}
+	if userLocation != nil {
+		search.UserLocation = claudeUserLocation(userLocation)
+	}
+
+	if systemPrompt != "" {
+		params.System = []anthropic.TextBlockParam{{Text: systemPrompt}}
+	}
+
+	if answerSchema != nil {
+		params.Tools = append(params.Tools, anthropic.ToolUnionParam{OfTool: claudeAnswerToolParam(answerSchema)})
+		params.System = append(params.System, anthropic.TextBlockParam{
+			Text: "Search the web as needed, then give your final answer only by calling the " + claudeAnswerTool + " tool.",
+		})
+	}
+
+	// max_tokens must cover the thinking budget plus the answer
+	if budget, ok := claudeThinkingBudget[reasoning]; ok {
+		params.Thinking = anthropic.ThinkingConfigParamOfEnabled(budget)
+		params.MaxTokens += budget
+	}
+
+	if dryRun {
+		return dryRunResult(p, params)
+	}
+
+	p.clientOnce.Do(func() { p.client = newAnthropicClient() })
+	client := p.client
+
+	slog.Debug("sending request", "provider", p.Name(), "tool", "web_search")
+	dumpJSON(p.Name()+"-request", params)
+
+	message, err := client.Messages.New(ctx, params)
 
 	result.Duration = time.Since(start)
 
@@ -62,33 +192,161 @@ func (p *ClaudeProvider) Query(ctx context.Context, query string, verbose bool)
 		result.Error = fmt.Errorf("API error: %w", err)
 		return result
 	}
+	dumpBytes(p.Name()+"-response", []byte(message.RawJSON()))
+	keepRaw(&result, []byte(message.RawJSON()))
 
 	// Extract token usage
 	result.Tokens.Input = int(message.Usage.InputTokens)
 	result.Tokens.Output = int(message.Usage.OutputTokens)
+	result.FinishReason = string(message.StopReason)
 
 	parseClaudeResponse(message, &result)
+	logClaudeToolCalls(v, p.Name(), message)
 	return result
 }
 
+// CountTokens counts the query's prompt tokens, including the web search tool
+// definition, with the Anthropic count-tokens endpoint (free, no generation).
+func (p *ClaudeProvider) CountTokens(ctx context.Context, query string) (int, error) {
+	p.clientOnce.Do(func() { p.client = newAnthropicClient() })
+	res, err := p.client.Messages.CountTokens(ctx, anthropic.MessageCountTokensParams{
+		Model:    anthropic.Model(p.ModelID()),
+		Messages: claudeMessages([]Message{{Role: RoleUser, Text: query}}),
+		Tools: []anthropic.MessageCountTokensToolUnionParam{
+			{
+				OfWebSearchTool20250305: &anthropic.WebSearchTool20250305Param{
+					Name: "web_search",
+					Type: "web_search_20250305",
+				},
+			},
+		},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("count tokens error: %w", err)
+	}
+	return int(res.InputTokens), nil
+}
+
+// claudeUserLocation maps -location to the web search tool's approximate location.
+func claudeUserLocation(loc *searchLocation) anthropic.WebSearchTool20250305UserLocationParam {
+	var p anthropic.WebSearchTool20250305UserLocationParam
+	if loc.City != "" {
+		p.City = anthropic.String(loc.City)
+	}
+	if loc.Region != "" {
+		p.Region = anthropic.String(loc.Region)
+	}
+	if loc.Country != "" {
+		p.Country = anthropic.String(loc.Country)
+	}
+	if loc.Timezone != "" {
+		p.Timezone = anthropic.String(loc.Timezone)
+	}
+	return p
+}
+
+// claudeMessages maps conversation history to Anthropic message params.
+func claudeMessages(history []Message) []anthropic.MessageParam {
+	messages := make([]anthropic.MessageParam, 0, len(history))
+	for _, m := range history {
+		if m.Role == RoleAssistant {
+			messages = append(messages, anthropic.NewAssistantMessage(anthropic.NewTextBlock(m.Text)))
+		} else {
+			blocks := make([]anthropic.ContentBlockParamUnion, 0, len(m.Images)+1)
+			for _, img := range m.Images {
+				blocks = append(blocks, anthropic.NewImageBlockBase64(img.MediaType, img.Base64()))
+			}
+			blocks = append(blocks, anthropic.NewTextBlock(m.Text))
+			messages = append(messages, anthropic.NewUserMessage(blocks...))
+		}
+	}
+	return messages
+}
+
+// claudeAnswerToolParam builds the answer tool from an object schema.
+func claudeAnswerToolParam(schema map[string]any) *anthropic.ToolParam {
+	extra := make(map[string]any)
+	for k, v := range schema {
+		if k != "type" && k != "properties" && k != "required" {
+			extra[k] = v
+		}
+	}
+	var required []string
+	if rs, ok := schema["required"].([]any); ok {
+		for _, r := range rs {
+			if s, ok := r.(string); ok {
+				required = append(required, s)
+			}
+		}
+	}
+	return &anthropic.ToolParam{
+		Name:        claudeAnswerTool,
+		Description: anthropic.String("Return the final answer as structured JSON."),
+		InputSchema: anthropic.ToolInputSchemaParam{
+			Properties:  schema["properties"],
+			Required:    required,
+			ExtraFields: extra,
+		},
+	}
+}
+
+// logClaudeToolCalls records the web searches and answer-tool calls in
+// message, under -vv.
+func logClaudeToolCalls(v Verbosity, provider string, message *anthropic.Message) {
+	for _, block := range message.Content {
+		if block.Type == "server_tool_use" || block.Type == "tool_use" {
+			logToolCall(v, provider, block.Name, block.Input)
+		}
+	}
+}
+
 func parseClaudeResponse(message *anthropic.Message, result *Result) {
 	var textBuilder strings.Builder
+	var structured string
 	seen := make(map[string]bool)
 
 	for _, block := range message.Content {
 		switch b := block.AsAny().(type) {
 		case anthropic.TextBlock:
+			// Citations attach to the whole text block they support.
+			start := textBuilder.Len()
 			textBuilder.WriteString(b.Text)
 			for _, citation := range b.Citations {
 				if citation.Type == "web_search_result_location" && citation.URL != "" {
 					DeduplicateCitations(&result.Citations, seen, Citation{
-						URL:   citation.URL,
-						Title: citation.Title,
+						URL:        citation.URL,
+						Title:      citation.Title,
+						Snippet:    citation.CitedText,
+						StartIndex: start,
+						EndIndex:   textBuilder.Len(),
 					})
 				}
 			}
+		case anthropic.ThinkingBlock:
+			AppendThinking(result, b.Thinking)
+		case anthropic.ServerToolUseBlock:
+			input, _ := b.Input.(map[string]any)
+			query, _ := input["query"].(string)
+			addSearchStep(result, query, 0)
+		case anthropic.ToolUseBlock:
+			if b.Name == claudeAnswerTool {
+				structured = string(b.Input)
+			}
+		case anthropic.WebSearchToolResultBlock:
+			if b.Content.ErrorCode != "" {
+				result.SearchError = string(b.Content.ErrorCode)
+			} else {
+				result.SearchResults += len(b.Content.OfWebSearchResultBlockArray)
+			}
 		}
 	}
 
 	result.Text = textBuilder.String()
+	if structured != "" {
+		result.Text = structured
+		// Offsets pointed into the prose that the structured answer replaced.
+		for i := range result.Citations {
+			result.Citations[i].StartIndex, result.Citations[i].EndIndex = 0, 0
+		}
+	}
 }
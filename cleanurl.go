@@ -0,0 +1,63 @@
+package main
+
+import (
+	"net/url"
+	"strings"
+)
+
+// cleanURLs is set by -clean-urls: printed and exported sources drop tracking
+// parameters. Citation.URL itself is never rewritten, so link checks, dedup,
+// and -archive still see the URL the provider returned.
+var cleanURLs bool
+
+// displayTrackingParams are stripped by displayURL, on top of utm_*. The list
+// is wider than normalizeURL's since a wrong guess here only changes what's
+// shown.
+var displayTrackingParams = map[string]bool{
+	"fbclid": true, "gclid": true, "dclid": true, "msclkid": true, "yclid": true,
+	"igshid": true, "mc_cid": true, "mc_eid": true, "_hsenc": true, "_hsmi": true,
+	"ref": true, "ref_src": true, "ref_url": true, "referrer": true,
+	"affiliate": true, "aff_id": true, "affid": true, "tag": true, "srsltid": true,
+}
+
+// displayURL returns rawURL as it should be shown: unchanged unless
+// -clean-urls is on, in which case tracking and affiliate parameters are
+// removed. The remaining parameters keep their order and encoding.
+func displayURL(rawURL string) string {
+	if !cleanURLs {
+		return rawURL
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" || u.RawQuery == "" {
+		return rawURL
+	}
+	var kept []string
+	for _, pair := range strings.Split(u.RawQuery, "&") {
+		key, _, _ := strings.Cut(pair, "=")
+		if k, err := url.QueryUnescape(key); err == nil {
+			key = k
+		}
+		key = strings.ToLower(key)
+		if pair == "" || strings.HasPrefix(key, "utm_") || displayTrackingParams[key] {
+			continue
+		}
+		kept = append(kept, pair)
+	}
+	u.RawQuery = strings.Join(kept, "&")
+	u.ForceQuery = false
+	return u.String()
+}
+
+// displayCitations returns a copy of citations with display URLs, for
+// renderers that take whole Citation values.
+func displayCitations(citations []Citation) []Citation {
+	if !cleanURLs {
+		return citations
+	}
+	out := make([]Citation, len(citations))
+	for i, c := range citations {
+		c.URL = displayURL(c.URL)
+		out[i] = c
+	}
+	return out
+}
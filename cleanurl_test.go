@@ -0,0 +1,54 @@
+package main
+
+import "testing"
+
+func TestDisplayURL(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{"https://example.com/a", "https://example.com/a"},
+		{"https://example.com/a?utm_source=x&utm_medium=y", "https://example.com/a"},
+		{"https://example.com/a?id=7&utm_source=x&page=2", "https://example.com/a?id=7&page=2"},
+		{"https://example.com/a?b=2&fbclid=abc&a=1", "https://example.com/a?b=2&a=1"}, // Order kept
+		{"https://example.com/a?UTM_Campaign=x&GCLID=y&q=go", "https://example.com/a?q=go"},
+		{"https://www.amazon.com/dp/B0?tag=aff-20&th=1", "https://www.amazon.com/dp/B0?th=1"},
+		{"https://example.com/a?ref=hn#section", "https://example.com/a#section"},
+		{"https://example.com/a?q=hello%20world&utm_source=x", "https://example.com/a?q=hello%20world"}, // Encoding kept
+		{"https://example.com/a?utm%5Fsource=x&k=v", "https://example.com/a?k=v"},                       // Escaped key
+		{"https://example.com/a?&&utm_source=x", "https://example.com/a"},
+		{"https://example.com/wiki/Go_(programming_language)?utm_source=x", "https://example.com/wiki/Go_(programming_language)"},
+		{"https://example.com/a?reference=1", "https://example.com/a?reference=1"}, // Not a tracking param
+		{"not a url", "not a url"},
+		{"/relative?utm_source=x", "/relative?utm_source=x"},
+	}
+
+	cleanURLs = false
+	for _, tt := range tests {
+		if got := displayURL(tt.raw); got != tt.raw {
+			t.Errorf("without -clean-urls, displayURL(%q) = %q, want it unchanged", tt.raw, got)
+		}
+	}
+
+	cleanURLs = true
+	t.Cleanup(func() { cleanURLs = false })
+	for _, tt := range tests {
+		if got := displayURL(tt.raw); got != tt.want {
+			t.Errorf("displayURL(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestDisplayCitations(t *testing.T) {
+	in := []Citation{{URL: "https://example.com/a?utm_source=x", Title: "A"}}
+	cleanURLs = true
+	t.Cleanup(func() { cleanURLs = false })
+
+	out := displayCitations(in)
+	if out[0].URL != "https://example.com/a" || out[0].Title != "A" {
+		t.Errorf("displayCitations = %+v", out)
+	}
+	if in[0].URL != "https://example.com/a?utm_source=x" {
+		t.Errorf("displayCitations modified its input: %+v", in)
+	}
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	cohereModelID  = "command-r-plus-08-2024"
+	cohereEndpoint = "https://api.cohere.com/v1/chat"
+)
+
+func init() {
+	Register(&CohereProvider{})
+}
+
+// CohereProvider implements Provider for Cohere Command via the Chat API
+// with the web-search connector.
+// The HTTP client is created on first use and reused across queries.
+type CohereProvider struct {
+	clientOnce sync.Once
+	client     *http.Client
+	variant    modelVariant
+}
+
+func (p *CohereProvider) Name() string { return p.variant.name("cohere") }
+func (p *CohereProvider) DisplayName() string {
+	return p.variant.displayName(p.Name(), "Cohere Command R+")
+}
+func (p *CohereProvider) Emoji() string   { return "🟢" }
+func (p *CohereProvider) ModelID() string { return p.variant.modelOr(cohereModelID) }
+
+// WithModel returns an instance for one of the other Command models, e.g. command-a-03-2025.
+func (p *CohereProvider) WithModel(label, model string) (Provider, error) {
+	return &CohereProvider{variant: modelVariant{label: label, model: model}}, nil
+}
+
+func (p *CohereProvider) Capabilities() Capabilities { return Capabilities{SystemPrompt: true} }
+
+func (p *CohereProvider) CheckAuth() error {
+	if os.Getenv("COHERE_API_KEY") == "" {
+		return &AuthError{Reason: "COHERE_API_KEY not set", Hint: "export COHERE_API_KEY=... (dashboard.cohere.com)"}
+	}
+	return nil
+}
+
+func (p *CohereProvider) Query(ctx context.Context, query string, v Verbosity) Result {
+	return p.QueryConversation(ctx, []Message{{Role: RoleUser, Text: query}}, v)
+}
+
+func (p *CohereProvider) QueryConversation(ctx context.Context, history []Message, v Verbosity) Result {
+	start := time.Now()
+	result := Result{}
+
+	apiKey := os.Getenv("COHERE_API_KEY")
+
+	// The Chat API takes the current turn separately from prior turns.
+	reqBody := cohereRequest{
+		Model:       p.ModelID(),
+		Message:     history[len(history)-1].Text,
+		ChatHistory: cohereChatHistory(history[:len(history)-1]),
+		Preamble:    systemPrompt,
+		Connectors: []cohereConnector{
+			{ID: "web-search"},
+		},
+	}
+
+	if dryRun {
+		return dryRunResult(p, map[string]any{
+			"endpoint": cohereEndpoint,
+			"body":     reqBody,
+		})
+	}
+
+	slog.Debug("sending request", "provider", p.Name(), "connector", "web-search")
+
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		result.Error = fmt.Errorf("marshal error: %w", err)
+		return result
+	}
+	dumpBytes(p.Name()+"-request", jsonData)
+
+	req, err := http.NewRequestWithContext(ctx, "POST", cohereEndpoint, bytes.NewBuffer(jsonData))
+	if err != nil {
+		result.Error = fmt.Errorf("request error: %w", err)
+		return result
+	}
+
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	p.clientOnce.Do(func() { p.client = newHTTPClient(5 * time.Minute) })
+	resp, err := p.client.Do(req)
+	result.Duration = time.Since(start)
+
+	if err != nil {
+		result.Error = fmt.Errorf("API error: %w", err)
+		return result
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		result.Error = fmt.Errorf("read error: %w", err)
+		return result
+	}
+	dumpBytes(p.Name()+"-response", body)
+	keepRaw(&result, body)
+
+	if resp.StatusCode != http.StatusOK {
+		result.Error = &statusError{StatusCode: resp.StatusCode, Message: string(body)}
+		return result
+	}
+
+	var cohereResp cohereResponse
+	if err := json.Unmarshal(body, &cohereResp); err != nil {
+		result.Error = fmt.Errorf("%w: %w", errParse, err)
+		return result
+	}
+
+	// Extract token usage
+	result.Tokens.Input = cohereResp.Meta.BilledUnits.InputTokens
+	result.Tokens.Output = cohereResp.Meta.BilledUnits.OutputTokens
+
+	parseCohereResponse(&cohereResp, &result)
+	for _, q := range cohereResp.SearchQueries {
+		logSearchQuery(v, p.Name(), q.Text)
+		addSearchStep(&result, q.Text, 0)
+	}
+	return result
+}
+
+// cohereChatHistory maps prior conversation turns to Chat API history entries.
+func cohereChatHistory(history []Message) []cohereChatMessage {
+	if len(history) == 0 {
+		return nil
+	}
+	messages := make([]cohereChatMessage, 0, len(history))
+	for _, m := range history {
+		role := "USER"
+		if m.Role == RoleAssistant {
+			role = "CHATBOT"
+		}
+		messages = append(messages, cohereChatMessage{Role: role, Message: m.Text})
+	}
+	return messages
+}
+
+// --- Cohere API Types ---
+
+type cohereRequest struct {
+	Model       string              `json:"model"`
+	Message     string              `json:"message"`
+	Preamble    string              `json:"preamble,omitempty"` // Replaces Cohere's default system preamble
+	ChatHistory []cohereChatMessage `json:"chat_history,omitempty"`
+	Connectors  []cohereConnector   `json:"connectors,omitempty"`
+}
+
+type cohereChatMessage struct {
+	Role    string `json:"role"`
+	Message string `json:"message"`
+}
+
+type cohereConnector struct {
+	ID string `json:"id"`
+}
+
+type cohereResponse struct {
+	Text         string `json:"text"`
+	FinishReason string `json:"finish_reason"`
+	Citations    []struct {
+		Start       int      `json:"start"`
+		End         int      `json:"end"`
+		Text        string   `json:"text"`
+		DocumentIDs []string `json:"document_ids"`
+	} `json:"citations"`
+	Documents []struct {
+		ID      string `json:"id"`
+		URL     string `json:"url"`
+		Title   string `json:"title"`
+		Snippet string `json:"snippet"`
+	} `json:"documents"`
+	SearchQueries []struct {
+		Text string `json:"text"`
+	} `json:"search_queries"`
+	SearchResults []struct {
+		DocumentIDs []string `json:"document_ids"`
+	} `json:"search_results"`
+	Meta struct {
+		BilledUnits struct {
+			InputTokens  int `json:"input_tokens"`
+			OutputTokens int `json:"output_tokens"`
+		} `json:"billed_units"`
+	} `json:"meta"`
+}
+
+// parseCohereResponse resolves citation spans to document URLs. Citations are
+// character spans into the text, each referencing document IDs; documents that
+// no span references were retrieved but not used, so they are only listed when
+// the answer carries no spans at all.
+func parseCohereResponse(resp *cohereResponse, result *Result) {
+	result.Text = resp.Text
+	result.FinishReason = resp.FinishReason
+
+	docs := make(map[string]Citation, len(resp.Documents))
+	for _, d := range resp.Documents {
+		docs[d.ID] = Citation{URL: d.URL, Title: d.Title}
+	}
+
+	for _, sr := range resp.SearchResults {
+		result.SearchResults += len(sr.DocumentIDs)
+	}
+
+	seen := make(map[string]bool)
+	for _, span := range resp.Citations {
+		for _, id := range span.DocumentIDs {
+			if c, ok := docs[id]; ok {
+				DeduplicateCitations(&result.Citations, seen, c)
+			}
+		}
+	}
+
+	if len(resp.Citations) == 0 {
+		for _, d := range resp.Documents {
+			DeduplicateCitations(&result.Citations, seen, docs[d.ID])
+		}
+	}
+}
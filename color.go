@@ -0,0 +1,60 @@
+package main
+
+import (
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// colorEnabled is set by setupColor; when false every helper returns its input unchanged.
+var colorEnabled bool
+
+// ANSI SGR codes used by the display layer.
+const (
+	ansiReset  = "\033[0m"
+	ansiBold   = "\033[1m"
+	ansiDim    = "\033[2m"
+	ansiItalic = "\033[3m"
+	ansiRed    = "\033[31m"
+	ansiGreen  = "\033[32m"
+	ansiYellow = "\033[33m"
+)
+
+// setupColor enables color unless -no-color or NO_COLOR (any value) is set, or
+// stdout isn't a terminal.
+func setupColor(noColor bool) {
+	_, envNoColor := os.LookupEnv("NO_COLOR")
+	colorEnabled = !noColor && !envNoColor && term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+func colorize(code, s string) string {
+	if !colorEnabled || s == "" {
+		return s
+	}
+	return code + s + ansiReset
+}
+
+func bold(s string) string   { return colorize(ansiBold, s) }
+func dim(s string) string    { return colorize(ansiDim, s) }
+func red(s string) string    { return colorize(ansiRed, s) }
+func green(s string) string  { return colorize(ansiGreen, s) }
+func yellow(s string) string { return colorize(ansiYellow, s) }
+
+// scoreColor colors s by a 0-10 score band: green ≥7, yellow ≥4, red below.
+func scoreColor(score float64, s string) string {
+	switch {
+	case score >= 7:
+		return green(s)
+	case score >= 4:
+		return yellow(s)
+	default:
+		return red(s)
+	}
+}
+
+// scoreBar renders a 0-10 score as a 10-cell bar colored by band.
+func scoreBar(score float64) string {
+	filled := min(max(int(score+0.5), 0), 10)
+	return scoreColor(score, strings.Repeat("█", filled)) + dim(strings.Repeat("░", 10-filled))
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// compareTo is the -compare-to baseline: a saved -format json document or
+// -jsonl-out file to diff the new run against.
+var compareTo string
+
+// compareMaxURLs caps the added/removed URLs listed per provider.
+const compareMaxURLs = 5
+
+// loadBaseline reads the saved run for query from path. The file may hold one
+// JSON document or many JSON lines; the last record for query wins. With an
+// empty query, the last record in the file is used.
+func loadBaseline(path, query string) (*jsonQueryRecord, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read baseline: %w", err)
+	}
+
+	var found *jsonQueryRecord
+	var queries []string
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for {
+		var rec jsonQueryRecord
+		if err := dec.Decode(&rec); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("parse baseline %s: %w", path, err)
+		}
+		// -quiet -format json writes a single result, not a query record.
+		if len(rec.Results) == 0 {
+			continue
+		}
+		queries = append(queries, rec.Query)
+		if query == "" || rec.Query == query {
+			found = &rec
+		}
+	}
+
+	switch {
+	case found != nil:
+		return found, nil
+	case len(queries) == 0:
+		return nil, fmt.Errorf("baseline %s has no query records (save one with -format json or -jsonl-out)", path)
+	}
+	return nil, fmt.Errorf("baseline %s has no run for %q (it has: %s)", path, query, strings.Join(queries, "; "))
+}
+
+// providerDiff is what changed for one provider between the baseline and the new run.
+type providerDiff struct {
+	Provider string
+	Model    string
+	Before   *jsonModelResult // nil if the provider wasn't in the baseline
+	After    *jsonModelResult // nil if the provider didn't run this time
+	Added    []string         // Cited now but not in the baseline
+	Removed  []string         // Cited in the baseline but not now
+	Overlap  float64          // Jaccard similarity of the citation sets
+}
+
+// diffBaseline compares results per provider, in the new run's rank order
+// followed by providers only in the baseline. Citations are matched by
+// normalized URL.
+func diffBaseline(base, cur jsonQueryRecord) []providerDiff {
+	before := make(map[string]*jsonModelResult)
+	for i := range base.Results {
+		before[base.Results[i].Provider] = &base.Results[i]
+	}
+
+	var diffs []providerDiff
+	seen := make(map[string]bool)
+	for i := range cur.Results {
+		after := &cur.Results[i]
+		seen[after.Provider] = true
+		diffs = append(diffs, newProviderDiff(after.Provider, after.Model, before[after.Provider], after))
+	}
+	for i := range base.Results {
+		if b := &base.Results[i]; !seen[b.Provider] {
+			diffs = append(diffs, newProviderDiff(b.Provider, b.Model, b, nil))
+		}
+	}
+	return diffs
+}
+
+func newProviderDiff(provider, model string, before, after *jsonModelResult) providerDiff {
+	d := providerDiff{Provider: provider, Model: model, Before: before, After: after}
+	if before == nil || after == nil {
+		return d
+	}
+
+	was, now := citationKeys(before.Citations), citationKeys(after.Citations)
+	for _, c := range after.Citations {
+		if k := normalizeURL(c.URL); !was[k] {
+			d.Added = append(d.Added, c.URL)
+			was[k] = true // Count each URL once
+		}
+	}
+	for _, c := range before.Citations {
+		if k := normalizeURL(c.URL); !now[k] {
+			d.Removed = append(d.Removed, c.URL)
+			now[k] = true
+		}
+	}
+	d.Overlap = jaccard(citationKeys(before.Citations), citationKeys(after.Citations))
+	return d
+}
+
+func citationKeys(citations []jsonCitation) map[string]bool {
+	keys := make(map[string]bool, len(citations))
+	for _, c := range citations {
+		keys[normalizeURL(c.URL)] = true
+	}
+	return keys
+}
+
+// Unchanged reports whether nothing compared differs.
+func (d providerDiff) Unchanged() bool {
+	b, a := d.Before, d.After
+	if b == nil || a == nil {
+		return false
+	}
+	return b.Error == a.Error && b.Words == a.Words && len(d.Added) == 0 && len(d.Removed) == 0 &&
+		judgeOverall(b) == judgeOverall(a)
+}
+
+// judgeOverall returns the judge's overall score, or -1 if unscored.
+func judgeOverall(r *jsonModelResult) float64 {
+	if r.Judge == nil {
+		return -1
+	}
+	return r.Judge.Overall
+}
+
+// printBaselineDiff prints a concise per-provider "what changed" report.
+func printBaselineDiff(path string, base jsonQueryRecord, diffs []providerDiff) {
+	fmt.Println(bold(fmt.Sprintf("🔁 Changes vs. baseline %s (%s)", path, base.Timestamp.Local().Format("2006-01-02 15:04"))))
+	fmt.Println(strings.Repeat("─", 70))
+
+	for _, d := range diffs {
+		label := d.Model
+		if p, ok := Get(d.Provider); ok {
+			label = p.Emoji() + " " + label
+		}
+		b, a := d.Before, d.After
+
+		switch {
+		case b == nil:
+			fmt.Printf("%s: 🆕 not in baseline\n", label)
+			continue
+		case a == nil:
+			fmt.Printf("%s: ➖ not in this run\n", label)
+			continue
+		case b.Error == "" && a.Error != "":
+			fmt.Printf("%s: %s\n", label, red("❌ now failing: "+a.Error))
+			continue
+		case a.Error != "":
+			fmt.Printf("%s: ❌ still failing: %s\n", label, a.Error)
+			continue
+		case b.Error != "":
+			fmt.Printf("%s: %s\n", label, green("✅ recovered (was: "+b.Error+")"))
+			continue
+		case d.Unchanged():
+			fmt.Printf("%s: %s\n", label, dim("unchanged"))
+			continue
+		}
+
+		parts := []string{
+			fmt.Sprintf("words %d → %d (%+d)", b.Words, a.Words, a.Words-b.Words),
+			fmt.Sprintf("citations %d → %d (+%d/-%d, %.0f%% same)", len(b.Citations), len(a.Citations), len(d.Added), len(d.Removed), d.Overlap*100),
+		}
+		if sb, sa := judgeOverall(b), judgeOverall(a); sb >= 0 && sa >= 0 {
+			score := fmt.Sprintf("score %.1f → %.1f (%+.1f)", sb, sa, sa-sb)
+			if sa < sb {
+				score = yellow(score)
+			}
+			parts = append(parts, score)
+		}
+		fmt.Printf("%s: %s\n", label, strings.Join(parts, " | "))
+		printURLChanges("+", d.Added)
+		printURLChanges("-", d.Removed)
+	}
+	fmt.Println()
+}
+
+func printURLChanges(sign string, urls []string) {
+	for i, u := range urls {
+		if i == compareMaxURLs {
+			fmt.Printf("   %s ... and %d more\n", sign, len(urls)-compareMaxURLs)
+			return
+		}
+		fmt.Printf("   %s %s\n", sign, dim(displayURL(u)))
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Panel confidence levels.
+const (
+	ConfidenceHigh   = "high"
+	ConfidenceMedium = "medium"
+	ConfidenceLow    = "low"
+)
+
+// Thresholds for the panel confidence signals.
+const (
+	confidenceMinOverlap = 25.0 // % of sources cited by 2+ models
+	confidenceMaxSpread  = 1.5  // Standard deviation of judge overall scores
+)
+
+// panelConfidence is a heuristic for how far to trust the combined answer.
+type panelConfidence struct {
+	Level     string
+	Rationale string
+}
+
+// assessPanelConfidence rates the panel from three signals: whether most
+// models actually searched, how much their sources overlap, and how closely
+// the judge scored them. Every available signal passing is high and one
+// failing is medium; two failing, or a panel that mostly didn't search, is low.
+// Judge spread is skipped when fewer than two models were scored.
+func assessPanelConfidence(results []ModelResult) panelConfidence {
+	var answered, searched int
+	var scores []float64
+	for _, mr := range results {
+		r := mr.Result
+		if r.Error != nil {
+			continue
+		}
+		answered++
+		if !r.Ungrounded && r.SearchError == "" && (len(r.Citations) > 0 || r.SearchResults > 0) {
+			searched++
+		}
+		if mr.JudgeScore != nil {
+			scores = append(scores, mr.JudgeScore.Overall)
+		}
+	}
+	if answered < 2 {
+		return panelConfidence{ConfidenceLow, fmt.Sprintf("only %d model answered, nothing to cross-check", answered)}
+	}
+
+	var reasons []string
+	failed := 0
+
+	reasons = append(reasons, fmt.Sprintf("%d/%d models searched", searched, answered))
+	if searched*2 <= answered {
+		return panelConfidence{ConfidenceLow, strings.Join(reasons, ", ") + " (most answered without live sources)"}
+	}
+
+	a := computeAgreement(results)
+	switch {
+	case a.Sources == 0:
+		failed++
+		reasons = append(reasons, "no sources to compare")
+	case a.Consensus == 0:
+		failed++
+		reasons = append(reasons, "no shared sources")
+	default:
+		if a.Percent() < confidenceMinOverlap {
+			failed++
+		}
+		reasons = append(reasons, fmt.Sprintf("%.0f%% shared sources", a.Percent()))
+	}
+
+	if len(scores) >= 2 {
+		spread := stddev(scores)
+		if spread > confidenceMaxSpread {
+			failed++
+			reasons = append(reasons, fmt.Sprintf("judge scores disagree (±%.1f)", spread))
+		} else {
+			reasons = append(reasons, fmt.Sprintf("judge scores agree (±%.1f)", spread))
+		}
+	}
+
+	level := ConfidenceHigh
+	switch {
+	case failed >= 2:
+		level = ConfidenceLow
+	case failed == 1:
+		level = ConfidenceMedium
+	}
+	return panelConfidence{level, strings.Join(reasons, ", ")}
+}
+
+// stddev returns the population standard deviation of xs.
+func stddev(xs []float64) float64 {
+	var mean float64
+	for _, x := range xs {
+		mean += x
+	}
+	mean /= float64(len(xs))
+	var sq float64
+	for _, x := range xs {
+		sq += (x - mean) * (x - mean)
+	}
+	return math.Sqrt(sq / float64(len(xs)))
+}
+
+// printPanelConfidence prints the confidence line for the combined summary.
+func printPanelConfidence(results []ModelResult) {
+	c := assessPanelConfidence(results)
+	label := "Panel confidence: " + c.Level
+	switch c.Level {
+	case ConfidenceHigh:
+		label = green(label)
+	case ConfidenceMedium:
+		label = yellow(label)
+	default:
+		label = red(label)
+	}
+	fmt.Println()
+	fmt.Printf("🎯 %s — %s\n", bold(label), c.Rationale)
+}
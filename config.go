@@ -0,0 +1,160 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// defaultConfigName is looked up in the home directory when -config isn't given.
+const defaultConfigName = ".web-search.yaml"
+
+// applyConfigFile loads flag defaults from a YAML config. Keys are flag names
+// (e.g. "providers", "judge-weights"); flags given on the command line win.
+// Three sections are special:
+//
+//	env:          # set environment variables that aren't already set
+//	  XAI_BASE_URL: https://gateway.example.com/v1
+//	api_key_env:  # read a standard key from a team-specific variable
+//	  ANTHROPIC_API_KEY: TEAM_ANTHROPIC_KEY
+//	max_token_estimate:  # worst-case input:output tokens for -budget
+//	  claude: 60000:4096
+//
+// An empty path means ~/.web-search.yaml, which may be absent.
+func applyConfigFile(path string) error {
+	explicit := path != ""
+	if !explicit {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil
+		}
+		path = filepath.Join(home, defaultConfigName)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !explicit && errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("read config: %w", err)
+	}
+
+	var cfg map[string]any
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return fmt.Errorf("parse config %s: %w", path, err)
+	}
+
+	setOnCLI := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { setOnCLI[f.Name] = true })
+
+	keys := make([]string, 0, len(cfg))
+	for k := range cfg {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		val := cfg[key]
+		switch key {
+		case "env":
+			vars, err := configStringMap(key, val)
+			if err != nil {
+				return err
+			}
+			for name, v := range vars {
+				if os.Getenv(name) == "" {
+					os.Setenv(name, v)
+				}
+			}
+			continue
+		case "api_key_env":
+			aliases, err := configStringMap(key, val)
+			if err != nil {
+				return err
+			}
+			for name, from := range aliases {
+				if os.Getenv(name) == "" && os.Getenv(from) != "" {
+					os.Setenv(name, os.Getenv(from))
+				}
+			}
+			continue
+		case "max_token_estimate":
+			// Provider names are checked once every provider is registered.
+			estimates, err := configStringMap(key, val)
+			if err != nil {
+				return err
+			}
+			names := make([]string, 0, len(estimates))
+			for name := range estimates {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			pairs := make([]string, len(names))
+			for i, name := range names {
+				pairs[i] = name + "=" + estimates[name]
+			}
+			maxTokenEstimateConfig = strings.Join(pairs, ",")
+			continue
+		case "config":
+			return fmt.Errorf("config %s: \"config\" can't be set from a config file", path)
+		}
+
+		if flag.Lookup(key) == nil {
+			return fmt.Errorf("config %s: unknown key %q (keys are flag names)", path, key)
+		}
+		if setOnCLI[key] {
+			continue
+		}
+		if err := flag.Set(key, configFlagValue(val)); err != nil {
+			return fmt.Errorf("config %s: %s: %w", path, key, err)
+		}
+	}
+	return nil
+}
+
+// configFlagValue renders a YAML value as flag text. Lists become comma-separated
+// (providers: [nova, claude]) and maps become key=value pairs (judge-weights: {recency: 0.5}).
+func configFlagValue(v any) string {
+	switch val := v.(type) {
+	case []any:
+		parts := make([]string, len(val))
+		for i, item := range val {
+			parts[i] = fmt.Sprint(item)
+		}
+		return strings.Join(parts, ",")
+	case map[string]any:
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		parts := make([]string, len(keys))
+		for i, k := range keys {
+			parts[i] = k + "=" + fmt.Sprint(val[k])
+		}
+		return strings.Join(parts, ",")
+	case nil:
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+// configStringMap reads a section of NAME: value pairs.
+func configStringMap(key string, v any) (map[string]string, error) {
+	m, ok := v.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("config: %q must be a map of NAME: value", key)
+	}
+	out := make(map[string]string, len(m))
+	for k, val := range m {
+		out[k] = fmt.Sprint(val)
+	}
+	return out, nil
+}
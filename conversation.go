@@ -0,0 +1,234 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+// Conversation roles.
+const (
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+// Message is a single conversation turn.
+type Message struct {
+	Role   string // RoleUser or RoleAssistant
+	Text   string
+	Images []queryImage // -image attachments; user messages only
+}
+
+// Conversation holds the message history for a multi-turn exchange with one provider.
+type Conversation struct {
+	Messages []Message
+}
+
+// ConversationProvider is implemented by providers that can send prior turns
+// in their native message format. Providers that don't implement it are
+// adapted by QueryConversation.
+type ConversationProvider interface {
+	QueryConversation(ctx context.Context, history []Message, v Verbosity) Result
+}
+
+// QueryConversation sends a conversation history to p. The last message must be the
+// user's current question. Providers without native multi-turn support receive the
+// history flattened into a single prompt.
+func QueryConversation(ctx context.Context, p Provider, history []Message, v Verbosity) Result {
+	if cp, ok := p.(ConversationProvider); ok {
+		return cp.QueryConversation(ctx, history, v)
+	}
+	return p.Query(ctx, flattenHistory(history), v)
+}
+
+// flattenHistory renders prior turns as a transcript preceding the current question.
+func flattenHistory(history []Message) string {
+	if len(history) == 0 {
+		return ""
+	}
+	current := history[len(history)-1].Text
+	if len(history) == 1 {
+		return current
+	}
+
+	var b strings.Builder
+	b.WriteString("Previous conversation:\n")
+	for _, m := range history[:len(history)-1] {
+		role := "User"
+		if m.Role == RoleAssistant {
+			role = "Assistant"
+		}
+		b.WriteString(role + ": " + m.Text + "\n")
+	}
+	b.WriteString("\nCurrent question: " + current)
+	return b.String()
+}
+
+// conversations holds per-provider history while multi-turn mode (REPL) is active.
+// A nil map means every query is sent standalone.
+var (
+	conversations   map[string]*Conversation
+	conversationsMu sync.Mutex
+)
+
+// prepareQuery applies the -lang, -since, and domain instructions p needs
+// (providers with native support get the query unchanged for that feature).
+func prepareQuery(p Provider, query string) string {
+	return applyDomainConstraints(p, applyRecency(p, localizeQuery(query)))
+}
+
+// queryWithHistory queries p, including its prior turns when multi-turn mode is active,
+// and records the new exchange on success.
+// Images from -image go with the first user turn; providers without vision
+// fail with errNoVision instead of answering without them.
+func queryWithHistory(ctx context.Context, p Provider, query string) Result {
+	if len(queryImages) > 0 && !capabilitiesOf(p).Vision {
+		return Result{Error: errNoVision, ErrorKind: ErrorKindOther}
+	}
+	query = prepareQuery(p, query)
+
+	conversationsMu.Lock()
+	if conversations == nil {
+		conversationsMu.Unlock()
+		r := withProviderTimeout(ctx, p, func(ctx context.Context) Result {
+			if len(queryImages) > 0 {
+				return QueryConversation(ctx, p, []Message{userMessage(query)}, verbosity)
+			}
+			return p.Query(ctx, query, verbosity)
+		})
+		normalizeResult(p, &r)
+		return r
+	}
+	conv, ok := conversations[p.Name()]
+	if !ok {
+		conv = &Conversation{}
+		conversations[p.Name()] = conv
+	}
+	turn := Message{Role: RoleUser, Text: query}
+	if len(conv.Messages) == 0 {
+		turn = userMessage(query)
+	}
+	history := append(append([]Message(nil), conv.Messages...), turn)
+	conversationsMu.Unlock()
+
+	r := withProviderTimeout(ctx, p, func(ctx context.Context) Result {
+		return QueryConversation(ctx, p, history, verbosity)
+	})
+	normalizeResult(p, &r)
+	if r.Error == nil {
+		conversationsMu.Lock()
+		conv.Messages = append(history, Message{Role: RoleAssistant, Text: r.Text})
+		conversationsMu.Unlock()
+	}
+	return r
+}
+
+// citationFollowUp is the follow-up turn sent when an answer has too few citations.
+const citationFollowUp = "Your answer cited fewer than %d sources. Search the web further and revise your answer, citing at least %d distinct sources."
+
+// queryProvider answers query from the -cache-responses cache when it can,
+// otherwise through queryWithMinCitations, caching a successful answer.
+func queryProvider(ctx context.Context, p Provider, query string) Result {
+	if r, ok := loadCachedResponse(p, query); ok {
+		return r
+	}
+	r := queryWithMinCitations(ctx, p, query)
+	storeCachedResponse(p, query, r)
+	return r
+}
+
+// queryWithMinCitations queries p and, when -min-citations is set and the answer
+// cites fewer sources, sends one follow-up turn asking it to search more. The
+// revised answer replaces the original; tokens and duration cover both turns.
+func queryWithMinCitations(ctx context.Context, p Provider, query string) Result {
+	r := queryWithHistory(ctx, p, query)
+	if minCitations <= 0 || dryRun || r.Error != nil || len(r.Citations) >= minCitations {
+		return r
+	}
+
+	slog.Info("re-prompting for citations", "provider", p.Name(), "citations", len(r.Citations), "min", minCitations)
+	followUp := fmt.Sprintf(citationFollowUp, minCitations, minCitations)
+
+	conversationsMu.Lock()
+	multiTurn := conversations != nil
+	conversationsMu.Unlock()
+
+	var retry Result
+	if multiTurn {
+		retry = queryWithHistory(ctx, p, followUp)
+	} else {
+		retry = withProviderTimeout(ctx, p, func(ctx context.Context) Result {
+			return QueryConversation(ctx, p, []Message{
+				userMessage(query),
+				{Role: RoleAssistant, Text: r.Text},
+				{Role: RoleUser, Text: followUp},
+			}, verbosity)
+		})
+		normalizeResult(p, &retry)
+	}
+
+	r.RePrompted = true
+	if retry.Error != nil {
+		slog.Warn("citation re-prompt failed, keeping first answer", "provider", p.Name(), "error", retry.Error)
+		return r
+	}
+
+	// Keep sources from the first turn; the revision often builds on them.
+	seen := make(map[string]bool)
+	for _, c := range retry.Citations {
+		seen[c.URL] = true
+	}
+	for _, c := range r.Citations {
+		MergeCitation(&retry.Citations, seen, c)
+	}
+
+	retry.RePrompted = true
+	retry.Duration += r.Duration
+	retry.Tokens.Input += r.Tokens.Input
+	retry.Tokens.Output += r.Tokens.Output
+	retry.Tokens.CachedInput += r.Tokens.CachedInput
+	retry.Tokens.Reasoning += r.Tokens.Reasoning
+	retry.SearchResults += r.SearchResults
+	return retry
+}
+
+// errEmptyResponse marks a query that succeeded but returned no answer text,
+// e.g. a Gemini candidate with no parts or a Claude reply that was all thinking.
+var errEmptyResponse = errors.New("empty response (no text content)")
+
+// checkEmptyResponse turns a successful result with no answer text into an
+// error, so it can't rank as a valid zero-word answer.
+func checkEmptyResponse(r *Result) {
+	if r.Error == nil && strings.TrimSpace(r.Text) == "" {
+		r.Error = errEmptyResponse
+	}
+}
+
+// normalizeResult applies provider-independent cleanup after a query, so every
+// provider's result looks the same to display and judging. Inline reasoning tags
+// are moved from Text into Thinking, empty answers become errEmptyResponse,
+// citations without a usable http(s) URL are dropped, the rest are filtered by
+// the domain lists and put in reference order, the response language is
+// detected, and structured answers are checked against -answer-schema.
+func normalizeResult(p Provider, r *Result) {
+	sanitizeCitations(r, p.Name())
+	filterCitations(r)
+	sortCitationsByIndex(r.Citations)
+	clean, thinking := extractThinkingTags(r.Text)
+	if thinking != "" {
+		r.Text = clean
+		AppendThinking(r, thinking)
+	}
+	checkEmptyResponse(r)
+	r.Language = detectLanguage(r.Text)
+	r.ErrorKind = classifyError(r.Error)
+	if answerSchema != nil && r.Error == nil {
+		if err := validateAnswer(r.Text, answerSchema); err != nil {
+			r.Error = fmt.Errorf("schema validation failed: %w", err)
+			r.ErrorKind = ErrorKindParse
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"regexp"
 	"strings"
@@ -12,8 +13,19 @@ type ModelResult struct {
 	Provider   Provider
 	Result     Result
 	JudgeScore *JudgeScore
+	Rank       int // 1 = best, set by rankResults; 0 when unranked
 }
 
+// maxCitationsDisplay caps each answer's source list in the terminal
+// (-max-citations-display; 0 shows all). JSON and HTML exports list every
+// citation.
+var maxCitationsDisplay = 15
+
+// warnSlow flags providers slower than this in the comparison summary
+// (-warn-slow; 0 = off). A slow answer often means retries or a degraded
+// endpoint rather than a hard question.
+var warnSlow time.Duration
+
 func printHeader() {
 	fmt.Println("╔══════════════════════════════════════════════════════════════╗")
 	fmt.Println("║                    WEB SEARCH CLI                            ║")
@@ -22,17 +34,6 @@ func printHeader() {
 	fmt.Println()
 }
 
-func printSkippedProviders(skipped []string) {
-	if len(skipped) == 0 {
-		return
-	}
-	fmt.Println("⏭️  Skipping providers (missing credentials):")
-	for _, msg := range skipped {
-		fmt.Printf("   %s\n", msg)
-	}
-	fmt.Println()
-}
-
 func printModelResult(mr ModelResult) {
 	printModelResultWithRank(mr, 0)
 }
@@ -44,58 +45,101 @@ func printModelResultWithRank(mr ModelResult, rank int) {
 	// Build header
 	header := fmt.Sprintf("%s %s", p.Emoji(), p.DisplayName())
 	if rank > 0 {
-		medals := []string{"🥇", "🥈", "🥉", "  "}
-		medal := medals[min(rank-1, 3)]
-		header = fmt.Sprintf("%s #%d %s", medal, rank, header)
+		header = fmt.Sprintf("%s #%d %s", rankMedal(rank), rank, header)
 	}
 	if r.Duration > 0 {
 		header += fmt.Sprintf(" (%v)", r.Duration.Round(time.Millisecond))
 	}
+	if r.Cached {
+		header += " (cached)"
+	}
 
+	switch {
+	case r.Error != nil:
+		header = red(header)
+	case rank == 1:
+		header = bold(green(header))
+	}
 	fmt.Printf("┌─ %s\n", header)
 
+	if errors.Is(r.Error, errInterrupted) {
+		fmt.Println("│ ⏹️  (interrupted)")
+		fmt.Println("└" + strings.Repeat("─", 60))
+		return
+	}
 	if r.Error != nil {
-		fmt.Printf("│ ❌ Error: %v\n", r.Error)
+		fmt.Printf("│ %s %s\n", r.ErrorKind.Icon(), red(fmt.Sprintf("Error: %v", r.Error)))
 		fmt.Println("└" + strings.Repeat("─", 60))
 		return
 	}
 
 	// Stats line with judge score
-	wordCount := len(strings.Fields(r.Text))
+	wordInfo := fmt.Sprintf("%d words (%s)", r.WordCount(), readingLabel(r.ReadingTime()))
+	searchInfo := searchStatus(r)
+	if r.RePrompted {
+		searchInfo += " | 🔁 re-prompted for citations"
+	}
+	if r.FilteredCitations > 0 {
+		searchInfo += fmt.Sprintf(" | ⚠️ relied on %d excluded-domain sources (dropped)", r.FilteredCitations)
+	}
+	if r.Truncated() {
+		searchInfo += " | " + yellow("⚠️ truncated (max_tokens)")
+	}
 	if mr.JudgeScore != nil {
-		fmt.Printf("│ 📊 %d words | %d citations | judge: %.1f/10\n", wordCount, len(r.Citations), mr.JudgeScore.Overall)
-		fmt.Printf("│ 🏛️  Quality: %d | Links: %d | Recency: %d | Significance: %d | Impact: %d\n",
-			mr.JudgeScore.Quality, mr.JudgeScore.LinkHealth, mr.JudgeScore.Recency, mr.JudgeScore.Significance, mr.JudgeScore.Impact)
+		fmt.Printf("│ 📊 %s | %d citations | %d domains%s | judge: %s %s\n", wordInfo, len(r.Citations), r.UniqueDomains(), searchInfo,
+			scoreBar(mr.JudgeScore.Overall), scoreColor(mr.JudgeScore.Overall, fmt.Sprintf("%.1f/10", mr.JudgeScore.Overall)))
+		fmt.Printf("│ 🏛️  Quality: %d | Links: %d | Diversity: %d | Recency: %d | Significance: %d | Impact: %d\n",
+			mr.JudgeScore.Quality, mr.JudgeScore.LinkHealth, mr.JudgeScore.Diversity, mr.JudgeScore.Recency, mr.JudgeScore.Significance, mr.JudgeScore.Impact)
 		if mr.JudgeScore.Reasoning != "" {
 			reasoning := mr.JudgeScore.Reasoning
 			if len(reasoning) > 120 {
 				reasoning = reasoning[:117] + "..."
 			}
-			fmt.Printf("│ 💬 %q\n", reasoning)
+			fmt.Printf("│ 💬 %s\n", dim(fmt.Sprintf("%q", reasoning)))
 		}
 	} else {
-		fmt.Printf("│ 📊 %d words | %d citations\n", wordCount, len(r.Citations))
+		fmt.Printf("│ 📊 %s | %d citations | %d domains%s\n", wordInfo, len(r.Citations), r.UniqueDomains(), searchInfo)
 	}
-	if r.Tokens.Input > 0 || r.Tokens.Output > 0 {
-		tokenCost := r.TokenCost(p.Name())
-		searchCost := SearchCost[p.Name()]
-		estTotal := r.EstimatedCost(p.Name())
+	tokenCost := r.TokenCost(p.Name())
+	estTotal := r.EstimatedCost(p.Name())
+	searchCost := estTotal - tokenCost // Zero for ungrounded answers
+	switch {
+	case r.Cached:
+		fmt.Printf("│ 💰 $0 (cached; originally %s)\n", tokenSummary(r.Tokens))
+	case costUnknown(p.Name()):
+		search := ""
 		if searchCost > 0 {
-			fmt.Printf("│ 💰 ~$%.4f est. (tokens: $%.4f + search: ~$%.4f)\n", estTotal, tokenCost, searchCost)
-		} else {
-			fmt.Printf("│ 💰 $%.4f (%d in / %d out tokens)\n", tokenCost, r.Tokens.Input, r.Tokens.Output)
+			search = fmt.Sprintf(" + search: ~$%.4f", searchCost)
 		}
+		fmt.Printf("│ 💰 cost unknown: no price for %s (%s)%s\n", modelIDOf(p), tokenSummary(r.Tokens), search)
+	case r.Tokens.Input == 0 && r.Tokens.Output == 0:
+		// Some APIs omit usage; the search fee is still charged and counted in totals.
+		if searchCost > 0 {
+			fmt.Printf("│ 💰 ~$%.4f est. (search: ~$%.4f; token usage unavailable)\n", estTotal, searchCost)
+		}
+	case searchCost > 0:
+		fmt.Printf("│ 💰 ~$%.4f est. (tokens: $%.4f + search: ~$%.4f)\n", estTotal, tokenCost, searchCost)
+	default:
+		fmt.Printf("│ 💰 $%.4f (%s)\n", tokenCost, tokenSummary(r.Tokens))
 	}
+	printTimeline(r)
 	fmt.Println("│")
 
+	if showThinking && r.Thinking != "" {
+		fmt.Println("│ 🧠 Reasoning:")
+		for _, line := range wrapText(strings.TrimSpace(r.Thinking), gutterWidth(4)) {
+			fmt.Printf("│   %s\n", dim(line))
+		}
+		fmt.Println("│")
+	}
+
 	// Print response text
-	text := r.Text
-	if !showThinking {
-		text = stripThinkingTags(text)
+	text := stripThinkingTags(r.Text)
+	if renderEnabled() {
+		text = renderMarkdownTerminal(text)
 	}
 
-	lines := strings.Split(text, "\n")
-	for _, line := range lines {
+	for _, line := range wrapText(text, gutterWidth(2)) {
 		fmt.Printf("│ %s\n", line)
 	}
 
@@ -103,12 +147,22 @@ func printModelResultWithRank(mr ModelResult, rank int) {
 	if len(r.Citations) > 0 {
 		fmt.Println("│")
 		fmt.Println("│ 📎 Sources:")
+		nums := referenceNumbers(r.Citations)
 		for i, citation := range r.Citations {
+			if maxCitationsDisplay > 0 && i == maxCitationsDisplay {
+				fmt.Printf("│   ... and %d more sources\n", len(r.Citations)-i)
+				break
+			}
 			if citation.Title != "" {
-				fmt.Printf("│   [%d] %s\n", i+1, citation.Title)
-				fmt.Printf("│       %s\n", citation.URL)
+				fmt.Printf("│   [%d] %s%s\n", nums[i], trustLabel(citation), citation.Title)
+				fmt.Printf("│       %s\n", dim(displayURL(citation.URL)))
 			} else {
-				fmt.Printf("│   [%d] %s\n", i+1, citation.URL)
+				fmt.Printf("│   [%d] %s%s\n", nums[i], trustLabel(citation), dim(displayURL(citation.URL)))
+			}
+			if verbosity >= VerbosityVerbose && citation.Snippet != "" {
+				for _, line := range wrapText("“"+strings.TrimSpace(citation.Snippet)+"”", gutterWidth(8)) {
+					fmt.Printf("│       %s\n", dim(line))
+				}
 			}
 		}
 	}
@@ -116,43 +170,115 @@ func printModelResultWithRank(mr ModelResult, rank int) {
 	fmt.Println("└" + strings.Repeat("─", 60))
 }
 
+// costLabel formats r's estimated cost as "~$0.0123", or "unknown" when the
+// provider's model has no known token price.
+func costLabel(provider string, r Result) string {
+	if costUnknown(provider) && !r.Cached {
+		return "unknown"
+	}
+	return fmt.Sprintf("~$%.4f", r.EstimatedCost(provider))
+}
+
+// tokenSummary formats token counts, noting cached and reasoning portions when reported.
+func tokenSummary(t TokenUsage) string {
+	in := fmt.Sprintf("%d in", t.Input)
+	if t.CachedInput > 0 {
+		in += fmt.Sprintf(" [%d cached]", t.CachedInput)
+	}
+	out := fmt.Sprintf("%d out", t.Output)
+	if t.Reasoning > 0 {
+		out += fmt.Sprintf(" [%d reasoning]", t.Reasoning)
+	}
+	return in + " / " + out + " tokens"
+}
+
+// readingLabel formats a reading time as "~N min read", or "<1 min read".
+func readingLabel(d time.Duration) string {
+	if d < time.Minute {
+		return "<1 min read"
+	}
+	return fmt.Sprintf("~%.0f min read", d.Minutes())
+}
+
+// searchStatus describes search tool outcome for the stats line, so a failed
+// search is distinguishable from a model that chose not to search.
+func searchStatus(r Result) string {
+	if r.Ungrounded {
+		return " | ⚠️ ungrounded (search unavailable, answered from model knowledge)"
+	}
+	if r.SearchError != "" {
+		return fmt.Sprintf(" | ⚠️ search failed: %s", r.SearchError)
+	}
+	if r.SearchResults > 0 {
+		return fmt.Sprintf(" | %d search results", r.SearchResults)
+	}
+	return ""
+}
+
 func printComparisonSummary(results []ModelResult) {
 	fmt.Println("╔══════════════════════════════════════════════════════════════════════╗")
 	fmt.Println("║                        RANKING & PERFORMANCE                         ║")
 	fmt.Println("╠══════════════════════════════════════════════════════════════════════╣")
 
 	var totalEstCost float64
-	for i, mr := range results {
+	unknownCost := false
+	for _, mr := range results {
 		p := mr.Provider
 		r := mr.Result
 
 		status := "✅"
+		// Long names (model variants, -providers-file entries) are cut to keep the box aligned.
+		name := fmt.Sprintf("%-22s", truncateRunes(p.DisplayName(), 22))
 		if r.Error != nil {
 			status = "❌"
+			name = red(name)
+		} else if mr.Rank == 1 && reverseRank {
+			name = yellow(name)
+		} else if mr.Rank == 1 {
+			name = green(name)
 		}
 
-		medals := []string{"🥇", "🥈", "🥉", "  "}
-		medal := medals[min(i, 3)]
+		medal := rankMedal(mr.Rank)
 
-		wordCount := len(strings.Fields(r.Text))
-		estCost := r.EstimatedCost(p.Name())
-		totalEstCost += estCost
+		wordCount := r.WordCount()
+		totalEstCost += r.EstimatedCost(p.Name())
+		unknownCost = unknownCost || (costUnknown(p.Name()) && !r.Cached)
 
 		judgeStr := "  n/a"
 		if mr.JudgeScore != nil {
-			judgeStr = fmt.Sprintf("%4.1f", mr.JudgeScore.Overall)
+			judgeStr = scoreColor(mr.JudgeScore.Overall, fmt.Sprintf("%4.1f", mr.JudgeScore.Overall))
 		}
-		fmt.Printf("║ %s %s %-22s %s │ %4d words │ %2d cites │ %s │ ~$%.4f ║\n",
-			medal, p.Emoji(), p.DisplayName(), status, wordCount, len(r.Citations), judgeStr, estCost)
+		fmt.Printf("║ %s %s %s %s │ %4d words │ %2d cites │ %s │ %8s ║\n",
+			medal, p.Emoji(), name, status, wordCount, len(r.Citations), judgeStr, costLabel(p.Name(), r))
 	}
 
 	fmt.Println("╠══════════════════════════════════════════════════════════════════════╣")
-	fmt.Printf("║ 💰 TOTAL EST. COST: ~$%.4f                                           ║\n", totalEstCost)
+	total := fmt.Sprintf("~$%.4f", totalEstCost)
+	if unknownCost {
+		total += " + unknown"
+	}
+	fmt.Printf("║ 💰 TOTAL EST. COST: %-51s║\n", total)
+
+	var reprompted []string
+	for _, mr := range results {
+		if mr.Result.RePrompted {
+			reprompted = append(reprompted, mr.Provider.DisplayName())
+		}
+	}
+	if len(reprompted) > 0 {
+		fmt.Printf("║ 🔁 Re-prompted for citations: %-39s ║\n", strings.Join(reprompted, ", "))
+	}
+	for _, mr := range slowResults(results) {
+		note := fmt.Sprintf("%s was slow: %s — consider a shorter timeout", mr.Provider.Name(), mr.Result.Duration.Round(100*time.Millisecond))
+		fmt.Printf("║ ⚠️  %s ║\n", yellow(fmt.Sprintf("%-64s", note)))
+	}
 
 	// Find winner
-	if len(results) > 0 && results[0].Result.Error == nil {
-		winner := results[0].Provider.DisplayName()
-		fmt.Printf("║ 🏆 WINNER: %-58s ║\n", winner)
+	if top := topRanked(results); top != nil && reverseRank {
+		fmt.Printf("║ 🔻 NEEDS IMPROVEMENT: %s ║\n", bold(yellow(fmt.Sprintf("%-47s", top.Provider.DisplayName()))))
+	} else if top != nil {
+		winner := top.Provider.DisplayName()
+		fmt.Printf("║ 🏆 WINNER: %s ║\n", bold(green(fmt.Sprintf("%-58s", winner))))
 	}
 
 	fmt.Println("╠══════════════════════════════════════════════════════════════════════╣")
@@ -161,21 +287,37 @@ func printComparisonSummary(results []ModelResult) {
 	fmt.Println()
 }
 
+// slowResults returns the results that took longer than -warn-slow. Cached
+// answers are skipped: their duration is from the run that stored them.
+func slowResults(results []ModelResult) []ModelResult {
+	if warnSlow <= 0 {
+		return nil
+	}
+	var slow []ModelResult
+	for _, mr := range results {
+		if !mr.Result.Cached && mr.Result.Duration > warnSlow {
+			slow = append(slow, mr)
+		}
+	}
+	return slow
+}
+
 func printCombinedSummary(results []ModelResult, query string) {
 	fmt.Println("╔══════════════════════════════════════════════════════════════════════╗")
 	fmt.Println("║                     COMBINED INTELLIGENCE                            ║")
 	fmt.Println("╚══════════════════════════════════════════════════════════════════════╝")
 	fmt.Println()
 
-	// Collect all unique citations
-	allCitations := make(map[string]Citation)
+	// Collect all unique citations, collapsing the same story under different URLs
+	var collected []Citation
 	for _, mr := range results {
 		for _, c := range mr.Result.Citations {
 			if c.URL != "" {
-				allCitations[c.URL] = c
+				collected = append(collected, c)
 			}
 		}
 	}
+	allCitations := CollapseDuplicateStories(collected)
 
 	// Show which models found what
 	fmt.Println("📊 Coverage Analysis:")
@@ -191,10 +333,27 @@ func printCombinedSummary(results []ModelResult, query string) {
 		keyPoints := extractKeyPoints(mr.Result.Text, 3)
 		fmt.Printf("\n%s %s found:\n", p.Emoji(), p.DisplayName())
 		for _, point := range keyPoints {
-			fmt.Printf("   • %s\n", point)
+			lines := wrapText(point, gutterWidth(5))
+			fmt.Printf("   • %s\n", lines[0])
+			for _, line := range lines[1:] {
+				fmt.Printf("     %s\n", line)
+			}
 		}
 	}
 
+	// Flag answers in a language other than the one requested with -lang
+	if responseLang != "en" {
+		for _, mr := range results {
+			if lang := mr.Result.Language; mr.Result.Error == nil && lang != "" && lang != responseLang {
+				fmt.Printf("\n⚠️  %s\n", yellow(fmt.Sprintf("%s %s answered in %s (expected %s)",
+					mr.Provider.Emoji(), mr.Provider.DisplayName(), languageName(lang), languageName(responseLang))))
+			}
+		}
+	}
+
+	printSourceAgreement(results)
+	printPanelConfidence(results)
+
 	// Show all unique sources
 	if len(allCitations) > 0 {
 		fmt.Println()
@@ -210,7 +369,7 @@ func printCombinedSummary(results []ModelResult, query string) {
 			if title == "" {
 				title = "(no title)"
 			}
-			fmt.Printf("   [%d] %s\n       %s\n", i, title, c.URL)
+			fmt.Printf("   [%d] %s%s\n       %s\n", i, trustLabel(c), title, dim(displayURL(c.URL)))
 			i++
 			if i > 10 {
 				fmt.Printf("   ... and %d more sources\n", len(allCitations)-10)
@@ -222,6 +381,88 @@ func printCombinedSummary(results []ModelResult, query string) {
 	fmt.Println()
 }
 
+// printSynthesis renders the -synthesize answer and the sources it cites.
+func printSynthesis(s *SynthesizedAnswer) {
+	fmt.Println(bold("🧩 Synthesized Answer"))
+	fmt.Println(strings.Repeat("─", 70))
+	for _, para := range strings.Split(strings.TrimSpace(s.Answer), "\n") {
+		if strings.TrimSpace(para) == "" {
+			fmt.Println()
+			continue
+		}
+		for _, line := range wrapText(para, gutterWidth(3)) {
+			fmt.Printf("   %s\n", line)
+		}
+	}
+
+	if len(s.Sources) > 0 {
+		fmt.Println()
+		fmt.Printf("📚 Sources (%d):\n", len(s.Sources))
+		for _, src := range s.Sources {
+			title := src.Citation.Title
+			if title == "" {
+				title = src.Citation.Domain
+			}
+			if title == "" {
+				title = "(no title)"
+			}
+			fmt.Printf("   [%d] %s\n       %s\n", src.Number, title, dim(displayURL(src.Citation.URL)))
+		}
+	}
+	fmt.Println()
+}
+
+// printClaimDiff renders claim clusters as consensus, divergence, and unique claims.
+func printClaimDiff(clusters []ClaimCluster) {
+	var consensus, divergence, unique []ClaimCluster
+	for _, c := range clusters {
+		switch {
+		case len(c.Disagree) > 0:
+			divergence = append(divergence, c)
+		case len(c.Agree) > 1:
+			consensus = append(consensus, c)
+		default:
+			unique = append(unique, c)
+		}
+	}
+
+	fmt.Println("🔀 Consensus vs. Divergence:")
+	fmt.Println(strings.Repeat("─", 70))
+
+	if len(consensus) > 0 {
+		fmt.Println("\n✅ Agreed:")
+		for _, c := range consensus {
+			fmt.Printf("   • %s\n     [%s]\n", c.Claim, strings.Join(c.Agree, ", "))
+		}
+	}
+	if len(divergence) > 0 {
+		fmt.Println("\n⚔️  Contradicted:")
+		for _, c := range divergence {
+			fmt.Printf("   • %s\n     agree: [%s] | disagree: [%s]\n", c.Claim, strings.Join(c.Agree, ", "), strings.Join(c.Disagree, ", "))
+			if c.Note != "" {
+				fmt.Printf("     ↳ %s\n", c.Note)
+			}
+		}
+	}
+	if len(unique) > 0 {
+		fmt.Println("\n🔹 Unique:")
+		for _, c := range unique {
+			fmt.Printf("   • %s\n     [%s]\n", c.Claim, strings.Join(c.Agree, ", "))
+		}
+	}
+	fmt.Println()
+}
+
+// gutterWidth returns the wrap width left after a line prefix of prefixCols
+// columns, or 0 when wrapping is disabled.
+func gutterWidth(prefixCols int) int {
+	w := wrapWidth()
+	if w == 0 {
+		return 0
+	}
+	return max(w-prefixCols, 20)
+}
+
 func extractKeyPoints(text string, maxPoints int) []string {
 	// Remove thinking tags
 	text = stripThinkingTags(text)
@@ -264,8 +505,25 @@ func extractKeyPoints(text string, maxPoints int) []string {
 	return points
 }
 
-func stripThinkingTags(text string) string {
-	re := regexp.MustCompile(`(?s)<thinking>.*?</thinking>\s*`)
-	return strings.TrimSpace(re.ReplaceAllString(text, ""))
+// thinkingTagRegex matches inline reasoning blocks in the tag variants models emit.
+var thinkingTagRegex = regexp.MustCompile(`(?s)(?:<thinking>.*?</thinking>|<think>.*?</think>|<reasoning>.*?</reasoning>)\s*`)
+
+// extractThinkingTags splits inline reasoning blocks out of text, returning the
+// cleaned text and the concatenated reasoning without its tags.
+func extractThinkingTags(text string) (clean, thinking string) {
+	var parts []string
+	for _, m := range thinkingTagRegex.FindAllString(text, -1) {
+		m = strings.TrimSpace(m)
+		start := strings.Index(m, ">")
+		end := strings.LastIndex(m, "</")
+		if start >= 0 && end > start {
+			parts = append(parts, strings.TrimSpace(m[start+1:end]))
+		}
+	}
+	return stripThinkingTags(text), strings.Join(parts, "\n\n")
 }
 
+// stripThinkingTags removes inline reasoning blocks (<thinking>, <think>, <reasoning>) from text.
+func stripThinkingTags(text string) string {
+	return strings.TrimSpace(thinkingTagRegex.ReplaceAllString(text, ""))
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Domain lists from -allow-domains and -block-domains. Entries match the domain
+// itself and any subdomain ("bbc.co.uk" matches "www.bbc.co.uk").
+var (
+	allowDomains []string
+	blockDomains []string
+)
+
+// DomainFilterProvider is implemented by providers whose search tool restricts
+// domains natively. Others get the lists as a prompt instruction. Citations from
+// every provider are post-filtered either way.
+type DomainFilterProvider interface {
+	SupportsDomainFilter() bool
+}
+
+// parseDomainList splits a comma-separated domain list, normalizing each entry.
+func parseDomainList(list string) ([]string, error) {
+	var domains []string
+	for _, d := range strings.Split(list, ",") {
+		d = strings.ToLower(strings.TrimSpace(d))
+		d = strings.TrimPrefix(strings.TrimPrefix(d, "https://"), "http://")
+		d = strings.TrimPrefix(strings.TrimSuffix(d, "/"), "www.")
+		if d == "" {
+			continue
+		}
+		if strings.ContainsAny(d, "/ ") || !strings.Contains(d, ".") {
+			return nil, fmt.Errorf("invalid domain %q", d)
+		}
+		domains = append(domains, d)
+	}
+	return domains, nil
+}
+
+// domainMatches reports whether domain is one of list or a subdomain of one.
+func domainMatches(domain string, list []string) bool {
+	for _, d := range list {
+		if domain == d || strings.HasSuffix(domain, "."+d) {
+			return true
+		}
+	}
+	return false
+}
+
+// citationAllowed reports whether c passes the allow and block lists.
+// Citations with no recognizable domain are kept.
+func citationAllowed(c Citation) bool {
+	domain := c.Domain
+	if domain == "" {
+		domain = domainFromURL(c.URL)
+	}
+	if domain == "" {
+		return true
+	}
+	if domainMatches(domain, blockDomains) {
+		return false
+	}
+	return len(allowDomains) == 0 || domainMatches(domain, allowDomains)
+}
+
+// nativeAllowedDomains returns the allow list minus blocked entries, for search
+// tools that accept only one of the two lists at a time.
+func nativeAllowedDomains() []string {
+	var out []string
+	for _, d := range allowDomains {
+		if !domainMatches(d, blockDomains) {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// applyDomainConstraints appends the domain lists to the query for providers
+// without a native domain filter.
+func applyDomainConstraints(p Provider, query string) string {
+	if len(allowDomains) == 0 && len(blockDomains) == 0 {
+		return query
+	}
+	if fp, ok := p.(DomainFilterProvider); ok && fp.SupportsDomainFilter() {
+		return query
+	}
+	if len(allowDomains) > 0 {
+		query += "\n\nOnly use sources from these domains: " + strings.Join(allowDomains, ", ") + "."
+	}
+	if len(blockDomains) > 0 {
+		query += "\n\nDo not use sources from these domains: " + strings.Join(blockDomains, ", ") + "."
+	}
+	return query
+}
+
+// filterCitations drops citations outside the allow list or on the block list,
+// recording how many were dropped so the display can flag answers that relied on them.
+func filterCitations(r *Result) {
+	if len(allowDomains) == 0 && len(blockDomains) == 0 {
+		return
+	}
+	kept := r.Citations[:0]
+	for _, c := range r.Citations {
+		if citationAllowed(c) {
+			kept = append(kept, c)
+		} else {
+			r.FilteredCitations++
+		}
+	}
+	r.Citations = kept
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// dryRun makes providers print the request they would send instead of calling the API.
+var dryRun bool
+
+// errDryRun marks results produced under -dry-run.
+var errDryRun = errors.New("dry-run: request not sent")
+
+// dryRunMu keeps concurrent providers' request dumps from interleaving.
+var dryRunMu sync.Mutex
+
+// dryRunResult prints req as indented JSON and returns a synthetic result.
+func dryRunResult(p Provider, req any) Result {
+	data, err := json.MarshalIndent(req, "", "  ")
+	if err != nil {
+		return Result{Error: fmt.Errorf("dry-run: marshal request: %w", err)}
+	}
+
+	dryRunMu.Lock()
+	defer dryRunMu.Unlock()
+	fmt.Printf("┌─ %s %s request (dry-run)\n%s\n└%s\n\n", p.Emoji(), p.DisplayName(), data, strings.Repeat("─", 60))
+	return Result{Error: errDryRun}
+}
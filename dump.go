@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// dumpDir is set by -dump-dir: each provider's raw request and response are
+// written there as <provider>-request.json and <provider>-response.json, so
+// parsing bugs can be diagnosed from the exact payloads. Later queries
+// overwrite earlier ones.
+var dumpDir string
+
+// dumpBytes writes data to dumpDir/<name>.json when -dump-dir is set.
+// Failures are logged rather than failing the query.
+func dumpBytes(name string, data []byte) {
+	if dumpDir == "" {
+		return
+	}
+	// Model variants are named "provider:label"; keep file names portable.
+	path := filepath.Join(dumpDir, strings.ReplaceAll(name, ":", "_")+".json")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		slog.Warn("dump failed", "file", path, "error", err)
+		return
+	}
+	slog.Debug("dumped payload", "file", path, "bytes", len(data))
+}
+
+// dumpJSON writes v as indented JSON to dumpDir/<name>.json, for SDK-based
+// providers whose raw bytes aren't exposed.
+func dumpJSON(name string, v any) {
+	if dumpDir == "" {
+		return
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		slog.Warn("dump failed", "file", name, "error", fmt.Errorf("marshal: %w", err))
+		return
+	}
+	dumpBytes(name, data)
+}
+
+// includeRaw is set by -include-raw: each provider keeps its raw response on
+// Result.Raw, which -format json and -jsonl-out emit under "raw".
+var includeRaw bool
+
+// keepRawResponses reports whether providers should keep raw responses: for
+// -include-raw, or for the -snapshot-query-results bundle.
+func keepRawResponses() bool {
+	return includeRaw || snapshotPath != ""
+}
+
+// keepRaw stores a provider's raw response body on r when raw responses are
+// kept. A body that isn't JSON is kept as a JSON string.
+func keepRaw(r *Result, data []byte) {
+	if !keepRawResponses() {
+		return
+	}
+	if json.Valid(data) {
+		r.Raw = json.RawMessage(data)
+		return
+	}
+	r.Raw, _ = json.Marshal(string(data))
+}
+
+// keepRawJSON stores v as JSON on r when raw responses are kept, for SDK-based
+// providers whose raw bytes aren't exposed.
+func keepRawJSON(r *Result, v any) {
+	if !keepRawResponses() {
+		return
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		slog.Warn("raw response not kept", "error", fmt.Errorf("marshal: %w", err))
+		return
+	}
+	r.Raw = data
+}
+
+// prepareDumpDir creates -dump-dir if it doesn't exist.
+func prepareDumpDir() error {
+	if dumpDir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(dumpDir, 0o755); err != nil {
+		return fmt.Errorf("create dump dir: %w", err)
+	}
+	return nil
+}
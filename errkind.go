@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+	"google.golang.org/genai"
+)
+
+// ErrorKind categorizes a failed Result so callers can tell a bad key from a
+// rate limit or a flaky network without parsing messages.
+type ErrorKind string
+
+const (
+	ErrorKindNone      ErrorKind = ""
+	ErrorKindAuth      ErrorKind = "auth"
+	ErrorKindRateLimit ErrorKind = "rate_limit"
+	ErrorKindTimeout   ErrorKind = "timeout"
+	ErrorKindNetwork   ErrorKind = "network"
+	ErrorKindParse     ErrorKind = "parse"
+	ErrorKindEmpty     ErrorKind = "empty"
+	ErrorKindOther     ErrorKind = "other"
+)
+
+// Icon returns the emoji shown next to an error of this kind.
+func (k ErrorKind) Icon() string {
+	switch k {
+	case ErrorKindAuth:
+		return "🔑"
+	case ErrorKindRateLimit:
+		return "🚦"
+	case ErrorKindTimeout:
+		return "⌛"
+	case ErrorKindNetwork:
+		return "🔌"
+	case ErrorKindParse:
+		return "🧩"
+	case ErrorKindEmpty:
+		return "📭"
+	}
+	return "❌"
+}
+
+// errParse is wrapped by provider errors for responses that couldn't be decoded.
+var errParse = errors.New("parse error")
+
+// statusError is a non-2xx response from a provider's HTTP API.
+type statusError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("API error (status %d): %s", e.StatusCode, e.Message)
+}
+
+// httpStatusCoder matches the AWS SDK's response errors.
+type httpStatusCoder interface {
+	HTTPStatusCode() int
+}
+
+// classifyError maps a provider error to its ErrorKind. Providers report
+// HTTP failures as *statusError (or their SDK's error type) and decode
+// failures wrapped in errParse; everything else falls back on the standard
+// library's context and network errors.
+func classifyError(err error) ErrorKind {
+	if err == nil {
+		return ErrorKindNone
+	}
+	switch {
+	case errors.Is(err, errEmptyResponse):
+		return ErrorKindEmpty
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrorKindTimeout
+	case errors.Is(err, errParse):
+		return ErrorKindParse
+	}
+
+	var throttled *types.ThrottlingException
+	var denied *types.AccessDeniedException
+	switch {
+	case errors.As(err, &throttled):
+		return ErrorKindRateLimit
+	case errors.As(err, &denied):
+		return ErrorKindAuth
+	}
+
+	if kind := statusKind(errorStatusCode(err)); kind != ErrorKindNone {
+		return kind
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		if netErr.Timeout() {
+			return ErrorKindTimeout
+		}
+		return ErrorKindNetwork
+	}
+	return ErrorKindOther
+}
+
+// errorStatusCode returns the HTTP status carried by err, or 0.
+func errorStatusCode(err error) int {
+	var se *statusError
+	var anthropicErr *anthropic.Error
+	var genaiErr genai.APIError
+	var awsErr httpStatusCoder
+	switch {
+	case errors.As(err, &se):
+		return se.StatusCode
+	case errors.As(err, &anthropicErr):
+		return anthropicErr.StatusCode
+	case errors.As(err, &genaiErr):
+		return genaiErr.Code
+	case errors.As(err, &awsErr):
+		return awsErr.HTTPStatusCode()
+	}
+	return 0
+}
+
+// statusKind maps an HTTP status to an ErrorKind, or ErrorKindNone for
+// statuses that say nothing specific (including 0).
+func statusKind(code int) ErrorKind {
+	switch code {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ErrorKindAuth
+	case http.StatusTooManyRequests, 529: // 529: Anthropic overloaded
+		return ErrorKindRateLimit
+	case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+		return ErrorKindTimeout
+	}
+	return ErrorKindNone
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// TokenCounter is implemented by providers whose API can count prompt tokens
+// with the model's own tokenizer, without generating anything.
+type TokenCounter interface {
+	CountTokens(ctx context.Context, query string) (int, error)
+}
+
+// charsPerToken approximates BPE tokenizers on English text.
+const charsPerToken = 4
+
+// estimateTokens approximates the token count of text from its length.
+func estimateTokens(text string) int {
+	return (len([]rune(text)) + charsPerToken - 1) / charsPerToken
+}
+
+// costEstimate is one row of the -estimate table.
+type costEstimate struct {
+	Provider Provider
+	Input    int  // Query tokens
+	Counted  bool // Input came from the provider's tokenizer rather than estimateTokens
+	Output   int  // Max output tokens assumed (MaxTokenEstimate)
+	Cost     float64
+	Worst    float64 // WorstCaseCost, which also budgets for search-result context
+	Note     string  // Why a tokenizer-capable provider was estimated
+}
+
+// runEstimate prints each provider's projected cost for query without calling
+// any model. Query tokens are counted with the provider's tokenizer when it has
+// one and credentials are set, otherwise estimated from length.
+func runEstimate(ctx context.Context, names []string, query string) {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	estimates := make([]costEstimate, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		p, _ := Get(name)
+		wg.Add(1)
+		go func(i int, p Provider) {
+			defer wg.Done()
+			estimates[i] = estimateCost(ctx, p, prepareQuery(p, query))
+		}(i, p)
+	}
+	wg.Wait()
+
+	printEstimateTable(estimates)
+}
+
+func estimateCost(ctx context.Context, p Provider, query string) costEstimate {
+	e := costEstimate{Provider: p, Input: estimateTokens(query), Output: MaxTokenEstimate[p.Name()].Output}
+
+	if tc, ok := p.(TokenCounter); ok {
+		if err := p.CheckAuth(); err != nil {
+			e.Note = "no key"
+		} else if n, err := tc.CountTokens(ctx, query); err != nil {
+			slog.Debug("token count failed, estimating", "provider", p.Name(), "error", err)
+			e.Note = "count error"
+		} else {
+			e.Input, e.Counted = n, true
+		}
+	}
+
+	r := Result{Tokens: TokenUsage{Input: e.Input, Output: e.Output}}
+	e.Cost = r.EstimatedCost(p.Name())
+	e.Worst = WorstCaseCost(p.Name())
+	return e
+}
+
+func printEstimateTable(estimates []costEstimate) {
+	fmt.Println("╔══════════════════════════════════════════════════════════════════════════════════════╗")
+	fmt.Println("║                                  COST ESTIMATE                                       ║")
+	fmt.Println("╠══════════════════════════════════════════════════════════════════════════════════════╣")
+	fmt.Printf("║ %-27s %12s %8s %10s %10s  %-11s ║\n", "Model", "Query tok", "Max out", "Est. cost", "Worst", "")
+	fmt.Println("╠══════════════════════════════════════════════════════════════════════════════════════╣")
+
+	var total, worst float64
+	unknown := false
+	for _, e := range estimates {
+		name := fmt.Sprintf("%s %s", e.Provider.Emoji(), e.Provider.DisplayName())
+		tokens := fmt.Sprintf("~%d", e.Input)
+		if e.Counted {
+			tokens = fmt.Sprintf("%d", e.Input)
+		}
+		cost, worstCost := fmt.Sprintf("$%.4f", e.Cost), fmt.Sprintf("$%.4f", e.Worst)
+		note := e.Note
+		if costUnknown(e.Provider.Name()) {
+			cost, worstCost, note = "unknown", "unknown", "no price"
+			unknown = true
+		}
+		fmt.Printf("║ %-27s %12s %8d %10s %10s  %-11s ║\n", name, tokens, e.Output, cost, worstCost, note)
+		total += e.Cost
+		worst += e.Worst
+	}
+
+	totalCost, worstCost := fmt.Sprintf("$%.4f", total), fmt.Sprintf("$%.4f", worst)
+	if unknown {
+		totalCost, worstCost = totalCost+"+?", worstCost+"+?"
+	}
+	fmt.Println("╠══════════════════════════════════════════════════════════════════════════════════════╣")
+	fmt.Printf("║ %-27s %12s %8s %10s %10s  %-11s ║\n", "TOTAL", "", "", totalCost, worstCost, "")
+	fmt.Println("╠══════════════════════════════════════════════════════════════════════════════════════╣")
+	fmt.Println("║ Est. cost: query tokens + max output + search fee. ~ marks length-based estimates.   ║")
+	fmt.Println("║ Worst: also budgets for search results added to the context (as used by -budget).    ║")
+	fmt.Println("╚══════════════════════════════════════════════════════════════════════════════════════╝")
+	fmt.Println()
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Exit codes under -fail-on-error. Bad flags and other setup failures exit 1.
+const (
+	exitProviderError = 2 // At least one selected provider returned an error
+	exitNoProviders   = 3 // Every selected provider was skipped (missing credentials, unsupported options)
+)
+
+// failOnError enables the -fail-on-error exit codes.
+var failOnError bool
+
+// failIgnoreKinds, from -fail-ignore, are error kinds that don't trip
+// -fail-on-error (e.g. auth, when a CI job lacks some keys).
+var failIgnoreKinds map[ErrorKind]bool
+
+// errorKinds are the ErrorKind values -fail-ignore accepts.
+var errorKinds = []ErrorKind{ErrorKindAuth, ErrorKindRateLimit, ErrorKindTimeout, ErrorKindNetwork, ErrorKindParse, ErrorKindEmpty, ErrorKindOther}
+
+// parseErrorKinds parses a comma-separated -fail-ignore list.
+func parseErrorKinds(s string) (map[ErrorKind]bool, error) {
+	kinds := make(map[ErrorKind]bool)
+	for _, part := range strings.Split(s, ",") {
+		k := ErrorKind(strings.TrimSpace(part))
+		if k == ErrorKindNone {
+			continue
+		}
+		valid := false
+		for _, known := range errorKinds {
+			valid = valid || k == known
+		}
+		if !valid {
+			names := make([]string, len(errorKinds))
+			for i, known := range errorKinds {
+				names[i] = string(known)
+			}
+			return nil, fmt.Errorf("unknown error kind %q (use %s)", k, strings.Join(names, ", "))
+		}
+		kinds[k] = true
+	}
+	return kinds, nil
+}
+
+// exitError stops a query before any provider is sent it. The one-shot CLI
+// exits with Code; the REPL prints it and prompts again.
+type exitError struct {
+	Code int
+	Err  error
+}
+
+func (e *exitError) Error() string { return e.Err.Error() }
+func (e *exitError) Unwrap() error { return e.Err }
+
+// exitOnError prints err to stderr and exits with its exit code (1 unless it
+// is an *exitError). It does nothing if err is nil.
+func exitOnError(err error) {
+	if err == nil {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+	code := 1
+	var ee *exitError
+	if errors.As(err, &ee) {
+		code = ee.Code
+	}
+	os.Exit(code)
+}
+
+// noProvidersExitCode is the exit code when every provider was skipped.
+func noProvidersExitCode() int {
+	if failOnError {
+		return exitNoProviders
+	}
+	return 1
+}
+
+// exitOnProviderErrors exits with exitProviderError under -fail-on-error if any
+// result failed, including interrupted ones. Dry-run results and kinds listed
+// in -fail-ignore don't count.
+func exitOnProviderErrors(results []ModelResult) {
+	if !failOnError {
+		return
+	}
+	for _, mr := range results {
+		if err := mr.Result.Error; err != nil && !errors.Is(err, errDryRun) && !failIgnoreKinds[mr.Result.ErrorKind] {
+			os.Exit(exitProviderError)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// explainScores enables the -explain-scores breakdown after the ranking.
+var explainScores bool
+
+// printScoreBreakdown shows, for each judged result, how every dimension
+// contributes to Overall under the active weights, the judge's full reasoning,
+// and which cited links failed validation. Link checks come from the shared
+// cache, so nothing is re-requested after judging.
+func printScoreBreakdown(ctx context.Context, results []ModelResult) {
+	checks := checkAllCitations(ctx, results)
+
+	fmt.Println()
+	fmt.Println(bold("🔎 Score Breakdown"))
+	fmt.Println(strings.Repeat("─", 70))
+
+	for i, mr := range results {
+		p := mr.Provider
+		js := mr.JudgeScore
+		if mr.Result.Error != nil {
+			continue
+		}
+		if js == nil {
+			fmt.Printf("\n#%d %s %s: not judged\n", i+1, p.Emoji(), p.DisplayName())
+			continue
+		}
+
+		fmt.Printf("\n#%d %s %s: %s\n", i+1, p.Emoji(), p.DisplayName(),
+			scoreColor(js.Overall, fmt.Sprintf("%.2f/10", js.Overall)))
+
+		if js.Quality == 0 {
+			// applyJudgeScores fallback: no evaluation came back for this model.
+			fmt.Printf("   Overall is link health only (%d); the judge returned no evaluation.\n", js.LinkHealth)
+		} else {
+			w := judgeWeights
+			rows := []struct {
+				name   string
+				score  int
+				weight float64
+			}{
+				{"Quality", js.Quality, w.Quality},
+				{"Links", js.LinkHealth, w.LinkHealth},
+				{"Diversity", js.Diversity, w.Diversity},
+				{"Recency", js.Recency, w.Recency},
+				{"Significance", js.Significance, w.Significance},
+				{"Impact", js.Impact, w.Impact},
+			}
+			for _, row := range rows {
+				fmt.Printf("   %-13s %2d × %.2f = %5.2f\n", row.name, row.score, row.weight, float64(row.score)*row.weight)
+			}
+			fmt.Printf("   %-13s %17s\n", "Overall", fmt.Sprintf("%5.2f", js.Overall))
+		}
+
+		if js.Reasoning != "" {
+			fmt.Println("   💬 Judge:")
+			for _, line := range wrapText(js.Reasoning, gutterWidth(6)) {
+				fmt.Printf("      %s\n", dim(line))
+			}
+		}
+
+		printLinkDetail(checks[p.Name()])
+	}
+	fmt.Println()
+}
+
+// printLinkDetail summarizes link checks and lists each failed URL with its cause.
+func printLinkDetail(checks []CitationCheck) {
+	if len(checks) == 0 {
+		fmt.Println("   🔗 No citations to check (link health scored neutral)")
+		return
+	}
+	healthy := 0
+	for _, c := range checks {
+		if c.Healthy {
+			healthy++
+		}
+	}
+	fmt.Printf("   🔗 %d/%d links healthy\n", healthy, len(checks))
+	for _, c := range checks {
+		if c.Healthy {
+			continue
+		}
+		cause := c.Error
+		if c.StatusCode != 0 {
+			cause = fmt.Sprintf("status %d", c.StatusCode)
+		}
+		fmt.Printf("      %s %s %s\n", red("✗"), displayURL(c.URL), dim("("+cause+")"))
+	}
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// fastest is set by -fastest: race every provider and keep the first good
+// answer, canceling the rest. The judge is skipped.
+var fastest bool
+
+// errLostRace marks a provider canceled because another answered first.
+var errLostRace = errors.New("canceled: another provider answered first (-fastest)")
+
+// runFastest queries every runnable provider in parallel and returns the first
+// successful, non-empty answer, ranked #1. The shared context is canceled as
+// soon as it arrives, which aborts the other providers' in-flight HTTP
+// requests; their goroutines are waited on (for up to interruptGrace) before
+// returning. If no provider succeeds, the failures are returned; if none can
+// run, the *exitError from runnableProviders.
+func runFastest(ctx context.Context, names []string, query string) ([]ModelResult, error) {
+	available, err := runnableProviders(names)
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Printf("🏁 Racing %d models for the fastest answer...\n", len(available))
+	fmt.Println(strings.Repeat("═", 65))
+	fmt.Println()
+
+	sigCtx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	raceCtx, cancel := context.WithCancel(sigCtx)
+	defer cancel()
+
+	// Buffered so losers finishing after the winner never block.
+	results := make(chan ModelResult, len(available))
+	progress := newProgressBoard(available)
+	var wg sync.WaitGroup
+	for _, p := range available {
+		wg.Add(1)
+		go func(provider Provider) {
+			defer wg.Done()
+			progress.Start(provider)
+			r := queryProvider(raceCtx, provider, query)
+			if r.Error != nil && raceCtx.Err() != nil && sigCtx.Err() == nil {
+				r.Error = errLostRace
+				r.ErrorKind = ErrorKindOther
+			}
+			logProviderResult(provider, r)
+			progress.Finish(provider, r)
+			results <- ModelResult{Provider: provider, Result: r}
+		}(p)
+	}
+
+	var winner *ModelResult
+	var failed []ModelResult
+	interrupted := false
+race:
+	for len(failed) < len(available) {
+		select {
+		case mr := <-results:
+			if mr.Result.Error == nil && strings.TrimSpace(mr.Result.Text) != "" {
+				winner = &mr
+				break race
+			}
+			failed = append(failed, mr)
+		case <-sigCtx.Done():
+			interrupted = true
+			break race
+		}
+	}
+	cancel()
+	progress.Stop()
+	awaitLosers(&wg)
+
+	if winner == nil {
+		for _, mr := range failed {
+			printModelResultWithRank(mr, 0)
+			fmt.Println()
+		}
+		switch {
+		case interrupted:
+			fmt.Println("⏹️  Interrupted before any provider answered")
+		case !dryRun:
+			fmt.Println("❌ No provider returned an answer")
+		}
+		return failed, nil
+	}
+
+	winner.Rank = 1
+	printModelResultWithRank(*winner, winner.Rank)
+	fmt.Println()
+	fmt.Printf("⚡ Fastest: %s %s in %v (~$%.4f)", winner.Provider.Emoji(), winner.Provider.DisplayName(),
+		winner.Result.Duration.Round(time.Millisecond), winner.Result.EstimatedCost(winner.Provider.Name()))
+	if canceled := len(available) - len(failed) - 1; canceled > 0 {
+		fmt.Printf(" — canceled %d slower %s", canceled, plural(canceled, "provider", "providers"))
+	}
+	fmt.Println()
+	fmt.Println()
+
+	modelResults := []ModelResult{*winner}
+	saveHTMLReport(query, modelResults)
+	saveArchive(ctx, query, modelResults)
+	saveSnapshot(query, modelResults)
+	return modelResults, nil
+}
+
+// awaitLosers waits for the canceled providers' goroutines to return, so none
+// outlive the race. Providers honor context cancellation, so this is normally
+// immediate; one that doesn't is logged rather than waited on forever.
+func awaitLosers(wg *sync.WaitGroup) {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		slog.Debug("all racing providers returned")
+	case <-time.After(interruptGrace):
+		slog.Warn("providers still running after -fastest cancel", "grace", interruptGrace)
+	}
+}
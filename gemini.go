@@ -2,9 +2,13 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"google.golang.org/genai"
@@ -12,59 +16,168 @@ import (
 
 const geminiModelID = "gemini-3-pro-preview"
 
+// geminiThinkingLevel maps -reasoning levels to Gemini thinking levels.
+var geminiThinkingLevel = map[string]genai.ThinkingLevel{
+	ReasoningLow:    genai.ThinkingLevelLow,
+	ReasoningMedium: genai.ThinkingLevelMedium,
+	ReasoningHigh:   genai.ThinkingLevelHigh,
+}
+
+// allowUngroundedFallback retries once without Google Search when grounding
+// itself fails (-allow-ungrounded-fallback).
+var allowUngroundedFallback bool
+
 func init() {
 	Register(&GeminiProvider{})
 }
 
 // GeminiProvider implements Provider for Gemini via Google AI API.
-type GeminiProvider struct{}
+// The API client is created on first use and reused across queries.
+type GeminiProvider struct {
+	clientOnce sync.Once
+	client     *genai.Client
+	clientErr  error
+	variant    modelVariant
+}
+
+func (p *GeminiProvider) Name() string { return p.variant.name("gemini") }
+func (p *GeminiProvider) DisplayName() string {
+	return p.variant.displayName(p.Name(), "Gemini 3 Pro")
+}
+func (p *GeminiProvider) Emoji() string   { return "🔵" }
+func (p *GeminiProvider) ModelID() string { return p.variant.modelOr(geminiModelID) }
 
-func (p *GeminiProvider) Name() string        { return "gemini" }
-func (p *GeminiProvider) DisplayName() string { return "Gemini 3 Pro" }
-func (p *GeminiProvider) Emoji() string       { return "🔵" }
+// WithModel returns an instance for one of the other Gemini models, e.g. Flash for a cheaper baseline.
+func (p *GeminiProvider) WithModel(label, model string) (Provider, error) {
+	return &GeminiProvider{variant: modelVariant{label: label, model: model}}, nil
+}
+
+// SupportsRecencyFilter is true: Google Search grounding takes a time range on the Gemini API.
+func (p *GeminiProvider) SupportsRecencyFilter() bool { return true }
+
+// SupportsAnswerSchema is true: Gemini 3 accepts a response schema alongside grounding.
+func (p *GeminiProvider) SupportsAnswerSchema() bool { return true }
+
+// Capabilities: -reasoning maps to a thinking level.
+func (p *GeminiProvider) Capabilities() Capabilities {
+	return Capabilities{SystemPrompt: true, Reasoning: true, Vision: true}
+}
 
 func (p *GeminiProvider) CheckAuth() error {
 	if os.Getenv("GOOGLE_API_KEY") == "" && os.Getenv("GEMINI_API_KEY") == "" {
-		return fmt.Errorf("GOOGLE_API_KEY not set")
+		return &AuthError{Reason: "GOOGLE_API_KEY not set", Hint: "export GOOGLE_API_KEY=... or GEMINI_API_KEY (aistudio.google.com/apikey)"}
 	}
 	return nil
 }
 
-func (p *GeminiProvider) Query(ctx context.Context, query string, verbose bool) Result {
+// apiClient returns the shared client, creating it on first use.
+func (p *GeminiProvider) apiClient(ctx context.Context) (*genai.Client, error) {
+	p.clientOnce.Do(func() {
+		apiKey := os.Getenv("GOOGLE_API_KEY")
+		if apiKey == "" {
+			apiKey = os.Getenv("GEMINI_API_KEY")
+		}
+		p.client, p.clientErr = genai.NewClient(ctx, &genai.ClientConfig{
+			APIKey:  apiKey,
+			Backend: genai.BackendGeminiAPI,
+		})
+	})
+	if p.clientErr != nil {
+		return nil, fmt.Errorf("client error: %w", p.clientErr)
+	}
+	return p.client, nil
+}
+
+// CountTokens counts the query's prompt tokens with the Gemini tokenizer.
+func (p *GeminiProvider) CountTokens(ctx context.Context, query string) (int, error) {
+	client, err := p.apiClient(ctx)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := client.Models.CountTokens(ctx, p.ModelID(), geminiContents([]Message{{Role: RoleUser, Text: query}}), nil)
+	if err != nil {
+		return 0, fmt.Errorf("count tokens error: %w", err)
+	}
+	return int(resp.TotalTokens), nil
+}
+
+func (p *GeminiProvider) Query(ctx context.Context, query string, v Verbosity) Result {
+	return p.QueryConversation(ctx, []Message{{Role: RoleUser, Text: query}}, v)
+}
+
+func (p *GeminiProvider) QueryConversation(ctx context.Context, history []Message, v Verbosity) Result {
 	start := time.Now()
 	result := Result{}
 
-	apiKey := os.Getenv("GOOGLE_API_KEY")
-	if apiKey == "" {
-		apiKey = os.Getenv("GEMINI_API_KEY")
+	contents := geminiContents(history)
+	search := &genai.GoogleSearch{}
+	if !sinceTime.IsZero() {
+		search.TimeRangeFilter = &genai.Interval{StartTime: sinceTime, EndTime: time.Now()}
 	}
-
-	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  apiKey,
-		Backend: genai.BackendGeminiAPI,
-	})
-	if err != nil {
-		result.Error = fmt.Errorf("client error: %w", err)
-		return result
+	config := &genai.GenerateContentConfig{
+		Tools: []*genai.Tool{
+			{GoogleSearch: search},
+		},
+	}
+	if systemPrompt != "" {
+		config.SystemInstruction = &genai.Content{Parts: []*genai.Part{{Text: systemPrompt}}}
+	}
+	if responseLang != "en" {
+		config.ToolConfig = &genai.ToolConfig{
+			RetrievalConfig: &genai.RetrievalConfig{LanguageCode: responseLang},
+		}
+	}
+	if answerSchema != nil {
+		config.ResponseMIMEType = "application/json"
+		config.ResponseJsonSchema = answerSchema
+	}
+	if level, ok := geminiThinkingLevel[reasoning]; ok || showThinking {
+		config.ThinkingConfig = &genai.ThinkingConfig{
+			IncludeThoughts: showThinking,
+			ThinkingLevel:   level,
+		}
 	}
 
-	if verbose {
-		fmt.Printf("  [Gemini] Sending request with Google Search grounding...\n")
+	if dryRun {
+		return dryRunResult(p, map[string]any{
+			"model":    p.ModelID(),
+			"contents": contents,
+			"config":   config,
+		})
 	}
 
-	googleSearchTool := &genai.Tool{
-		GoogleSearch: &genai.GoogleSearch{},
+	client, err := p.apiClient(ctx)
+	if err != nil {
+		result.Error = err
+		return result
 	}
 
-	resp, err := client.Models.GenerateContent(ctx, geminiModelID, genai.Text(query), &genai.GenerateContentConfig{
-		Tools: []*genai.Tool{googleSearchTool},
+	slog.Debug("sending request", "provider", p.Name(), "tool", "google_search")
+	dumpJSON(p.Name()+"-request", map[string]any{
+		"model":    p.ModelID(),
+		"contents": contents,
+		"config":   config,
 	})
+
+	resp, err := client.Models.GenerateContent(ctx, p.ModelID(), contents, config)
+
+	// A grounding quota or availability error doesn't stop the model itself
+	// from answering; retry once without the search tool if allowed.
+	if err != nil && allowUngroundedFallback && geminiGroundingError(err) {
+		slog.Warn("grounding failed, retrying without Google Search", "provider", p.Name(), "error", err)
+		config.Tools = nil
+		config.ToolConfig = nil
+		resp, err = client.Models.GenerateContent(ctx, p.ModelID(), contents, config)
+		result.Ungrounded = err == nil
+	}
 	result.Duration = time.Since(start)
 
 	if err != nil {
 		result.Error = fmt.Errorf("API error: %w", err)
 		return result
 	}
+	dumpJSON(p.Name()+"-response", resp)
+	keepRawJSON(&result, resp)
 
 	// Extract token usage
 	if resp.UsageMetadata != nil {
@@ -73,36 +186,188 @@ func (p *GeminiProvider) Query(ctx context.Context, query string, verbose bool)
 	}
 
 	parseGeminiResponse(resp, &result)
+	if len(resp.Candidates) > 0 && resp.Candidates[0].GroundingMetadata != nil {
+		for _, q := range resp.Candidates[0].GroundingMetadata.WebSearchQueries {
+			logSearchQuery(v, p.Name(), q)
+			addSearchStep(&result, q, 0)
+		}
+	}
 	return result
 }
 
+// geminiGroundingError reports whether err is a failure of the Google Search
+// tool (e.g. the grounded-prompt quota) rather than of the model.
+func geminiGroundingError(err error) bool {
+	var apiErr genai.APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	switch apiErr.Code {
+	case 400, 403, 429:
+	default:
+		return false
+	}
+	msg := strings.ToLower(apiErr.Message)
+	for _, marker := range []string{"grounding", "grounded", "google_search", "google search", "search tool"} {
+		if strings.Contains(msg, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// geminiContents maps conversation history to Gemini contents.
+func geminiContents(history []Message) []*genai.Content {
+	contents := make([]*genai.Content, 0, len(history))
+	for _, m := range history {
+		role := genai.RoleUser
+		if m.Role == RoleAssistant {
+			role = genai.RoleModel
+		}
+		parts := make([]*genai.Part, 0, len(m.Images)+1)
+		for _, img := range m.Images {
+			parts = append(parts, genai.NewPartFromBytes(img.Data, img.MediaType))
+		}
+		parts = append(parts, genai.NewPartFromText(m.Text))
+		contents = append(contents, genai.NewContentFromParts(parts, genai.Role(role)))
+	}
+	return contents
+}
+
 func parseGeminiResponse(resp *genai.GenerateContentResponse, result *Result) {
-	if resp == nil || len(resp.Candidates) == 0 {
+	if resp == nil {
+		result.Error = fmt.Errorf("empty response")
+		return
+	}
+
+	if resp.PromptFeedback != nil && resp.PromptFeedback.BlockReason != "" {
+		result.Error = fmt.Errorf("prompt blocked: %s", resp.PromptFeedback.BlockReason)
+		if resp.PromptFeedback.BlockReasonMessage != "" {
+			result.Error = fmt.Errorf("prompt blocked: %s (%s)", resp.PromptFeedback.BlockReason, resp.PromptFeedback.BlockReasonMessage)
+		}
+		return
+	}
+
+	if len(resp.Candidates) == 0 {
+		result.Error = fmt.Errorf("no candidates returned")
 		return
 	}
 
 	candidate := resp.Candidates[0]
-	if candidate.Content == nil {
+	result.FinishReason = string(candidate.FinishReason)
+
+	// MAX_TOKENS still carries a (truncated) answer; any other non-STOP reason means
+	// the response was withheld, e.g. SAFETY or RECITATION.
+	switch candidate.FinishReason {
+	case "", genai.FinishReasonStop, genai.FinishReasonMaxTokens:
+	default:
+		result.Error = fmt.Errorf("response blocked: finish reason %s", candidate.FinishReason)
+		if candidate.FinishMessage != "" {
+			result.Error = fmt.Errorf("response blocked: finish reason %s (%s)", candidate.FinishReason, candidate.FinishMessage)
+		}
+		return
+	}
+
+	if candidate.Content == nil || len(candidate.Content.Parts) == 0 {
+		result.Error = fmt.Errorf("no content returned (finish reason %s)", candidate.FinishReason)
 		return
 	}
 
+	// partOffset maps each answer part to where it starts in result.Text, so
+	// grounding segments (offsets within a part) can be located in the whole answer.
 	var textBuilder strings.Builder
-	for _, part := range candidate.Content.Parts {
-		if part.Text != "" {
+	partOffset := make(map[int]int)
+	for i, part := range candidate.Content.Parts {
+		if part == nil || part.Text == "" {
+			continue
+		}
+		if part.Thought {
+			AppendThinking(result, part.Text)
+		} else {
+			partOffset[i] = textBuilder.Len()
 			textBuilder.WriteString(part.Text)
 		}
 	}
 	result.Text = textBuilder.String()
 
 	if candidate.GroundingMetadata != nil {
+		supports := geminiFirstSupports(candidate.GroundingMetadata.GroundingSupports)
+		refs := geminiReferenceOrder(supports)
 		seen := make(map[string]bool)
-		for _, chunk := range candidate.GroundingMetadata.GroundingChunks {
-			if chunk.Web != nil {
-				DeduplicateCitations(&result.Citations, seen, Citation{
-					URL:   chunk.Web.URI,
-					Title: chunk.Web.Title,
-				})
+		for i, chunk := range candidate.GroundingMetadata.GroundingChunks {
+			if chunk.Web == nil {
+				continue
+			}
+			c := Citation{
+				URL:    chunk.Web.URI,
+				Title:  chunk.Web.Title,
+				Domain: geminiChunkDomain(chunk.Web),
+				Index:  refs[i],
+			}
+			if seg := supports[i]; seg != nil {
+				c.Snippet = seg.Text
+				if offset, ok := partOffset[int(seg.PartIndex)]; ok {
+					c.StartIndex = offset + int(seg.StartIndex)
+					c.EndIndex = offset + int(seg.EndIndex)
+				}
 			}
+			MergeCitation(&result.Citations, seen, c)
 		}
 	}
 }
+
+// geminiFirstSupports maps each grounding chunk index to the first answer segment
+// it supports.
+func geminiFirstSupports(supports []*genai.GroundingSupport) map[int]*genai.Segment {
+	first := make(map[int]*genai.Segment)
+	for _, s := range supports {
+		if s == nil || s.Segment == nil {
+			continue
+		}
+		for _, idx := range s.GroundingChunkIndices {
+			if _, ok := first[int(idx)]; !ok {
+				first[int(idx)] = s.Segment
+			}
+		}
+	}
+	return first
+}
+
+// geminiReferenceOrder numbers grounding chunks by where the answer first
+// cites them: the chunk supporting the earliest text is 1. Gemini lists chunks
+// in retrieval order, which doesn't follow the text. Segments in different
+// parts compare by part first.
+func geminiReferenceOrder(first map[int]*genai.Segment) map[int]int {
+	chunks := make([]int, 0, len(first))
+	for idx := range first {
+		chunks = append(chunks, idx)
+	}
+	sort.Slice(chunks, func(i, j int) bool {
+		a, b := first[chunks[i]], first[chunks[j]]
+		if a.PartIndex != b.PartIndex {
+			return a.PartIndex < b.PartIndex
+		}
+		if a.StartIndex != b.StartIndex {
+			return a.StartIndex < b.StartIndex
+		}
+		return chunks[i] < chunks[j]
+	})
+
+	order := make(map[int]int, len(chunks))
+	for rank, idx := range chunks {
+		order[idx] = rank + 1
+	}
+	return order
+}
+
+// geminiChunkDomain returns the source domain for a grounding chunk. Gemini API URIs
+// are vertexaisearch redirect links, but the chunk title is the source's domain.
+func geminiChunkDomain(web *genai.GroundingChunkWeb) string {
+	if web.Domain != "" {
+		return web.Domain
+	}
+	if title := strings.ToLower(web.Title); strings.Contains(title, ".") && !strings.Contains(title, " ") {
+		return strings.TrimPrefix(title, "www.")
+	}
+	return ""
+}
@@ -1,195 +1,108 @@
 package main
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
-	"fmt"
-	"io"
-	"net/http"
+	"log/slog"
 	"os"
-	"regexp"
+	"strings"
 	"time"
 )
 
 const (
-	grokModelID     = "grok-4"
-	grokAPIEndpoint = "https://api.x.ai/v1/responses"
+	grokModelID        = "grok-4"
+	grokDefaultBaseURL = "https://api.x.ai/v1"
 )
 
+// xaiBaseURL overrides XAI_BASE_URL when set via -xai-base-url.
+var xaiBaseURL string
+
 func init() {
 	Register(&GrokProvider{})
 }
 
-// GrokProvider implements Provider for Grok via xAI API.
-type GrokProvider struct{}
+// GrokProvider implements Provider for Grok via the xAI Responses API.
+type GrokProvider struct {
+	api     responsesAPI
+	variant modelVariant
+}
+
+func (p *GrokProvider) Name() string { return p.variant.name("grok") }
+func (p *GrokProvider) DisplayName() string {
+	return p.variant.displayName(p.Name(), "Grok 4 (xAI)")
+}
+func (p *GrokProvider) Emoji() string   { return "⚫" }
+func (p *GrokProvider) ModelID() string { return p.variant.modelOr(grokModelID) }
+
+// WithModel returns an instance for one of the other Grok models, e.g. grok-4-fast.
+func (p *GrokProvider) WithModel(label, model string) (Provider, error) {
+	return &GrokProvider{variant: modelVariant{label: label, model: model}}, nil
+}
+
+// BaseURL returns the xAI API base from -xai-base-url, XAI_BASE_URL, or the default.
+func (p *GrokProvider) BaseURL() string {
+	return resolveBaseURL(xaiBaseURL, "XAI_BASE_URL", grokDefaultBaseURL)
+}
+
+// SupportsAnswerSchema is true: the Responses API accepts a json_schema text format with tools.
+func (p *GrokProvider) SupportsAnswerSchema() bool { return true }
+
+// Capabilities: reasoning effort only applies to models that accept it.
+func (p *GrokProvider) Capabilities() Capabilities {
+	return Capabilities{SystemPrompt: true, Reasoning: grokSupportsReasoningEffort(p.ModelID()), Vision: true}
+}
 
-func (p *GrokProvider) Name() string        { return "grok" }
-func (p *GrokProvider) DisplayName() string { return "Grok 4 (xAI)" }
-func (p *GrokProvider) Emoji() string       { return "⚫" }
+// DefaultTimeout allows for Grok's multi-step agentic search.
+func (p *GrokProvider) DefaultTimeout() time.Duration { return 4 * time.Minute }
 
 func (p *GrokProvider) CheckAuth() error {
 	if os.Getenv("XAI_API_KEY") == "" {
-		return fmt.Errorf("XAI_API_KEY not set")
+		return &AuthError{Reason: "XAI_API_KEY not set", Hint: "export XAI_API_KEY=... (console.x.ai)"}
 	}
 	return nil
 }
 
-func (p *GrokProvider) Query(ctx context.Context, query string, verbose bool) Result {
+func (p *GrokProvider) Query(ctx context.Context, query string, v Verbosity) Result {
+	return p.QueryConversation(ctx, []Message{{Role: RoleUser, Text: query}}, v)
+}
+
+func (p *GrokProvider) QueryConversation(ctx context.Context, history []Message, v Verbosity) Result {
 	start := time.Now()
 	result := Result{}
 
-	apiKey := os.Getenv("XAI_API_KEY")
+	reqBody := newResponsesRequest(p.ModelID(), history)
 
-	if verbose {
-		fmt.Printf("  [Grok] Sending request with web search...\n")
-	}
-
-	reqBody := grokRequest{
-		Model: grokModelID,
-		Input: []grokMessage{
-			{Role: "user", Content: query},
-		},
-		Tools: []grokTool{
-			{Type: "web_search"},
-		},
+	// Grok 4 always reasons and rejects an effort setting; only the mini models accept one.
+	if reasoning != ReasoningOff {
+		if grokSupportsReasoningEffort(p.ModelID()) {
+			reqBody.Reasoning = &responsesReasoning{Effort: reasoning}
+		} else {
+			slog.Debug("reasoning effort not supported, ignoring", "provider", p.Name(), "model", p.ModelID())
+		}
 	}
 
-	jsonData, err := json.Marshal(reqBody)
-	if err != nil {
-		result.Error = fmt.Errorf("marshal error: %w", err)
-		return result
+	if dryRun {
+		return dryRunResult(p, map[string]any{
+			"endpoint": p.BaseURL() + "/responses",
+			"body":     reqBody,
+		})
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", grokAPIEndpoint, bytes.NewBuffer(jsonData))
-	if err != nil {
-		result.Error = fmt.Errorf("request error: %w", err)
-		return result
-	}
+	slog.Debug("sending request", "provider", p.Name(), "tool", "web_search")
 
-	req.Header.Set("Authorization", "Bearer "+apiKey)
-	req.Header.Set("Content-Type", "application/json")
-
-	client := &http.Client{Timeout: 5 * time.Minute}
-	resp, err := client.Do(req)
+	resp, err := p.api.send(ctx, p.Name(), p.BaseURL(), os.Getenv("XAI_API_KEY"), reqBody)
 	result.Duration = time.Since(start)
-
 	if err != nil {
-		result.Error = fmt.Errorf("API error: %w", err)
-		return result
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		result.Error = fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+		result.Error = err
 		return result
 	}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		result.Error = fmt.Errorf("read error: %w", err)
-		return result
-	}
-
-	var grokResp grokResponse
-	if err := json.Unmarshal(body, &grokResp); err != nil {
-		result.Error = fmt.Errorf("parse error: %w", err)
-		return result
-	}
-
-	// Extract token usage
-	if grokResp.Usage != nil {
-		result.Tokens.Input = grokResp.Usage.InputTokens
-		result.Tokens.Output = grokResp.Usage.OutputTokens
-	}
-
-	parseGrokResponse(&grokResp, &result)
+	keepRaw(&result, resp.raw)
+	parseResponsesOutput(resp, &result)
+	logResponsesSearches(v, p.Name(), resp)
 	return result
 }
 
-// --- Grok API Types ---
-
-type grokRequest struct {
-	Model string        `json:"model"`
-	Input []grokMessage `json:"input"`
-	Tools []grokTool    `json:"tools,omitempty"`
-}
-
-type grokMessage struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
-}
-
-type grokTool struct {
-	Type string `json:"type"`
-}
-
-type grokResponse struct {
-	OutputText string `json:"output_text"`
-	Output     []struct {
-		Type    string `json:"type"`
-		Content []struct {
-			Type string `json:"type"`
-			Text string `json:"text"`
-		} `json:"content,omitempty"`
-		Action struct {
-			Type    string `json:"type"`
-			Query   string `json:"query"`
-			URL     string `json:"url"`
-			Sources []struct {
-				URL   string `json:"url"`
-				Title string `json:"title"`
-			} `json:"sources"`
-		} `json:"action,omitempty"`
-	} `json:"output"`
-	Usage *struct {
-		InputTokens  int `json:"input_tokens"`
-		OutputTokens int `json:"output_tokens"`
-	} `json:"usage,omitempty"`
-}
-
-func parseGrokResponse(resp *grokResponse, result *Result) {
-	// Get the main text response
-	result.Text = resp.OutputText
-
-	// Extract text from Output array content blocks
-	if result.Text == "" {
-		for _, out := range resp.Output {
-			for _, content := range out.Content {
-				if content.Type == "output_text" && content.Text != "" {
-					result.Text = content.Text
-					break
-				}
-			}
-			if result.Text != "" {
-				break
-			}
-		}
-	}
-
-	seen := make(map[string]bool)
-
-	// Extract citations from markdown links in text [[n]](url) pattern
-	linkRegex := regexp.MustCompile(`\[\[(\d+)\]\]\((https?://[^\)]+)\)`)
-	matches := linkRegex.FindAllStringSubmatch(result.Text, -1)
-	for _, match := range matches {
-		if len(match) >= 3 {
-			DeduplicateCitations(&result.Citations, seen, Citation{
-				URL: match[2],
-			})
-		}
-	}
-
-	// Also extract from web_search_call action sources
-	for _, out := range resp.Output {
-		if out.Type == "web_search_call" && out.Action.Type == "search" {
-			for _, src := range out.Action.Sources {
-				DeduplicateCitations(&result.Citations, seen, Citation{
-					URL:   src.URL,
-					Title: src.Title,
-				})
-			}
-		}
-	}
+// grokSupportsReasoningEffort reports whether model accepts reasoning.effort.
+func grokSupportsReasoningEffort(model string) bool {
+	return strings.HasPrefix(model, "grok-3-mini")
 }
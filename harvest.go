@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// citationsOnly enables -citations-only: print the merged source list instead
+// of answers, scores, and the judge.
+var citationsOnly bool
+
+// harvestedSource is one deduplicated citation and the providers that cited it.
+type harvestedSource struct {
+	Citation  Citation
+	Providers []string
+}
+
+// harvestedDomain groups sources by registrable domain.
+type harvestedDomain struct {
+	Domain  string
+	Sources []*harvestedSource
+}
+
+// runCitationsOnly queries each provider and prints every citation once,
+// grouped by domain and tagged with the providers that found it.
+func runCitationsOnly(ctx context.Context, names []string, query string) []ModelResult {
+	available, statuses := checkProviders(names)
+	printAuthTable(statuses)
+	warnUnsupportedFlags(available)
+
+	if len(available) == 0 {
+		failNoProviders(statuses)
+	}
+
+	fmt.Printf("🔗 Harvesting sources from %d models...\n", len(available))
+	fmt.Println(strings.Repeat("═", 65))
+	fmt.Println()
+
+	results := make([]ModelResult, len(available))
+	var wg sync.WaitGroup
+	for i, p := range available {
+		wg.Add(1)
+		go func(i int, p Provider) {
+			defer wg.Done()
+			r := queryProvider(ctx, p, query)
+			logProviderResult(p, r)
+			results[i] = ModelResult{Provider: p, Result: r}
+		}(i, p)
+	}
+	wg.Wait()
+
+	for _, mr := range results {
+		if mr.Result.Error != nil {
+			fmt.Printf("❌ %s %s: %v\n", mr.Provider.Emoji(), mr.Provider.DisplayName(), mr.Result.Error)
+		}
+	}
+	printHarvest(harvestCitations(results))
+	return results
+}
+
+// harvestCitations merges citations across results by normalized URL and
+// groups them by domain: domains with the most sources first, sources in the
+// order first cited.
+func harvestCitations(results []ModelResult) []harvestedDomain {
+	byKey := make(map[string]*harvestedSource)
+	byDomain := make(map[string]*harvestedDomain)
+	var domains []*harvestedDomain
+
+	for _, mr := range results {
+		if mr.Result.Error != nil {
+			continue
+		}
+		for _, c := range mr.Result.Citations {
+			key := normalizeURL(c.URL)
+			src, ok := byKey[key]
+			if !ok {
+				src = &harvestedSource{Citation: c}
+				byKey[key] = src
+
+				domain := c.Domain
+				if domain == "" {
+					domain = domainFromURL(c.URL)
+				}
+				domain = registrableDomain(domain)
+				d, ok := byDomain[domain]
+				if !ok {
+					d = &harvestedDomain{Domain: domain}
+					byDomain[domain] = d
+					domains = append(domains, d)
+				}
+				d.Sources = append(d.Sources, src)
+			}
+			if n := mr.Provider.Name(); !slices.Contains(src.Providers, n) {
+				src.Providers = append(src.Providers, n)
+			}
+		}
+	}
+
+	sort.SliceStable(domains, func(i, j int) bool { return len(domains[i].Sources) > len(domains[j].Sources) })
+	out := make([]harvestedDomain, len(domains))
+	for i, d := range domains {
+		out[i] = *d
+	}
+	return out
+}
+
+func printHarvest(domains []harvestedDomain) {
+	total := 0
+	for _, d := range domains {
+		total += len(d.Sources)
+	}
+	if total == 0 {
+		fmt.Println("📭 No citations returned.")
+		return
+	}
+
+	fmt.Println()
+	fmt.Println(bold(fmt.Sprintf("🔗 Sources: %d unique across %d domains", total, len(domains))))
+	fmt.Println(strings.Repeat("─", 70))
+	for _, d := range domains {
+		fmt.Printf("\n%s%s (%d)\n", trustLabel(Citation{Domain: d.Domain}), bold(d.Domain), len(d.Sources))
+		for _, s := range d.Sources {
+			fmt.Printf("   %s %s\n", dim("["+strings.Join(s.Providers, ", ")+"]"), displayURL(s.Citation.URL))
+		}
+	}
+	fmt.Println()
+}
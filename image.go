@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// imageMaxBytes is the largest -image accepted; Claude rejects bigger images
+// and Bedrock's limit is lower still.
+const imageMaxBytes = 5 << 20
+
+// imageMediaTypes are the formats every vision provider accepts.
+var imageMediaTypes = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// queryImage is an image attached to the user's question with -image.
+type queryImage struct {
+	Name      string // File name, for messages
+	MediaType string // e.g. "image/png"
+	Data      []byte
+}
+
+// Base64 returns the image data base64-encoded.
+func (img queryImage) Base64() string {
+	return base64.StdEncoding.EncodeToString(img.Data)
+}
+
+// DataURL returns the image as a data: URL.
+func (img queryImage) DataURL() string {
+	return "data:" + img.MediaType + ";base64," + img.Base64()
+}
+
+// Format returns the bare format name ("png", "jpeg", ...).
+func (img queryImage) Format() string {
+	return strings.TrimPrefix(img.MediaType, "image/")
+}
+
+// queryImages are the -image attachments, sent with the first user message.
+var queryImages []queryImage
+
+// imagePaths collects repeated -image flags.
+type imagePaths []string
+
+func (p *imagePaths) String() string { return strings.Join(*p, ",") }
+
+func (p *imagePaths) Set(path string) error {
+	*p = append(*p, path)
+	return nil
+}
+
+// errNoVision marks a provider skipped because it can't take -image input.
+var errNoVision = errors.New("no image input support (-image)")
+
+// loadImages reads and validates each -image file. The media type is sniffed
+// from the content rather than trusted from the extension.
+func loadImages(paths []string) ([]queryImage, error) {
+	var images []queryImage
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read image: %w", err)
+		}
+		if len(data) > imageMaxBytes {
+			return nil, fmt.Errorf("image %s is %d bytes (max %d)", path, len(data), imageMaxBytes)
+		}
+		mediaType := http.DetectContentType(data)
+		if !imageMediaTypes[mediaType] {
+			return nil, fmt.Errorf("image %s: unsupported type %s (use PNG, JPEG, GIF, or WebP)", path, mediaType)
+		}
+		images = append(images, queryImage{Name: filepath.Base(path), MediaType: mediaType, Data: data})
+	}
+	return images, nil
+}
+
+// userMessage returns the user's question as a message, carrying the -image
+// attachments.
+func userMessage(text string) Message {
+	return Message{Role: RoleUser, Text: text, Images: queryImages}
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// jsonQueryRecord is the machine-readable form of one query and its results.
+type jsonQueryRecord struct {
+	Query     string            `json:"query"`
+	Timestamp time.Time         `json:"timestamp"`
+	Results   []jsonModelResult `json:"results"`
+}
+
+type jsonModelResult struct {
+	Provider      string          `json:"provider"`
+	Model         string          `json:"model"`
+	Rank          int             `json:"rank,omitempty"`
+	Text          string          `json:"text,omitempty"`
+	Thinking      string          `json:"thinking,omitempty"`
+	Error         string          `json:"error,omitempty"`
+	ErrorKind     ErrorKind       `json:"error_kind,omitempty"`
+	DurationMS    int64           `json:"duration_ms"`
+	Words         int             `json:"words"`
+	Citations     []jsonCitation  `json:"citations"`
+	SearchCount   int             `json:"search_count,omitempty"`
+	Timeline      []jsonStep      `json:"timeline,omitempty"`
+	InputTokens   int             `json:"input_tokens"`
+	OutputTokens  int             `json:"output_tokens"`
+	EstimatedCost float64         `json:"estimated_cost"`
+	CostUnknown   bool            `json:"cost_unknown,omitempty"` // Model has no known token price; estimated_cost is the search fee only
+	FinishReason  string          `json:"finish_reason,omitempty"`
+	Truncated     bool            `json:"truncated,omitempty"`
+	Ungrounded    bool            `json:"ungrounded,omitempty"`
+	Cached        bool            `json:"cached,omitempty"`
+	Language      string          `json:"language,omitempty"`
+	Judge         *jsonJudgeScore `json:"judge,omitempty"`
+	Raw           json.RawMessage `json:"raw,omitempty"` // -include-raw
+}
+
+type jsonCitation struct {
+	Index       int        `json:"index,omitempty"` // In-text reference number, when known
+	URL         string     `json:"url"`
+	DisplayURL  string     `json:"display_url,omitempty"` // With -clean-urls, when it differs from url
+	Domain      string     `json:"domain,omitempty"`
+	Title       string     `json:"title,omitempty"`
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+	Trust       TrustTier  `json:"trust,omitempty"` // gov, edu, news, or trusted (-trust-file)
+}
+
+type jsonStep struct {
+	Kind       string `json:"kind"`
+	Detail     string `json:"detail,omitempty"`
+	DurationMS int64  `json:"duration_ms,omitempty"` // Omitted when the API doesn't time steps
+}
+
+type jsonJudgeScore struct {
+	Overall      float64 `json:"overall"`
+	Quality      int     `json:"quality"`
+	LinkHealth   int     `json:"link_health"`
+	Diversity    int     `json:"diversity"`
+	Recency      int     `json:"recency"`
+	Significance int     `json:"significance"`
+	Impact       int     `json:"impact"`
+	Reasoning    string  `json:"reasoning,omitempty"`
+}
+
+// newJSONQueryRecord converts ranked results to their JSON form, in display
+// order (rank order unless -pin-order).
+func newJSONQueryRecord(query string, results []ModelResult) jsonQueryRecord {
+	rec := jsonQueryRecord{Query: query, Timestamp: time.Now(), Results: make([]jsonModelResult, 0, len(results))}
+	for _, mr := range results {
+		rec.Results = append(rec.Results, newJSONModelResult(mr))
+	}
+	return rec
+}
+
+func newJSONModelResult(mr ModelResult) jsonModelResult {
+	r := mr.Result
+	jr := jsonModelResult{
+		Provider:      mr.Provider.Name(),
+		Model:         mr.Provider.DisplayName(),
+		Rank:          mr.Rank,
+		Text:          r.Text,
+		Thinking:      r.Thinking,
+		DurationMS:    r.Duration.Milliseconds(),
+		Words:         r.WordCount(),
+		Citations:     make([]jsonCitation, 0, len(r.Citations)),
+		InputTokens:   r.Tokens.Input,
+		OutputTokens:  r.Tokens.Output,
+		EstimatedCost: r.EstimatedCost(mr.Provider.Name()),
+		CostUnknown:   costUnknown(mr.Provider.Name()) && !r.Cached,
+		FinishReason:  r.FinishReason,
+		Truncated:     r.Truncated(),
+		Ungrounded:    r.Ungrounded,
+		Cached:        r.Cached,
+		Language:      r.Language,
+		SearchCount:   r.SearchCount,
+	}
+	if includeRaw {
+		jr.Raw = r.Raw
+	}
+	if r.Error != nil {
+		jr.Error = r.Error.Error()
+		jr.ErrorKind = r.ErrorKind
+	}
+	for _, s := range r.Timeline {
+		jr.Timeline = append(jr.Timeline, jsonStep{Kind: s.Kind, Detail: s.Detail, DurationMS: s.Duration.Milliseconds()})
+	}
+	for _, c := range r.Citations {
+		jc := jsonCitation{Index: c.Index, URL: c.URL, Domain: c.Domain, Title: c.Title, PublishedAt: c.PublishedAt, Trust: c.Trust()}
+		if u := displayURL(c.URL); u != c.URL {
+			jc.DisplayURL = u
+		}
+		jr.Citations = append(jr.Citations, jc)
+	}
+	if js := mr.JudgeScore; js != nil {
+		jr.Judge = &jsonJudgeScore{
+			Overall:      js.Overall,
+			Quality:      js.Quality,
+			LinkHealth:   js.LinkHealth,
+			Diversity:    js.Diversity,
+			Recency:      js.Recency,
+			Significance: js.Significance,
+			Impact:       js.Impact,
+			Reasoning:    js.Reasoning,
+		}
+	}
+	return jr
+}
+
+// jsonlOut is the -jsonl-out path; each completed query is appended as one line.
+var jsonlOut string
+
+// jsonlWriter appends query records to a JSON-lines file, syncing after every
+// record so a crash while writing keeps every record already appended.
+type jsonlWriter struct {
+	mu sync.Mutex
+	f  *os.File
+}
+
+func openJSONL(path string) (*jsonlWriter, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", path, err)
+	}
+	return &jsonlWriter{f: f}, nil
+}
+
+// Write appends query's results as one JSON line.
+func (w *jsonlWriter) Write(query string, results []ModelResult) error {
+	data, err := json.Marshal(newJSONQueryRecord(query, results))
+	if err != nil {
+		return fmt.Errorf("marshal record: %w", err)
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, err := w.f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("write record: %w", err)
+	}
+	return w.f.Sync()
+}
+
+func (w *jsonlWriter) Close() error {
+	return w.f.Close()
+}
+
+// appendJSONL records a finished query to -jsonl-out, if set.
+func appendJSONL(w *jsonlWriter, query string, results []ModelResult) {
+	if w == nil || len(results) == 0 {
+		return
+	}
+	if err := w.Write(query, results); err != nil {
+		fmt.Printf("⚠️  JSONL error: %v\n", err)
+	}
+}
@@ -3,7 +3,10 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
+	"net"
 	"net/http"
 	"sort"
 	"strings"
@@ -11,50 +14,173 @@ import (
 	"time"
 
 	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/anthropics/anthropic-sdk-go/option"
 )
 
-const judgeModelID = "claude-haiku-4-5-20251001"
+const (
+	judgeModelID = "claude-haiku-4-5-20251001"
+
+	// judgeInputPrice is the judge model's input price in USD per million
+	// tokens. Prompt cache reads bill at a tenth of it, cache writes at 1.25x.
+	judgeInputPrice = 1.00
+
+	// judgeConcurrency bounds parallel judge API calls in JudgeBatch.
+	judgeConcurrency = 4
+)
+
+// judgeClient is shared by all judge calls so batch runs reuse one connection pool.
+var (
+	judgeClientOnce sync.Once
+	judgeClient     anthropic.Client
+)
+
+// judgeCacheStats totals prompt cache usage across judge calls, for the -v
+// savings report.
+var (
+	judgeCacheStats   struct{ Calls, Read, Written int64 }
+	judgeCacheStatsMu sync.Mutex
+	judgeCacheUnused  sync.Once // The "cache unused" notice is logged once per run
+)
+
+// recordJudgeCacheUsage logs one judge call's prompt cache usage and the
+// running savings (-v). Nothing is read or written when the cached prefix is
+// shorter than the model's minimum cacheable length, which is the usual case
+// with the default rubric.
+func recordJudgeCacheUsage(u anthropic.Usage) {
+	judgeCacheStatsMu.Lock()
+	judgeCacheStats.Calls++
+	judgeCacheStats.Read += u.CacheReadInputTokens
+	judgeCacheStats.Written += u.CacheCreationInputTokens
+	total := judgeCacheStats
+	judgeCacheStatsMu.Unlock()
+
+	if u.CacheReadInputTokens == 0 && u.CacheCreationInputTokens == 0 {
+		judgeCacheUnused.Do(func() {
+			slog.Info("judge prompt cache unused (tool and instructions below the model's minimum cacheable length)", "input_tokens", u.InputTokens)
+		})
+		return
+	}
+	slog.Info("judge prompt cache",
+		"cache_read_input_tokens", u.CacheReadInputTokens,
+		"cache_creation_input_tokens", u.CacheCreationInputTokens,
+		"input_tokens", u.InputTokens,
+		"saved", fmt.Sprintf("$%.4f", judgeCacheSavings(u.CacheReadInputTokens, u.CacheCreationInputTokens)),
+		"saved_total", fmt.Sprintf("$%.4f over %d calls", judgeCacheSavings(total.Read, total.Written), total.Calls))
+}
+
+// judgeCacheSavings is what prompt caching saved versus sending the cached
+// tokens uncached: reads are 90% cheaper, writes cost 25% more.
+func judgeCacheSavings(read, written int64) float64 {
+	return (float64(read)*0.9 - float64(written)*0.25) * judgeInputPrice / 1_000_000
+}
+
+// linkCheckCache remembers HEAD results by URL so sources shared across models
+// or batch queries are only checked once per run.
+var (
+	linkCheckCache   = make(map[string]CitationCheck)
+	linkCheckCacheMu sync.Mutex
+)
+
+// Link check limits, set by -linkcheck-timeout and -linkcheck-concurrency.
+// The concurrency limit is shared by every validateCitations call, so checking
+// several models at once still opens at most that many connections.
+var (
+	linkCheckTimeout     = 5 * time.Second
+	linkCheckConcurrency = 16
+	linkCheckSemOnce     sync.Once
+	linkCheckSem         chan struct{}
+)
+
+func linkCheckSlots() chan struct{} {
+	linkCheckSemOnce.Do(func() { linkCheckSem = make(chan struct{}, max(linkCheckConcurrency, 1)) })
+	return linkCheckSem
+}
 
 // CitationCheck holds the result of an HTTP HEAD validation for a citation URL.
 type CitationCheck struct {
-	URL        string
-	StatusCode int
-	Healthy    bool
-	Latency    time.Duration
-	Error      string
+	URL          string
+	StatusCode   int
+	Healthy      bool
+	Latency      time.Duration
+	LastModified *time.Time // From the Last-Modified response header, if present
+	Error        string
 }
 
-// validateCitations performs parallel HTTP HEAD requests to check citation URLs.
-func validateCitations(citations []Citation) []CitationCheck {
+// validateCitations performs parallel HTTP HEAD requests to check citation URLs,
+// at most linkCheckConcurrency at a time. A check that runs past
+// linkCheckTimeout is recorded as a timeout, distinct from connection errors.
+// Canceling ctx stops in-flight requests; URLs checked by then keep their
+// results and the rest are returned with the context error (and not cached).
+func validateCitations(ctx context.Context, citations []Citation) []CitationCheck {
 	checks := make([]CitationCheck, len(citations))
 	var wg sync.WaitGroup
+	sem := linkCheckSlots()
 
-	client := &http.Client{
-		Timeout: 5 * time.Second,
-		CheckRedirect: func(req *http.Request, via []*http.Request) error {
-			return nil // follow redirects
-		},
+	client := newHTTPClient(linkCheckTimeout)
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		return nil // follow redirects
 	}
 
 	for i, c := range citations {
 		wg.Add(1)
 		go func(idx int, citation Citation) {
 			defer wg.Done()
+
+			linkCheckCacheMu.Lock()
+			cached, ok := linkCheckCache[citation.URL]
+			linkCheckCacheMu.Unlock()
+			if ok {
+				checks[idx] = cached
+				return
+			}
+
 			check := CitationCheck{URL: citation.URL}
+
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				check.Error = ctx.Err().Error()
+				checks[idx] = check
+				return
+			}
+
 			start := time.Now()
 
-			resp, err := client.Head(citation.URL)
+			req, err := http.NewRequestWithContext(ctx, "HEAD", citation.URL, nil)
+			if err != nil {
+				check.Error = err.Error()
+				checks[idx] = check
+				return
+			}
+			resp, err := client.Do(req)
 			check.Latency = time.Since(start)
 
 			if err != nil {
-				check.Error = err.Error()
+				if ctx.Err() != nil {
+					check.Error = err.Error()
+					checks[idx] = check
+					return
+				}
+				var netErr net.Error
+				if errors.As(err, &netErr) && netErr.Timeout() {
+					check.Error = fmt.Sprintf("timeout after %s", linkCheckTimeout)
+				} else {
+					check.Error = "connection error: " + err.Error()
+				}
 			} else {
 				resp.Body.Close()
 				check.StatusCode = resp.StatusCode
 				check.Healthy = resp.StatusCode >= 200 && resp.StatusCode < 400
+				if lm, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+					check.LastModified = &lm
+				}
 			}
 
 			checks[idx] = check
+			linkCheckCacheMu.Lock()
+			linkCheckCache[citation.URL] = check
+			linkCheckCacheMu.Unlock()
 		}(i, c)
 	}
 
@@ -63,8 +189,12 @@ func validateCitations(citations []Citation) []CitationCheck {
 }
 
 // linkHealthScore computes a 1-10 score from citation check results.
-// Returns 5 if there are no citations (neutral).
+// Returns 5 if there are no citations (neutral). Under -dedupe-domain-score
+// only the first link per domain counts.
 func linkHealthScore(checks []CitationCheck) int {
+	if dedupeDomainScore {
+		checks = firstCheckPerDomain(checks)
+	}
 	if len(checks) == 0 {
 		return 5
 	}
@@ -82,6 +212,30 @@ func linkHealthScore(checks []CitationCheck) int {
 	return score
 }
 
+// firstCheckPerDomain keeps the first check for each registrable domain.
+func firstCheckPerDomain(checks []CitationCheck) []CitationCheck {
+	seen := make(map[string]bool)
+	var out []CitationCheck
+	for _, c := range checks {
+		d := registrableDomain(domainFromURL(c.URL))
+		if seen[d] {
+			continue
+		}
+		seen[d] = true
+		out = append(out, c)
+	}
+	return out
+}
+
+// diversityScore maps domain diversity to a 1-10 score.
+// Returns 5 if there are no citations (neutral), matching linkHealthScore.
+func diversityScore(r Result) int {
+	if len(r.Citations) == 0 {
+		return 5
+	}
+	return int(r.DomainDiversity()*9) + 1
+}
+
 // judgeEvaluation is the structured response from the LLM judge per model.
 type judgeEvaluation struct {
 	Model        string `json:"model"`
@@ -97,18 +251,33 @@ type judgeToolResponse struct {
 	Evaluations []judgeEvaluation `json:"evaluations"`
 }
 
-// buildJudgePrompt constructs the prompt for the LLM judge.
+// judgeInstructions returns the static part of the judge prompt: the rubric
+// and scoring instructions, which depend only on flags. It is sent ahead of
+// buildJudgePrompt's per-query part and marked for prompt caching, so batch
+// runs pay for it once.
+func judgeInstructions() string {
+	var b strings.Builder
+
+	b.WriteString(activeJudgeRubric())
+	b.WriteString("\n\n")
+	b.WriteString("I have already validated citation links. Link health scores are provided.\n")
+	b.WriteString("Where known, each citation's publication or last-modified date is listed; base recency on these dates rather than guessing.\n")
+	if !sinceTime.IsZero() {
+		b.WriteString(fmt.Sprintf("The user asked for sources published on or after %s. Score recency low for responses that rely on older sources.\n", sinceLabel()))
+	}
+	if dedupeDomainScore {
+		b.WriteString("Several citations from the same site count as one source: reward breadth of independent sources, not the number of links.\n")
+	}
+	b.WriteString("Citations from recognized sources are tagged [gov], [edu], [news], or [trusted]; let well-established sources count toward quality, but don't penalize an untagged source for being unlisted.\n")
+	return b.String()
+}
+
+// buildJudgePrompt constructs the per-query part of the judge prompt, which
+// follows judgeInstructions.
 func buildJudgePrompt(results []ModelResult, query string, allChecks map[string][]CitationCheck) string {
 	var b strings.Builder
 
-	b.WriteString("You are a news editor evaluating web search results from multiple AI models.\n\n")
 	b.WriteString(fmt.Sprintf("QUERY: %q\n\n", query))
-	b.WriteString("For EACH model below, score these dimensions from 1-10:\n")
-	b.WriteString("- quality: depth, coherence, factual accuracy of the response\n")
-	b.WriteString("- recency: how current the information and cited sources are (today > this week > this month > older)\n")
-	b.WriteString("- significance: is this newsworthy and substantial? Would it make WSJ or major outlets?\n")
-	b.WriteString("- impact: how impactful is this to the relevant business, industry, or topic?\n\n")
-	b.WriteString("I have already validated citation links. Link health scores are provided.\n\n")
 
 	for _, mr := range results {
 		if mr.Result.Error != nil {
@@ -117,7 +286,8 @@ func buildJudgePrompt(results []ModelResult, query string, allChecks map[string]
 		p := mr.Provider
 		r := mr.Result
 
-		wordCount := len(strings.Fields(r.Text))
+		text := stripThinkingTags(r.Text)
+		wordCount := r.WordCount()
 		checks := allChecks[p.Name()]
 		healthyCount := 0
 		for _, c := range checks {
@@ -130,28 +300,47 @@ func buildJudgePrompt(results []ModelResult, query string, allChecks map[string]
 		b.WriteString(fmt.Sprintf("=== MODEL: %s ===\n", p.DisplayName()))
 
 		// Truncate text to ~500 words
-		text := r.Text
 		words := strings.Fields(text)
 		if len(words) > 500 {
 			text = strings.Join(words[:500], " ") + "..."
 		}
-		b.WriteString(fmt.Sprintf("Response (%d words, %d citations):\n", wordCount, len(r.Citations)))
+		if dedupeDomainScore {
+			b.WriteString(fmt.Sprintf("Response (%d words, %d citations from %d domains):\n", wordCount, len(r.Citations), r.UniqueDomains()))
+		} else {
+			b.WriteString(fmt.Sprintf("Response (%d words, %d citations):\n", wordCount, len(r.Citations)))
+		}
+		if r.Ungrounded {
+			b.WriteString("[Note: web search was unavailable for this model, so it answered from its own knowledge without live sources.]\n")
+		}
+		if r.Truncated() {
+			b.WriteString("[Note: this response was cut off by the output token limit. Do not penalize the abrupt ending as incoherence; judge the content that is present.]\n")
+		}
 		b.WriteString(text)
 		b.WriteString("\n\n")
 
+		// Match checks by URL, not position, so a reordered citation list can't
+		// attribute a status to the wrong source.
+		byURL := make(map[string]*CitationCheck, len(checks))
+		for i := range checks {
+			byURL[checks[i].URL] = &checks[i]
+		}
+
 		b.WriteString(fmt.Sprintf("Citations (%d/%d links working):\n", healthyCount, len(r.Citations)))
 		for i, c := range r.Citations {
 			status := "unknown"
-			if i < len(checks) {
-				if checks[i].Healthy {
-					status = fmt.Sprintf("%d OK", checks[i].StatusCode)
-				} else if checks[i].Error != "" {
+			check := byURL[c.URL]
+			if check != nil {
+				if check.Healthy {
+					status = fmt.Sprintf("%d OK", check.StatusCode)
+				} else if strings.HasPrefix(check.Error, "timeout") {
+					status = "timeout"
+				} else if check.Error != "" {
 					status = "error"
 				} else {
-					status = fmt.Sprintf("%d", checks[i].StatusCode)
+					status = fmt.Sprintf("%d", check.StatusCode)
 				}
 			}
-			b.WriteString(fmt.Sprintf("  %d. %s - %s\n", i+1, c.URL, status))
+			b.WriteString(fmt.Sprintf("  %d. %s - %s%s%s\n", i+1, c.URL, status, citationDateNote(c, check), citationTrustNote(c)))
 		}
 		b.WriteString(fmt.Sprintf("Link Health Score: %d/10\n", lhScore))
 		b.WriteString("===\n\n")
@@ -162,13 +351,113 @@ func buildJudgePrompt(results []ModelResult, query string, allChecks map[string]
 	return b.String()
 }
 
+// citationTrustNote tags a citation with its trust tier for the judge, or "".
+func citationTrustNote(c Citation) string {
+	if t := c.Trust(); t != TrustUnknown {
+		return fmt.Sprintf(" [%s]", t)
+	}
+	return ""
+}
+
+// citationDateNote formats the best known date for a citation, preferring the
+// publication date over the Last-Modified header. Returns "" when neither is known.
+func citationDateNote(c Citation, check *CitationCheck) string {
+	if c.PublishedAt != nil {
+		return fmt.Sprintf(" (published %s)", c.PublishedAt.Format("2006-01-02"))
+	}
+	if check != nil && check.LastModified != nil {
+		return fmt.Sprintf(" (last modified %s)", check.LastModified.Format("2006-01-02"))
+	}
+	return ""
+}
+
+// QueryResults pairs a query with the model results collected for it.
+type QueryResults struct {
+	Query   string
+	Results []ModelResult
+}
+
 // Judge evaluates all model results using link validation and an LLM judge.
-func Judge(ctx context.Context, results []ModelResult, query string, verbose bool) ([]ModelResult, error) {
+func Judge(ctx context.Context, results []ModelResult, query string) ([]ModelResult, error) {
+	judged, errs := JudgeBatch(ctx, []QueryResults{{Query: query, Results: results}})
+	return judged[0].Results, errs[0]
+}
+
+// JudgeBatch judges several queries' results concurrently, with at most
+// judgeConcurrency judge calls in flight. Results and errors are returned in
+// input order; a failed query keeps its results unscored. Ranking is left to
+// rankResults.
+func JudgeBatch(ctx context.Context, batch []QueryResults) ([]QueryResults, []error) {
+	out := make([]QueryResults, len(batch))
+	errs := make([]error, len(batch))
+	sem := make(chan struct{}, judgeConcurrency)
+	var wg sync.WaitGroup
+
+	for i, qr := range batch {
+		wg.Add(1)
+		go func(idx int, qr QueryResults) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			results, err := judgeQuery(ctx, qr.Results, qr.Query)
+			out[idx] = QueryResults{Query: qr.Query, Results: results}
+			errs[idx] = err
+		}(i, qr)
+	}
+	wg.Wait()
+
+	return out, errs
+}
+
+// judgeQuery runs link validation, the LLM judge call, and scoring for one query.
+func judgeQuery(ctx context.Context, results []ModelResult, query string) ([]ModelResult, error) {
 	// Phase 1: Validate all citations in parallel
-	if verbose {
-		fmt.Println("  [Judge] Validating citation links...")
+	allChecks := checkAllCitations(ctx, results)
+
+	// Count valid (non-error) results
+	validCount := 0
+	for _, mr := range results {
+		if mr.Result.Error == nil {
+			validCount++
+		}
+	}
+	if validCount == 0 {
+		return results, nil
 	}
 
+	// Phase 2: Call LLM judge
+	evals, err := judgeEvaluate(ctx, results, query, allChecks)
+	if err != nil {
+		return results, err
+	}
+
+	// Phase 3: Attach scores to results
+	applyJudgeScores(results, evals, allChecks)
+	return results, nil
+}
+
+// judgeEvaluate scores one query's results. It is the LLM judge unless the
+// mock setup swaps in mockJudge, the same way mock providers are registered.
+var judgeEvaluate = llmJudge
+
+// llmJudge asks the judge model to score results, given their link checks.
+func llmJudge(ctx context.Context, results []ModelResult, query string, checks map[string][]CitationCheck) ([]judgeEvaluation, error) {
+	instructions := judgeInstructions()
+	prompt := buildJudgePrompt(results, query, checks)
+	evals, err := callJudge(ctx, instructions, prompt)
+	if err != nil {
+		return nil, err
+	}
+	recordJudgeExchange(instructions+"\n\n"+prompt, evals)
+	return evals, nil
+}
+
+// checkAllCitations validates every successful result's citations in parallel,
+// keyed by provider name.
+func checkAllCitations(ctx context.Context, results []ModelResult) map[string][]CitationCheck {
+	slog.Debug("validating citation links")
+
 	allChecks := make(map[string][]CitationCheck)
 	var mu sync.Mutex
 	var wg sync.WaitGroup
@@ -180,7 +469,7 @@ func Judge(ctx context.Context, results []ModelResult, query string, verbose boo
 		wg.Add(1)
 		go func(mr ModelResult) {
 			defer wg.Done()
-			checks := validateCitations(mr.Result.Citations)
+			checks := validateCitations(ctx, mr.Result.Citations)
 			mu.Lock()
 			allChecks[mr.Provider.Name()] = checks
 			mu.Unlock()
@@ -188,37 +477,25 @@ func Judge(ctx context.Context, results []ModelResult, query string, verbose boo
 	}
 	wg.Wait()
 
-	if verbose {
-		for name, checks := range allChecks {
-			healthy := 0
-			for _, c := range checks {
-				if c.Healthy {
-					healthy++
-				}
+	for name, checks := range allChecks {
+		healthy := 0
+		for _, c := range checks {
+			if c.Healthy {
+				healthy++
+			} else {
+				slog.Debug("link check failed", "provider", name, "url", c.URL, "status", c.StatusCode, "error", c.Error)
 			}
-			fmt.Printf("  [Judge] %s: %d/%d links healthy\n", name, healthy, len(checks))
-		}
-	}
-
-	// Count valid (non-error) results
-	validCount := 0
-	for _, mr := range results {
-		if mr.Result.Error == nil {
-			validCount++
 		}
-	}
-	if validCount == 0 {
-		return results, nil
+		slog.Debug("link check complete", "provider", name, "healthy", healthy, "total", len(checks))
 	}
 
-	// Phase 2: Call LLM judge
-	if verbose {
-		fmt.Println("  [Judge] Calling LLM judge (Claude Haiku 4.5)...")
-	}
-
-	prompt := buildJudgePrompt(results, query, allChecks)
+	return allChecks
+}
 
-	client := anthropic.NewClient()
+// callJudge sends the judge instructions and per-query prompt to the LLM and
+// returns its structured evaluations.
+func callJudge(ctx context.Context, instructions, prompt string) ([]judgeEvaluation, error) {
+	slog.Debug("calling LLM judge", "model", judgeModelID)
 
 	// Define the scoring tool schema
 	evaluationItemSchema := map[string]any{
@@ -234,94 +511,196 @@ func Judge(ctx context.Context, results []ModelResult, query string, verbose boo
 		"required": []any{"model", "quality", "recency", "significance", "impact", "reasoning"},
 	}
 
-	message, err := client.Messages.New(ctx, anthropic.MessageNewParams{
+	tool := anthropic.ToolParam{
+		Name:        "score_models",
+		Description: anthropic.String("Score each AI model's web search results across quality, recency, significance, and impact dimensions."),
+		InputSchema: anthropic.ToolInputSchemaParam{
+			Properties: map[string]any{
+				"evaluations": map[string]any{
+					"type":  "array",
+					"items": evaluationItemSchema,
+				},
+			},
+			Required: []string{"evaluations"},
+		},
+	}
+
+	var toolInput judgeToolResponse
+	if err := judgeToolCall(ctx, instructions, prompt, tool, &toolInput); err != nil {
+		return nil, err
+	}
+
+	if len(toolInput.Evaluations) == 0 {
+		return nil, fmt.Errorf("judge returned no evaluations")
+	}
+
+	slog.Debug("judge evaluations received", "count", len(toolInput.Evaluations))
+	return toolInput.Evaluations, nil
+}
+
+// judgeToolCall sends prompt to the judge model, forces a call to tool, and
+// unmarshals the tool input into out. A non-empty cachedPrefix is sent first
+// as its own block, and it and the tool definition get cache_control
+// breakpoints, so the whole static prefix (tool, then instructions) counts
+// toward the minimum cacheable length and calls sharing it read it from
+// Anthropic's prompt cache. Transient API errors are
+// retried with withRetry, since a judge failure comes after all the provider
+// spend.
+func judgeToolCall(ctx context.Context, cachedPrefix, prompt string, tool anthropic.ToolParam, out any) error {
+	// withRetry owns backoff so it isn't compounded by the SDK's own retries.
+	judgeClientOnce.Do(func() { judgeClient = newAnthropicClient(option.WithMaxRetries(0)) })
+
+	var blocks []anthropic.ContentBlockParamUnion
+	if cachedPrefix != "" {
+		tool.CacheControl = anthropic.NewCacheControlEphemeralParam()
+		prefix := anthropic.NewTextBlock(cachedPrefix)
+		prefix.OfText.CacheControl = anthropic.NewCacheControlEphemeralParam()
+		blocks = append(blocks, prefix)
+	}
+	blocks = append(blocks, anthropic.NewTextBlock(prompt))
+
+	params := anthropic.MessageNewParams{
 		Model:     judgeModelID,
 		MaxTokens: 2048,
 		Messages: []anthropic.MessageParam{
-			anthropic.NewUserMessage(anthropic.NewTextBlock(prompt)),
-		},
-		ToolChoice: anthropic.ToolChoiceParamOfTool("score_models"),
-		Tools: []anthropic.ToolUnionParam{
-			{
-				OfTool: &anthropic.ToolParam{
-					Name:        "score_models",
-					Description: anthropic.String("Score each AI model's web search results across quality, recency, significance, and impact dimensions."),
-					InputSchema: anthropic.ToolInputSchemaParam{
-						Properties: map[string]any{
-							"evaluations": map[string]any{
-								"type":  "array",
-								"items": evaluationItemSchema,
-							},
-						},
-						Required: []string{"evaluations"},
-					},
-				},
-			},
+			anthropic.NewUserMessage(blocks...),
 		},
+		ToolChoice: anthropic.ToolChoiceParamOfTool(tool.Name),
+		Tools:      []anthropic.ToolUnionParam{{OfTool: &tool}},
+	}
+	var message *anthropic.Message
+	attempts, err := withRetry(ctx, "judge", judgeRetryable, func() error {
+		var err error
+		message, err = judgeClient.Messages.New(ctx, params)
+		return err
 	})
 
 	if err != nil {
-		return results, fmt.Errorf("judge API error: %w", err)
+		if attempts > 1 {
+			return fmt.Errorf("judge API error after %d attempts: %w", attempts, err)
+		}
+		return fmt.Errorf("judge API error: %w", err)
+	}
+	if cachedPrefix != "" {
+		recordJudgeCacheUsage(message.Usage)
 	}
 
 	// Parse the tool_use response
-	var toolInput judgeToolResponse
 	for _, block := range message.Content {
-		if tb := block.AsToolUse(); tb.Name == "score_models" {
-			if err := json.Unmarshal(tb.Input, &toolInput); err != nil {
-				return results, fmt.Errorf("judge parse error: %w", err)
+		if tb := block.AsToolUse(); tb.Name == tool.Name {
+			if err := json.Unmarshal(tb.Input, out); err != nil {
+				slog.Debug("judge tool input unparseable", "tool", tool.Name, "error", err, "input", string(tb.Input))
+				return fmt.Errorf("judge parse error: %w", err)
 			}
-			break
+			return nil
 		}
 	}
+	return fmt.Errorf("judge did not call %s", tool.Name)
+}
 
-	if len(toolInput.Evaluations) == 0 {
-		return results, fmt.Errorf("judge returned no evaluations")
+// judgeRetryable reports whether a judge API error is transient: a timeout,
+// conflict, rate limit, server error or overload (5xx, 529), or a dropped
+// connection. The status codes match the ones the SDK itself retries.
+func judgeRetryable(err error) bool {
+	var apiErr *anthropic.Error
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusRequestTimeout, http.StatusConflict, http.StatusTooManyRequests:
+			return true
+		}
+		return apiErr.StatusCode >= http.StatusInternalServerError
 	}
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
 
-	if verbose {
-		fmt.Printf("  [Judge] Received %d evaluations\n", len(toolInput.Evaluations))
+// matchJudgeEvaluations validates the judge's tool output against the models
+// it was shown and returns each matched evaluation keyed by provider name.
+// Scores outside 1-10 are clamped; evaluations for models that weren't judged,
+// or a second one for the same model, are dropped. Models left without an
+// evaluation fall back to link health in applyJudgeScores. Discrepancies are
+// logged at info level (-v).
+func matchJudgeEvaluations(results []ModelResult, evals []judgeEvaluation) map[string]judgeEvaluation {
+	used := make([]bool, len(evals))
+	matched := make(map[string]judgeEvaluation)
+	match := func(p Provider, i int) {
+		e := evals[i]
+		e.Quality = clampJudgeScore(p, "quality", e.Quality)
+		e.Recency = clampJudgeScore(p, "recency", e.Recency)
+		e.Significance = clampJudgeScore(p, "significance", e.Significance)
+		e.Impact = clampJudgeScore(p, "impact", e.Impact)
+		matched[p.Name()] = e
+		used[i] = true
 	}
 
-	// Phase 3: Attach scores to results
-	// Build a lookup from display name to evaluation
-	evalMap := make(map[string]judgeEvaluation)
-	for _, eval := range toolInput.Evaluations {
-		evalMap[eval.Model] = eval
+	// Exact display names first, so a loose match can't claim another
+	// model's evaluation; then names the judge paraphrased.
+	for _, mr := range results {
+		if mr.Result.Error != nil {
+			continue
+		}
+		for i, e := range evals {
+			if !used[i] && strings.EqualFold(strings.TrimSpace(e.Model), mr.Provider.DisplayName()) {
+				match(mr.Provider, i)
+				break
+			}
+		}
 	}
+	for _, mr := range results {
+		p := mr.Provider
+		if _, ok := matched[p.Name()]; ok || mr.Result.Error != nil {
+			continue
+		}
+		for i, e := range evals {
+			name := strings.ToLower(strings.TrimSpace(e.Model))
+			if !used[i] && name != "" && (strings.Contains(name, strings.ToLower(p.Name())) ||
+				strings.Contains(strings.ToLower(p.DisplayName()), name)) {
+				match(p, i)
+				break
+			}
+		}
+		if _, ok := matched[p.Name()]; !ok {
+			slog.Info("judge omitted a model, scoring by link health", "model", p.DisplayName())
+		}
+	}
+
+	for i, e := range evals {
+		if !used[i] {
+			slog.Info("judge evaluation ignored: no such model or a duplicate", "model", e.Model)
+		}
+	}
+	return matched
+}
+
+// clampJudgeScore pins a judge score to the 1-10 scale the tool asks for.
+func clampJudgeScore(p Provider, dimension string, score int) int {
+	clamped := min(max(score, 1), 10)
+	if clamped != score {
+		slog.Info("judge score out of range, clamped", "model", p.DisplayName(), "dimension", dimension, "score", score, "clamped", clamped)
+	}
+	return clamped
+}
+
+// applyJudgeScores attaches judge evaluations and link health to each result.
+func applyJudgeScores(results []ModelResult, evals []judgeEvaluation, allChecks map[string][]CitationCheck) {
+	matched := matchJudgeEvaluations(results, evals)
 
 	for i := range results {
 		if results[i].Result.Error != nil {
 			continue
 		}
 		p := results[i].Provider
-
-		// Try matching by display name first, then by provider name
-		eval, ok := evalMap[p.DisplayName()]
-		if !ok {
-			// Try fuzzy matching — the judge may have returned a slightly different name
-			for name, e := range evalMap {
-				if strings.Contains(strings.ToLower(name), strings.ToLower(p.Name())) ||
-					strings.Contains(strings.ToLower(p.DisplayName()), strings.ToLower(name)) {
-					eval = e
-					ok = true
-					break
-				}
-			}
-		}
+		eval, ok := matched[p.Name()]
 
 		lhScore := linkHealthScore(allChecks[p.Name()])
+		divScore := diversityScore(results[i].Result)
 
 		if ok {
-			overall := float64(eval.Quality)*0.25 +
-				float64(lhScore)*0.15 +
-				float64(eval.Recency)*0.20 +
-				float64(eval.Significance)*0.20 +
-				float64(eval.Impact)*0.20
+			overall := judgeWeights.Overall(eval.Quality, lhScore, divScore, eval.Recency, eval.Significance, eval.Impact)
 
 			results[i].JudgeScore = &JudgeScore{
 				Quality:      eval.Quality,
 				LinkHealth:   lhScore,
+				Diversity:    divScore,
 				Recency:      eval.Recency,
 				Significance: eval.Significance,
 				Impact:       eval.Impact,
@@ -332,23 +711,172 @@ func Judge(ctx context.Context, results []ModelResult, query string, verbose boo
 			// Fallback: assign link health score only
 			results[i].JudgeScore = &JudgeScore{
 				LinkHealth: lhScore,
+				Diversity:  divScore,
 				Overall:    float64(lhScore),
 				Reasoning:  "Judge did not return evaluation for this model",
 			}
 		}
 	}
+}
+
+// ClaimCluster is a factual claim with the models that support or contradict it.
+type ClaimCluster struct {
+	Claim    string   `json:"claim"`
+	Agree    []string `json:"agree"`    // Display names of models stating the claim
+	Disagree []string `json:"disagree"` // Display names of models contradicting it
+	Note     string   `json:"note"`     // What the disagreeing models say instead
+}
+
+// CompareClaims asks the judge model to extract key claims across all successful
+// responses and cluster them by which models agree or disagree.
+func CompareClaims(ctx context.Context, results []ModelResult, query string) ([]ClaimCluster, error) {
+	var b strings.Builder
+	b.WriteString("You are a fact-checker comparing answers from several AI models to the same question.\n\n")
+	b.WriteString(fmt.Sprintf("QUERY: %q\n\n", query))
+	b.WriteString("Extract the 5-12 most important factual claims across all answers. For each claim, list the models that state or clearly agree with it, ")
+	b.WriteString("and the models that contradict it (different numbers, dates, outcomes, or names). Omit models that simply don't mention it. ")
+	b.WriteString("A claim made by only one model with no contradiction is a unique claim. Use the exact model names given below.\n\n")
 
-	// Sort by Overall score descending
-	sort.SliceStable(results, func(i, j int) bool {
-		si, sj := 0.0, 0.0
-		if results[i].JudgeScore != nil {
-			si = results[i].JudgeScore.Overall
+	for _, mr := range results {
+		if mr.Result.Error != nil {
+			continue
 		}
-		if results[j].JudgeScore != nil {
-			sj = results[j].JudgeScore.Overall
+		text := stripThinkingTags(mr.Result.Text)
+		if words := strings.Fields(text); len(words) > 500 {
+			text = strings.Join(words[:500], " ") + "..."
 		}
-		return si > sj
-	})
+		b.WriteString(fmt.Sprintf("=== MODEL: %s ===\n%s\n===\n\n", mr.Provider.DisplayName(), text))
+	}
+	b.WriteString("Return the clusters using the cluster_claims tool.\n")
+
+	modelList := map[string]any{"type": "array", "items": map[string]any{"type": "string"}}
+	tool := anthropic.ToolParam{
+		Name:        "cluster_claims",
+		Description: anthropic.String("Report key claims and which models agree with or contradict each one."),
+		InputSchema: anthropic.ToolInputSchemaParam{
+			Properties: map[string]any{
+				"claims": map[string]any{
+					"type": "array",
+					"items": map[string]any{
+						"type": "object",
+						"properties": map[string]any{
+							"claim":    map[string]any{"type": "string"},
+							"agree":    modelList,
+							"disagree": modelList,
+							"note":     map[string]any{"type": "string"},
+						},
+						"required": []any{"claim", "agree", "disagree"},
+					},
+				},
+			},
+			Required: []string{"claims"},
+		},
+	}
 
-	return results, nil
+	slog.Debug("calling LLM judge for claim comparison", "model", judgeModelID)
+
+	var toolInput struct {
+		Claims []ClaimCluster `json:"claims"`
+	}
+	if err := judgeToolCall(ctx, "", b.String(), tool, &toolInput); err != nil {
+		return nil, err
+	}
+	return toolInput.Claims, nil
+}
+
+// SynthesizedAnswer is the judge's merged answer across all models, with the
+// deduplicated sources it cites.
+type SynthesizedAnswer struct {
+	Answer  string
+	Sources []SynthesizedSource
+}
+
+// SynthesizedSource is a source cited in a synthesized answer as [Number].
+type SynthesizedSource struct {
+	Number   int
+	Citation Citation
+}
+
+// Synthesize asks the judge model to merge all successful responses into one
+// best-of answer, favoring models with higher judge scores and facts that
+// several models agree on. Sources are pooled and deduplicated across models
+// and cited by number.
+func Synthesize(ctx context.Context, results []ModelResult, query string) (*SynthesizedAnswer, error) {
+	var pooled []Citation
+	for _, mr := range results {
+		if mr.Result.Error == nil {
+			pooled = append(pooled, mr.Result.Citations...)
+		}
+	}
+	sources := CollapseDuplicateStories(pooled)
+
+	var b strings.Builder
+	b.WriteString("You are an editor merging answers from several AI models to the same question into one answer.\n\n")
+	b.WriteString(fmt.Sprintf("QUERY: %q\n\n", query))
+	b.WriteString("Write the single best answer to the query using only information from the answers below. ")
+	b.WriteString("Prefer facts that several models agree on. When models conflict, follow the higher-scored models and mention the disagreement if it matters. ")
+	b.WriteString("Cite sources inline as [n] using the numbered source list; do not invent sources.\n\n")
+
+	for _, mr := range results {
+		if mr.Result.Error != nil {
+			continue
+		}
+		score := "unscored"
+		if mr.JudgeScore != nil {
+			score = fmt.Sprintf("judge score %.1f/10", mr.JudgeScore.Overall)
+		}
+		text := stripThinkingTags(mr.Result.Text)
+		if words := strings.Fields(text); len(words) > 800 {
+			text = strings.Join(words[:800], " ") + "..."
+		}
+		b.WriteString(fmt.Sprintf("=== MODEL: %s (%s) ===\n%s\n===\n\n", mr.Provider.DisplayName(), score, text))
+	}
+
+	if len(sources) > 0 {
+		b.WriteString("SOURCES:\n")
+		for i, c := range sources {
+			b.WriteString(fmt.Sprintf("[%d] %s - %s\n", i+1, c.Title, c.URL))
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("Return the answer and the numbers of the sources it cites using the synthesize_answer tool.\n")
+
+	tool := anthropic.ToolParam{
+		Name:        "synthesize_answer",
+		Description: anthropic.String("Report the merged answer and the source numbers it cites."),
+		InputSchema: anthropic.ToolInputSchemaParam{
+			Properties: map[string]any{
+				"answer":  map[string]any{"type": "string"},
+				"sources": map[string]any{"type": "array", "items": map[string]any{"type": "integer"}},
+			},
+			Required: []string{"answer", "sources"},
+		},
+	}
+
+	slog.Debug("calling LLM judge for synthesis", "model", judgeModelID, "sources", len(sources))
+
+	var toolInput struct {
+		Answer  string `json:"answer"`
+		Sources []int  `json:"sources"`
+	}
+	if err := judgeToolCall(ctx, "", b.String(), tool, &toolInput); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(toolInput.Answer) == "" {
+		return nil, fmt.Errorf("judge returned an empty synthesis")
+	}
+
+	// Keep the cited sources under their original numbers so [n] in the
+	// answer still matches; out-of-range numbers are dropped.
+	out := &SynthesizedAnswer{Answer: toolInput.Answer}
+	seen := make(map[int]bool)
+	for _, n := range toolInput.Sources {
+		if n < 1 || n > len(sources) || seen[n] {
+			continue
+		}
+		seen[n] = true
+		out.Sources = append(out.Sources, SynthesizedSource{Number: n, Citation: sources[n-1]})
+	}
+	sort.Slice(out.Sources, func(i, j int) bool { return out.Sources[i].Number < out.Sources[j].Number })
+	return out, nil
 }
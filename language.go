@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// responseLang is the ISO 639-1 code from -lang. English adds no instruction.
+var responseLang = "en"
+
+// languageNames maps supported -lang codes to the names used in the instruction.
+var languageNames = map[string]string{
+	"ar": "Arabic",
+	"de": "German",
+	"en": "English",
+	"es": "Spanish",
+	"fr": "French",
+	"hi": "Hindi",
+	"it": "Italian",
+	"ja": "Japanese",
+	"ko": "Korean",
+	"nl": "Dutch",
+	"pt": "Portuguese",
+	"ru": "Russian",
+	"zh": "Chinese",
+}
+
+// parseLanguage accepts a code ("fr") or English name ("French") and returns the code.
+func parseLanguage(s string) (string, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if _, ok := languageNames[s]; ok {
+		return s, nil
+	}
+	for code, name := range languageNames {
+		if strings.ToLower(name) == s {
+			return code, nil
+		}
+	}
+	codes := make([]string, 0, len(languageNames))
+	for code := range languageNames {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return "", fmt.Errorf("unsupported language %q (use one of: %s)", s, strings.Join(codes, ", "))
+}
+
+// localizeQuery appends a response-language instruction for non-English -lang.
+func localizeQuery(query string) string {
+	if responseLang == "en" {
+		return query
+	}
+	return query + "\n\nRespond in " + languageNames[responseLang] + "."
+}
+
+// scriptLanguages maps non-Latin scripts to the language they identify.
+// Han is checked after Kana and Hangul, since Japanese and Korean text contains Han.
+var scriptLanguages = []struct {
+	table *unicode.RangeTable
+	code  string
+}{
+	{unicode.Hiragana, "ja"},
+	{unicode.Katakana, "ja"},
+	{unicode.Hangul, "ko"},
+	{unicode.Han, "zh"},
+	{unicode.Cyrillic, "ru"},
+	{unicode.Arabic, "ar"},
+	{unicode.Devanagari, "hi"},
+}
+
+// stopwords are frequent function words that distinguish Latin-script languages.
+var stopwords = map[string][]string{
+	"en": {"the", "and", "of", "to", "is", "in", "that", "for", "with", "was", "are", "this"},
+	"fr": {"le", "la", "les", "et", "des", "est", "une", "dans", "pour", "que", "qui", "sur"},
+	"de": {"der", "die", "und", "das", "ist", "nicht", "mit", "den", "ein", "eine", "auf", "für"},
+	"es": {"el", "la", "los", "las", "y", "es", "una", "por", "para", "que", "con", "del"},
+	"it": {"il", "la", "che", "di", "è", "una", "per", "con", "sono", "della", "gli", "nel"},
+	"pt": {"o", "os", "as", "e", "é", "uma", "para", "com", "não", "que", "dos", "em"},
+	"nl": {"de", "het", "een", "en", "van", "is", "niet", "met", "voor", "op", "zijn", "dat"},
+}
+
+// minLanguageSignal is the fewest letters or stopword hits needed for a confident guess.
+const minLanguageSignal = 20
+
+// detectLanguage guesses the ISO 639-1 code of text, or "" when there's too little
+// signal. Non-Latin scripts are identified by character ranges; Latin-script
+// languages by stopword frequency. Citations and markdown don't skew it much,
+// since URLs contain few stopwords.
+func detectLanguage(text string) string {
+	scriptCounts := make(map[string]int)
+	letters := 0
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		for _, s := range scriptLanguages {
+			if unicode.Is(s.table, r) {
+				scriptCounts[s.code]++
+				break
+			}
+		}
+	}
+	if letters < minLanguageSignal {
+		return ""
+	}
+
+	// Kana anywhere means Japanese, even if Han characters dominate.
+	if scriptCounts["ja"] > 0 {
+		scriptCounts["ja"] += scriptCounts["zh"]
+		delete(scriptCounts, "zh")
+	}
+	for code, n := range scriptCounts {
+		if n*2 > letters {
+			return code
+		}
+	}
+
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	freq := make(map[string]int, len(words))
+	for _, w := range words {
+		freq[w]++
+	}
+
+	// Romance languages share many stopwords, so require a clear margin over the runner-up.
+	best, bestHits, secondHits := "", 0, 0
+	for code, list := range stopwords {
+		hits := 0
+		for _, w := range list {
+			hits += freq[w]
+		}
+		switch {
+		case hits > bestHits:
+			best, bestHits, secondHits = code, hits, bestHits
+		case hits > secondHits:
+			secondHits = hits
+		}
+	}
+	if bestHits < minLanguageSignal/4 || bestHits*4 < secondHits*5 {
+		return ""
+	}
+	return best
+}
+
+// languageName returns the display name for a code, or the code itself.
+func languageName(code string) string {
+	if name, ok := languageNames[code]; ok {
+		return name
+	}
+	return code
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// searchLocation is an approximate user location for localizing search results.
+type searchLocation struct {
+	City     string
+	Region   string
+	Country  string // ISO 3166-1 alpha-2, e.g. "US"
+	Timezone string // IANA name, e.g. "America/Los_Angeles"
+}
+
+// userLocation is the -location value; nil when not set.
+var userLocation *searchLocation
+
+// parseLocation parses "City, Region, CC" with any leading parts optional
+// ("US", "Paris, FR", "Austin, Texas, US"). A part containing "/" is taken as
+// an IANA timezone and may appear anywhere ("London, GB, Europe/London").
+func parseLocation(s string) (*searchLocation, error) {
+	loc := &searchLocation{}
+	var places []string
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		switch {
+		case part == "":
+		case strings.Contains(part, "/"):
+			if loc.Timezone != "" {
+				return nil, fmt.Errorf("invalid location %q: more than one timezone", s)
+			}
+			loc.Timezone = part
+		default:
+			places = append(places, part)
+		}
+	}
+
+	if n := len(places); n > 0 && len(places[n-1]) == 2 {
+		loc.Country = strings.ToUpper(places[n-1])
+		places = places[:n-1]
+	}
+	switch len(places) {
+	case 0:
+	case 1:
+		loc.City = places[0]
+	case 2:
+		loc.City, loc.Region = places[0], places[1]
+	default:
+		return nil, fmt.Errorf("invalid location %q (want \"City, Region, CC\", e.g. \"Austin, Texas, US\")", s)
+	}
+
+	if *loc == (searchLocation{}) {
+		return nil, fmt.Errorf("invalid location %q: empty", s)
+	}
+	return loc, nil
+}
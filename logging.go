@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+)
+
+// setupLogging installs the default slog logger. Logs go to logFile when set so stdout
+// stays clean for the formatted comparison, otherwise to stderr. When level is empty,
+// it defaults to debug under -vv, info under -v or for a log file, and warn for stderr.
+// The returned close function releases the log file, if any.
+func setupLogging(logFile, level string, v Verbosity) (func() error, error) {
+	var w io.Writer = os.Stderr
+	closeFn := func() error { return nil }
+
+	if logFile != "" {
+		f, err := os.OpenFile(logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("open log file: %w", err)
+		}
+		w, closeFn = f, f.Close
+	}
+
+	var lvl slog.Level
+	switch {
+	case level != "":
+		if err := lvl.UnmarshalText([]byte(level)); err != nil {
+			return nil, fmt.Errorf("invalid -log-level %q (use debug, info, warn, error)", level)
+		}
+	case v >= VerbosityDebug:
+		lvl = slog.LevelDebug
+	case v >= VerbosityVerbose, logFile != "":
+		lvl = slog.LevelInfo
+	default:
+		lvl = slog.LevelWarn
+	}
+
+	slog.SetDefault(slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{Level: lvl})))
+	return closeFn, nil
+}
+
+// logProviderResult records a provider's timing and outcome.
+func logProviderResult(p Provider, r Result) {
+	if r.Error != nil {
+		slog.Info("provider failed", "provider", p.Name(), "duration", r.Duration, "error", r.Error)
+		return
+	}
+	slog.Info("provider finished", "provider", p.Name(), "duration", r.Duration,
+		"citations", len(r.Citations), "input_tokens", r.Tokens.Input, "output_tokens", r.Tokens.Output)
+}
@@ -2,17 +2,34 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"log/slog"
+	"net"
 	"os"
+	"os/signal"
+	"slices"
 	"strings"
-	"sync"
+	"syscall"
+	"time"
 )
 
 // Global flags
 var (
 	showThinking bool
-	verbose      bool
+	skipJudge    bool
+	budget       float64
+	providerList []string // Explicit subset from -providers; overrides -model
+	saveHTML     string
+	compareDiff  bool
+	synthesize   bool           // -synthesize: judge merges all answers into one
+	reasoning    = ReasoningOff // -reasoning effort, mapped per provider
+	minCitations int            // Re-prompt once when a provider cites fewer sources
+	benchmarkN   int            // -benchmark runs per provider, including warmup
+	repeatN      int            // -repeat runs per provider for the stability report
+	maxSearches  int            // Cap on Claude web searches per query (0 = API default)
 )
 
 func main() {
@@ -35,13 +52,24 @@ MODELS:
   claude   Claude 4.5 Sonnet with Anthropic web_search tool
   gemini   Gemini 3 Pro with Google Search grounding
   grok     Grok 4 with xAI web search
+  cohere   Cohere Command R+ with the web-search connector
+  ollama   Local Ollama model with a web_search tool (no API cost)
+  mistral  Mistral Medium with the built-in web_search tool
   all      Run all available models in parallel (default)
 
 ENVIRONMENT VARIABLES:
   AWS credentials      Required for Nova (via ~/.aws/credentials or env vars)
-  ANTHROPIC_API_KEY    Required for Claude
+  ANTHROPIC_API_KEY    Required for Claude and the judge (or -anthropic-api-key-file)
+  ANTHROPIC_BASE_URL   Optional Anthropic API base (proxy/gateway) for Claude and the judge
   GOOGLE_API_KEY       Required for Gemini
   XAI_API_KEY          Required for Grok
+  NO_COLOR             Disable colored output (same as -no-color)
+  XAI_BASE_URL         Optional xAI API base (proxy/gateway); /responses is appended
+  COHERE_API_KEY       Required for Cohere
+  MISTRAL_API_KEY      Required for Mistral
+  OLLAMA_HOST          Ollama server (default http://localhost:11434)
+  OLLAMA_MODEL         Local model name (default llama3.1)
+  OLLAMA_SEARCH_URL    Search endpoint for Ollama web_search tool calls (POST {"query"})
 
 EXAMPLES:
   # Compare all models (default)
@@ -50,74 +78,682 @@ EXAMPLES:
   # Run single model
   web-search -model claude -q "Current Bitcoin price"
 
+  # Compare a subset of models
+  web-search -providers nova,claude -q "Current Bitcoin price"
+
   # Verbose output with timing details
   web-search -v -q "Latest SpaceX launches"
 
   # Show model thinking/reasoning traces
   web-search -thinking -q "Who won the Super Bowl?"
 
+  # More reasoning (Claude thinking budget, Gemini thinking level); shown with -thinking
+  web-search -reasoning high -thinking -q "Why did the Fed hold rates?"
+
+  # Latency benchmark: 6 runs per provider, first discarded as warmup
+  web-search -benchmark 6 -q "What is the capital of France?"
+
+  # Structured JSON answers validated against a schema
+  web-search -answer-schema answer.json -q "Top 3 AI funding rounds this week"
+  web-search -image product.jpg -q "What's trending about this product?"
+
+  # Breaking news: weight recency heavily in the judge's overall score
+  web-search -judge-weights recency=0.5 -q "What just happened in markets?"
+
+  # Trust-scoped research: restrict or exclude source domains
+  web-search -allow-domains reuters.com,apnews.com -q "Election results"
+  web-search -block-domains reddit.com,x.com -q "Best mechanical keyboards"
+
+  # Only recent sources (native filter on Gemini, prompt instruction elsewhere)
+  web-search -since 7d -q "Latest OpenAI announcements"
+
+  # Answer in French; warns if a model replies in another language
+  web-search -lang fr -q "Quelles sont les dernières nouvelles sur l'IA ?"
+
+  # Read the question from a pipe or heredoc
+  echo "What is the latest Rust release?" | web-search -query-stdin
+
+  # Inspect request bodies without calling any API
+  web-search -dry-run -q "test"
+
+  # Shareable HTML report
+  web-search -save-html report.html -q "Latest SpaceX launches"
+
+  # Snapshot cited pages for later verification
+  web-search -archive -archive-dir ./evidence -q "Latest SpaceX launches"
+
+  # Cap worst-case spend; cheapest providers run first
+  web-search -budget 0.05 -q "Latest SpaceX launches"
+
+  # Overnight batch, one JSON line per query as it finishes (tail -f to follow)
+  web-search -queries-file queries.txt -jsonl-out results.jsonl
+  web-search -compare-to baseline.json
+
+  # Just the best answer from the panel, as JSON
+  web-search -quiet -format json -q "Latest SpaceX launches"
+
+  # Interactive session (\model, \judge, \quit)
+  web-search -repl -model claude
+
 `)
 	}
 
+	configPath := flag.String("config", "", "YAML config with flag defaults (default ~/.web-search.yaml if present); command-line flags win")
 	query := flag.String("q", "", "Question to ask (required)")
-	model := flag.String("model", "all", "Model to use: nova, claude, gemini, grok, or all")
-	thinking := flag.Bool("thinking", false, "Show model's thinking/reasoning traces")
-	verboseFlag := flag.Bool("v", false, "Enable verbose output with timing details")
+	model := flag.String("model", "all", "Model to use: nova, claude, gemini, grok, cohere, ollama, mistral, or all")
+	providersFlag := flag.String("providers", "", "Comma-separated subset of models to compare (e.g. nova,claude)")
+	sameProviderModels := flag.String("compare-models-same-provider", "", "Compare models of one provider side by side: comma-separated provider:label=model entries (e.g. claude:opus=claude-opus-4-1,claude:sonnet=claude-sonnet-4-5), added to the run as provider:label")
+	providersFile := flag.String("providers-file", "", "YAML file defining extra OpenAI-compatible providers (name, base_url, model, api_key_env, api, web_search); see README")
+	thinking := flag.Bool("thinking", false, "Show model reasoning traces in a 🧠 Reasoning section")
+	verboseFlag := flag.Bool("v", false, "Verbose output: timing, progress, and info logs to stderr")
+	debugFlag := flag.Bool("vv", false, "Debug output: -v plus each model's search queries and raw tool calls (debug logs)")
+	logFile := flag.String("log-file", "", "Write logs to this file instead of stderr")
+	logLevel := flag.String("log-level", "", "Log level: debug, info, warn, error (default warn, info with -log-file or -v, debug with -vv)")
+	allowList := flag.String("allow-domains", "", "Comma-separated domains to restrict sources to (native on Claude, prompt elsewhere; citations post-filtered)")
+	blockList := flag.String("block-domains", "", "Comma-separated domains to exclude from sources (native on Claude, prompt elsewhere; citations post-filtered)")
+	location := flag.String("location", "", "Approximate location for Claude's search results: \"City, Region, CC\" (e.g. \"Austin, Texas, US\"), optional IANA timezone")
+	flag.IntVar(&maxSearches, "max-searches", 0, "Max web searches Claude may run per query (0 = API default; caps search spend)")
+	since := flag.String("since", "", "Prefer sources within a window: 24h, 7d, 2w, 3m, or a date (2025-01-15)")
+	noColor := flag.Bool("no-color", false, "Disable ANSI colors (also NO_COLOR env var; off automatically when not a TTY)")
+	flag.BoolVar(&renderMarkdown, "render", false, "Render markdown in answers (bold, italic, headers, links as text (url)); off with -no-color or when not a TTY")
+	flag.IntVar(&outputWidth, "width", 0, "Wrap response text to N columns (default: terminal width; no wrapping when not a TTY)")
+	system := flag.String("system", "", "System prompt sent to every provider (e.g. \"You are a financial news analyst; prioritize primary sources\")")
+	systemFile := flag.String("system-file", "", "Read the system prompt from this file")
+	lang := flag.String("lang", "en", "Response language code or name (e.g. fr, French); non-English adds a \"Respond in ...\" instruction")
+	queryStdin := flag.Bool("query-stdin", false, "Read the question from stdin instead of -q (for pipes and heredocs)")
+	repl := flag.Bool("repl", false, "Interactive mode: read queries from stdin until \\quit")
+	queriesFile := flag.String("queries-file", "", "Run every query in this file (one per line, # comments) in sequence")
+	flag.StringVar(&metricsFile, "metrics-file", "", "Write per-provider latency, tokens, cost, errors, and judge score as Prometheus textfile gauges to this file after the run")
+	flag.StringVar(&statsdAddr, "statsd", "", "Send per-provider latency, tokens, cost, errors, and judge score to this StatsD host:port over UDP after the run")
+	flag.StringVar(&jsonlOut, "jsonl-out", "", "Append one JSON line per completed query (query, results, judge scores) to this file")
+	flag.StringVar(&compareTo, "compare-to", "", "Diff this run against a saved one (-format json or -jsonl-out file): citation, word count, and score changes per provider. -q defaults to the saved query")
+	flag.DurationVar(&queryTimeout, "timeout", 0, "Max time per provider query, overriding each provider's default (2m; Nova and Grok 4m, Ollama 5m)")
+	flag.DurationVar(&warnSlow, "warn-slow", 0, "Flag providers that take longer than this (e.g. 30s) in the summary; 0 = off")
+	flag.DurationVar(&linkCheckTimeout, "linkcheck-timeout", linkCheckTimeout, "Per-link timeout for citation HEAD checks")
+	flag.IntVar(&linkCheckConcurrency, "linkcheck-concurrency", linkCheckConcurrency, "Max citation HEAD checks in flight at once")
+	noJudge := flag.Bool("no-judge", false, "Skip link validation and LLM judging (links are still checked for -sort overall)")
+	flag.BoolVar(&compareDiff, "compare-diff", false, "Extract claims and show where models agree, contradict, or are unique (extra judge call)")
+	flag.BoolVar(&synthesize, "synthesize", false, "Have the judge merge all answers into one best-of answer with combined sources (extra judge call)")
+	flag.BoolVar(&allowUngroundedFallback, "allow-ungrounded-fallback", false, "If Gemini's Google Search grounding fails (e.g. grounded-prompt quota), retry once without search and mark the answer ungrounded")
+	flag.StringVar(&novaModelARN, "nova-model-arn", "", "Nova model to invoke: inference profile or provisioned-throughput ARN, or a model ID (default "+novaModelID+")")
+	flag.StringVar(&anthropicBaseURL, "anthropic-base-url", "", "Override the Anthropic API base URL for Claude and the judge (default $ANTHROPIC_BASE_URL or https://api.anthropic.com)")
+	anthropicKeyFile := flag.String("anthropic-api-key-file", "", "Read the Anthropic API key for Claude and the judge from this file (overrides $ANTHROPIC_API_KEY)")
+	flag.StringVar(&xaiBaseURL, "xai-base-url", "", "Override the xAI API base URL (default $XAI_BASE_URL or https://api.x.ai/v1)")
+	flag.StringVar(&reasoning, "reasoning", ReasoningOff, "Extended thinking / reasoning effort: off, low, medium, high (raises token cost)")
+	flag.BoolVar(&explainScores, "explain-scores", false, "After ranking, show each model's weighted score breakdown, full judge reasoning, and failed links")
+	trustFile := flag.String("trust-file", "", "YAML map of trust tier (trusted, gov, edu, news) to domains, extending the built-in source trust list")
+	rubricFile := flag.String("judge-rubric", "", "Replace the judge's news-editor persona and dimension descriptions with this file's text (must describe quality, recency, significance, impact)")
+	weights := flag.String("judge-weights", "", "Override judge weights, e.g. recency=0.5,quality=0.2 (keys: quality, links, diversity, recency, significance, impact; normalized to sum to 1)")
+	flag.StringVar(&sortBy, "sort", SortOverall, "Rank by: overall, quality, recency, cost, speed, citations (overall uses link health without the judge)")
+	flag.IntVar(&maxCitationsDisplay, "max-citations-display", maxCitationsDisplay, "Show at most N sources per answer in the terminal, then \"... and M more\" (0 = all; exports keep every citation)")
+	flag.BoolVar(&cleanURLs, "clean-urls", false, "Strip tracking and affiliate parameters (utm_*, fbclid, gclid, ref, ...) from printed and exported source URLs; checks and dedup still use the original URL")
+	flag.BoolVar(&fastest, "fastest", false, "Race all providers and print only the first successful answer, canceling the rest; skips the judge")
+	flag.BoolVar(&reverseRank, "reverse", false, "Rank worst first and flag the bottom performer as needing improvement instead of naming a winner (-quiet then prints the worst answer)")
+	flag.BoolVar(&pinOrder, "pin-order", false, "Keep providers in a fixed (registry) display order; ranks and medals are still shown")
+	flag.BoolVar(&dedupeDomainScore, "dedupe-domain-score", false, "Count at most one citation per domain toward scores (link health, -sort citations, judge); all citations are still shown")
+	flag.IntVar(&minCitations, "min-citations", 0, "Re-prompt a provider once if it returns fewer than N citations (0 = off)")
+	flag.IntVar(&benchmarkN, "benchmark", 0, "Run the query N times per provider (first is warmup) and report latency stats; skips the judge")
+	flag.IntVar(&repeatN, "repeat", 0, "Run the query N times per provider and report answer stability (citation and text similarity); skips the judge")
+	var images imagePaths
+	flag.Var(&images, "image", "Attach an image file (PNG, JPEG, GIF, WebP) to the question; repeatable. Claude, Gemini, Grok, and Nova only; others error")
+	schemaFile := flag.String("answer-schema", "", "JSON schema file; supporting providers (Claude, Gemini, Grok) return validated JSON, others are skipped")
+	flag.BoolVar(&citationsOnly, "citations-only", false, "Print only a merged, domain-grouped list of every cited source and which models found it (no answers, no judge)")
+	flag.BoolVar(&quiet, "quiet", false, "Print only the top-ranked answer and its sources (best answer from the panel)")
+	flag.StringVar(&outputFormat, "format", FormatText, "Output format: text or json (json prints the ranked results, or just the winner with -quiet)")
+	capabilities := flag.Bool("capabilities", false, "Print which features (system prompt, domain/recency filters, schema, reasoning, ...) each provider supports, then exit")
+	estimate := flag.Bool("estimate", false, "Print each provider's projected cost for the query (tokenizer counts where available) without calling any model")
+	failIgnore := flag.String("fail-ignore", "", "Error kinds that don't trip -fail-on-error, comma-separated: auth, rate_limit, timeout, network, parse, empty, other")
+	flag.BoolVar(&failOnError, "fail-on-error", false, "Exit 2 if any provider errors, 3 if every provider is skipped (missing credentials); for CI health checks")
+	flag.BoolVar(&dryRun, "dry-run", false, "Print each provider's request instead of calling the API")
+	flag.BoolVar(&cacheResponses, "cache-responses", false, "Cache successful answers on disk by provider, model, and query; repeat queries are answered from the cache at no cost")
+	flag.DurationVar(&cacheTTL, "cache-ttl", defaultCacheTTL, "How long -cache-responses reuses an answer")
+	flag.BoolVar(&cacheRefresh, "refresh", false, "With -cache-responses, ignore cached answers and store fresh ones")
+	flag.BoolVar(&includeRaw, "include-raw", false, "Attach each provider's raw response under \"raw\" in -format json and -jsonl-out records")
+	flag.StringVar(&dumpDir, "dump-dir", "", "Write each provider's raw request and response to DIR as <provider>-request.json / <provider>-response.json")
+	flag.StringVar(&snapshotPath, "snapshot-query-results", "", "Bundle the query, flags, raw and parsed results, link checks, judge prompt and response, ranking, and HTML report into this directory (or .zip) for sharing")
+	flag.StringVar(&saveHTML, "save-html", "", "Write an HTML report with clickable sources to this path")
+	flag.BoolVar(&archiveEnabled, "archive", false, "Save the HTML of each healthy cited page, with a manifest.json, into a timestamped directory")
+	flag.StringVar(&archiveDir, "archive-dir", archiveDir, "Parent directory for -archive snapshots")
+	flag.Float64Var(&budget, "budget", 0, "Max estimated spend in USD per query (0 = unlimited)")
+	maxTokens := flag.String("max-token-estimate", "", "Override worst-case token usage for -budget and -estimate, e.g. claude=60000:4096,gemini=8000:8192 (provider=input:output)")
 	flag.Parse()
 
-	showThinking = *thinking || *verboseFlag
-	verbose = *verboseFlag
+	if err := applyConfigFile(*configPath); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	showThinking = *thinking
+	switch {
+	case *debugFlag:
+		verbosity = VerbosityDebug
+	case *verboseFlag:
+		verbosity = VerbosityVerbose
+	}
+	skipJudge = *noJudge
+
+	setupColor(*noColor)
+
+	closeLog, err := setupLogging(*logFile, *logLevel, verbosity)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	defer closeLog()
+
+	if *providersFile != "" {
+		if err := loadProvidersFile(*providersFile); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: -providers-file: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	var variantNames []string
+	if *sameProviderModels != "" {
+		if variantNames, err = registerModelVariants(*sameProviderModels); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: -compare-models-same-provider: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if err := applyMaxTokenEstimates(maxTokenEstimateConfig); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: config max_token_estimate: %v\n", err)
+		os.Exit(1)
+	}
+	if err := applyMaxTokenEstimates(*maxTokens); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: -max-token-estimate: %v\n", err)
+		os.Exit(1)
+	}
 
-	if *query == "" {
+	if *queryStdin {
+		if *query != "" {
+			fmt.Fprintln(os.Stderr, "Error: use either -q or -query-stdin, not both.")
+			os.Exit(1)
+		}
+		if *repl {
+			fmt.Fprintln(os.Stderr, "Error: -query-stdin cannot be combined with -repl.")
+			os.Exit(1)
+		}
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: reading query from stdin: %v\n", err)
+			os.Exit(1)
+		}
+		*query = strings.TrimRight(string(data), "\r\n")
+		if strings.TrimSpace(*query) == "" {
+			fmt.Fprintln(os.Stderr, "Error: -query-stdin read an empty query.")
+			os.Exit(1)
+		}
+	}
+
+	if *capabilities {
+		printCapabilities(selectedProviders(*model))
+		return
+	}
+
+	var queries []string
+	if *queriesFile != "" {
+		if *query != "" || *queryStdin || *repl {
+			fmt.Fprintln(os.Stderr, "Error: -queries-file cannot be combined with -q, -query-stdin, or -repl.")
+			os.Exit(1)
+		}
+		if *estimate || benchmarkN > 0 || repeatN > 0 {
+			fmt.Fprintln(os.Stderr, "Error: -queries-file cannot be combined with -estimate, -benchmark, or -repeat.")
+			os.Exit(1)
+		}
+		if queries, err = loadQueries(*queriesFile); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	var baseline *jsonQueryRecord
+	if compareTo != "" {
+		if *repl || len(queries) > 0 || *estimate || benchmarkN > 0 || repeatN > 0 || citationsOnly {
+			fmt.Fprintln(os.Stderr, "Error: -compare-to applies to a single comparison run; it can't be combined with -repl, -queries-file, -estimate, -benchmark, -repeat, or -citations-only.")
+			os.Exit(1)
+		}
+		if baseline, err = loadBaseline(compareTo, *query); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: -compare-to: %v\n", err)
+			os.Exit(1)
+		}
+		*query = baseline.Query
+	}
+
+	if *query == "" && !*repl && len(queries) == 0 {
 		fmt.Fprintln(os.Stderr, "Error: -q flag is required. Use -h for help.")
 		os.Exit(1)
 	}
 
+	switch reasoning {
+	case ReasoningOff, ReasoningLow, ReasoningMedium, ReasoningHigh:
+	default:
+		fmt.Fprintf(os.Stderr, "Error: invalid -reasoning %q (use off, low, medium, high)\n", reasoning)
+		os.Exit(1)
+	}
+
+	if *weights != "" {
+		if judgeWeights, err = parseJudgeWeights(*weights, judgeWeights); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: -judge-weights: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if failIgnoreKinds, err = parseErrorKinds(*failIgnore); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: -fail-ignore: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *trustFile != "" {
+		if err := loadTrustFile(*trustFile); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: -trust-file: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if *rubricFile != "" {
+		if judgeRubric, err = loadJudgeRubric(*rubricFile); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: -judge-rubric: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if err := validateSortMode(sortBy); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if allowDomains, err = parseDomainList(*allowList); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: -allow-domains: %v\n", err)
+		os.Exit(1)
+	}
+	if blockDomains, err = parseDomainList(*blockList); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: -block-domains: %v\n", err)
+		os.Exit(1)
+	}
+
+	if queryTimeout < 0 || warnSlow < 0 {
+		fmt.Fprintln(os.Stderr, "Error: -timeout and -warn-slow must be >= 0")
+		os.Exit(1)
+	}
+	if linkCheckTimeout <= 0 || linkCheckConcurrency < 1 {
+		fmt.Fprintln(os.Stderr, "Error: -linkcheck-timeout must be > 0 and -linkcheck-concurrency >= 1")
+		os.Exit(1)
+	}
+
+	if maxSearches < 0 {
+		fmt.Fprintln(os.Stderr, "Error: -max-searches must be >= 0")
+		os.Exit(1)
+	}
+	if *location != "" {
+		if userLocation, err = parseLocation(*location); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: -location: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if *since != "" {
+		sinceTime, err = parseSince(*since, time.Now())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if systemPrompt, err = loadSystemPrompt(*system, *systemFile); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	responseLang, err = parseLanguage(*lang)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *anthropicKeyFile != "" {
+		if anthropicAPIKey, err = loadAnthropicKey(*anthropicKeyFile); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: -anthropic-api-key-file: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if *schemaFile != "" {
+		answerSchema, err = loadAnswerSchema(*schemaFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if len(images) > 0 {
+		queryImages, err = loadImages(images)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: -image: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if err := prepareDumpDir(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: -dump-dir: %v\n", err)
+		os.Exit(1)
+	}
+
+	if _, err := resolveNovaTarget(novaModelARN); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: -nova-model-arn: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := validateBaseURLs(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if outputFormat != FormatText && outputFormat != FormatJSON {
+		fmt.Fprintf(os.Stderr, "Error: invalid -format %q (use text or json)\n", outputFormat)
+		os.Exit(1)
+	}
+	if citationsOnly && (*repl || len(queries) > 0 || *estimate || benchmarkN > 0 || repeatN > 0 || reportSuppressed()) {
+		fmt.Fprintln(os.Stderr, "Error: -citations-only applies to a single query and can't be combined with -quiet or -format json.")
+		os.Exit(1)
+	}
+	if snapshotPath != "" && (*repl || len(queries) > 0 || *estimate || benchmarkN > 0 || repeatN > 0 || citationsOnly || dryRun) {
+		fmt.Fprintln(os.Stderr, "Error: -snapshot-query-results bundles a single comparison run; it can't be combined with -repl, -queries-file, -estimate, -benchmark, -repeat, -citations-only, or -dry-run.")
+		os.Exit(1)
+	}
+	if fastest && (*repl || *estimate || benchmarkN > 0 || repeatN > 0 || citationsOnly || compareDiff || synthesize || explainScores) {
+		fmt.Fprintln(os.Stderr, "Error: -fastest keeps a single answer; it can't be combined with -repl, -estimate, -benchmark, -repeat, -citations-only, -compare-diff, -synthesize, or -explain-scores.")
+		os.Exit(1)
+	}
+	if fastest {
+		skipJudge = true
+	}
+	if (metricsFile != "" || statsdAddr != "") && (*repl || *estimate || benchmarkN > 0 || repeatN > 0 || citationsOnly || dryRun) {
+		fmt.Fprintln(os.Stderr, "Error: -metrics-file and -statsd report comparison runs; they can't be combined with -repl, -estimate, -benchmark, -repeat, -citations-only, or -dry-run.")
+		os.Exit(1)
+	}
+	if statsdAddr != "" {
+		if _, err := net.ResolveUDPAddr("udp", statsdAddr); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid -statsd address %q: %v\n", statsdAddr, err)
+			os.Exit(1)
+		}
+	}
+	if includeRaw && outputFormat != FormatJSON && jsonlOut == "" {
+		fmt.Fprintln(os.Stderr, "Error: -include-raw applies to JSON output; use it with -format json or -jsonl-out.")
+		os.Exit(1)
+	}
+	if reportSuppressed() && (*repl || len(queries) > 0 || *estimate || benchmarkN > 0 || repeatN > 0) {
+		fmt.Fprintln(os.Stderr, "Error: -quiet and -format json apply to a single query; use -jsonl-out for batches.")
+		os.Exit(1)
+	}
+
+	if *providersFlag != "" {
+		names, err := parseProviderList(*providersFlag)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		providerList = names
+	}
+	// Variants run alone, or alongside an explicit -providers list.
+	for _, name := range variantNames {
+		if !slices.Contains(providerList, name) {
+			providerList = append(providerList, name)
+		}
+	}
+
+	var jsonl *jsonlWriter
+	if jsonlOut != "" {
+		if jsonl, err = openJSONL(jsonlOut); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: -jsonl-out: %v\n", err)
+			os.Exit(1)
+		}
+		defer jsonl.Close()
+	}
+
+	var restoreStdout func()
+	if reportSuppressed() {
+		restoreStdout = silenceStdout()
+	}
+
 	printHeader()
-	fmt.Printf("📝 Query: %s\n\n", *query)
 
 	ctx := context.Background()
 
-	if *model == "all" {
-		runAllModels(ctx, *query)
-	} else {
-		runSingleModel(ctx, *model, *query)
+	if *repl {
+		runREPL(ctx, *model)
+		return
+	}
+
+	if len(queries) > 0 {
+		all := runBatch(ctx, *model, queries, jsonl)
+		emitMetrics(all)
+		exitOnProviderErrors(all)
+		return
+	}
+
+	fmt.Printf("📝 Query: %s\n\n", *query)
+
+	if *estimate {
+		runEstimate(ctx, selectedProviders(*model), *query)
+		return
+	}
+
+	if citationsOnly {
+		skipJudge = true
+		exitOnProviderErrors(runCitationsOnly(ctx, selectedProviders(*model), *query))
+		return
+	}
+
+	if benchmarkN > 0 && repeatN > 0 {
+		fmt.Fprintln(os.Stderr, "Error: use either -benchmark or -repeat, not both.")
+		os.Exit(1)
 	}
+	if benchmarkN > 0 {
+		if len(queryImages) > 0 {
+			fmt.Fprintln(os.Stderr, "Error: -image is not supported with -benchmark")
+			os.Exit(1)
+		}
+		if benchmarkN < 2 {
+			fmt.Fprintln(os.Stderr, "Error: -benchmark needs at least 2 runs (the first is warmup)")
+			os.Exit(1)
+		}
+		skipJudge = true
+		runBenchmark(ctx, selectedProviders(*model), *query, benchmarkN)
+		return
+	}
+	if repeatN > 0 {
+		if repeatN < 2 {
+			fmt.Fprintln(os.Stderr, "Error: -repeat needs at least 2 runs to compare")
+			os.Exit(1)
+		}
+		skipJudge = true
+		runRepeat(ctx, selectedProviders(*model), *query, repeatN)
+		return
+	}
+
+	results, err := runQuery(ctx, *model, *query)
+	exitOnError(err)
+	appendJSONL(jsonl, *query, results)
+	emitMetrics(results)
+	if baseline != nil {
+		printBaselineDiff(compareTo, *baseline, diffBaseline(*baseline, newJSONQueryRecord(*query, results)))
+	}
+	if restoreStdout != nil {
+		restoreStdout()
+		if err := printFinalOutput(*query, results); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	exitOnProviderErrors(results)
 }
 
-func runAllModels(ctx context.Context, query string) {
-	// Pre-flight auth check
-	var available []Provider
-	var skipped []string
+// selectedProviders resolves -providers or -model to provider names, exiting on
+// an unknown model.
+func selectedProviders(model string) []string {
+	switch {
+	case len(providerList) > 0:
+		return providerList
+	case model == "all":
+		return All()
+	}
+	if _, ok := Get(model); !ok {
+		fmt.Fprintf(os.Stderr, "Error: unknown model %q (available: all, %s)\n", model, strings.Join(All(), ", "))
+		os.Exit(1)
+	}
+	return []string{model}
+}
 
+// runQuery dispatches a query to the -providers subset, all models, or a single
+// named model, and returns the ranked results. It returns an *exitError, before
+// any provider is queried, when none can run.
+func runQuery(ctx context.Context, model, query string) ([]ModelResult, error) {
+	var names []string
+	switch {
+	case len(providerList) > 0:
+		names = providerList
+	case model == "all":
+		names = All()
+	default:
+		return runSingleModel(ctx, model, query)
+	}
+	if fastest {
+		return runFastest(ctx, names, query)
+	}
+	return runAllModels(ctx, names, query)
+}
+
+// isSingleModel reports whether -model names one provider rather than -providers or "all".
+func isSingleModel(model string) bool {
+	return len(providerList) == 0 && model != "all"
+}
+
+// collectQuery queries the same providers as runQuery but leaves judging and
+// printing the answers to the caller, so runBatch can judge every query in one
+// JudgeBatch call. Reports whether Ctrl-C interrupted the query.
+func collectQuery(ctx context.Context, model, query string) ([]ModelResult, bool, error) {
+	if isSingleModel(model) {
+		mr, err := querySingleModel(ctx, model, query)
+		if err != nil {
+			return nil, false, err
+		}
+		return []ModelResult{mr}, false, nil
+	}
+	queryCtx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return queryAllModels(queryCtx, stop, selectedProviders(model), query)
+}
+
+// validateBaseURLs checks every overridable provider base URL before any request is sent.
+func validateBaseURLs() error {
 	for _, name := range All() {
 		p, _ := Get(name)
-		if err := p.CheckAuth(); err != nil {
-			skipped = append(skipped, fmt.Sprintf("%s %s: %s", p.Emoji(), p.DisplayName(), err.Error()))
-		} else {
-			available = append(available, p)
+		bp, ok := p.(BaseURLProvider)
+		if !ok {
+			continue
+		}
+		if err := validateBaseURL(bp.BaseURL()); err != nil {
+			return fmt.Errorf("invalid %s base URL %q: %v", name, bp.BaseURL(), err)
 		}
 	}
+	return nil
+}
+
+// parseProviderList splits a comma-separated provider list and validates each name
+// against the registry, dropping duplicates. Every unknown name is reported at once.
+func parseProviderList(list string) ([]string, error) {
+	var names, unknown []string
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		if _, ok := Get(name); !ok {
+			unknown = append(unknown, name)
+			continue
+		}
+		names = append(names, name)
+	}
+	switch len(unknown) {
+	case 0:
+	case 1:
+		return nil, fmt.Errorf("unknown provider %q (available: %s)", unknown[0], strings.Join(All(), ", "))
+	default:
+		return nil, fmt.Errorf("unknown providers %s (available: %s)", strings.Join(unknown, ", "), strings.Join(All(), ", "))
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("-providers is empty (available: %s)", strings.Join(All(), ", "))
+	}
+	return names, nil
+}
 
-	printSkippedProviders(skipped)
+// runnableProviders runs the pre-flight auth check and -budget selection for
+// names, printing both, and returns an *exitError when no provider can run.
+func runnableProviders(names []string) ([]Provider, error) {
+	available, statuses := checkProviders(names)
+	printAuthTable(statuses)
+	warnUnsupportedFlags(available)
 
 	if len(available) == 0 {
-		fmt.Println("❌ No providers available. Set at least one API key.")
-		os.Exit(1)
+		return nil, noProvidersError(statuses)
+	}
+
+	available, dropped, projected := applyBudget(available, budget)
+	printBudgetDropped(dropped, budget)
+	if len(available) == 0 {
+		return nil, &exitError{Code: 1, Err: fmt.Errorf("Budget $%.4f is too low for any provider.", budget)}
+	}
+	if budget > 0 {
+		slog.Debug("budget check", "projected", projected, "budget", budget)
+	}
+	return available, nil
+}
+
+func runAllModels(ctx context.Context, names []string, query string) ([]ModelResult, error) {
+	// Ctrl-C cancels in-flight queries; whatever finished is still shown.
+	queryCtx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	modelResults, interrupted, err := queryAllModels(queryCtx, stop, names, query)
+	if err != nil {
+		return nil, err
+	}
+	if !interrupted && !skipJudge {
+		// Ctrl-C while judging cancels link checks and the judge call; results
+		// are still shown, ranked without judge scores.
+		printJudgingBanner("⚖️  Judging results...")
+		var err error
+		if modelResults, err = Judge(queryCtx, modelResults, query); err != nil {
+			printJudgeError(err)
+		}
+	}
+	presentResults(ctx, modelResults, query, interrupted)
+	return modelResults, nil
+}
+
+// queryAllModels runs query against every runnable provider in names in
+// parallel and collects the results, unjudged and unranked. ctx is canceled by
+// Ctrl-C; stop releases the signal handler so a second Ctrl-C exits.
+func queryAllModels(ctx context.Context, stop func(), names []string, query string) ([]ModelResult, bool, error) {
+	available, err := runnableProviders(names)
+	if err != nil {
+		return nil, false, err
 	}
 
 	fmt.Printf("🚀 Running query against %d models in parallel...\n", len(available))
 	fmt.Println(strings.Repeat("═", 65))
 	fmt.Println()
 
-	var wg sync.WaitGroup
 	results := make(chan ModelResult, len(available))
+	progress := newProgressBoard(available)
 
 	for _, p := range available {
-		wg.Add(1)
 		go func(provider Provider) {
-			defer wg.Done()
-			r := provider.Query(ctx, query, verbose)
+			progress.Start(provider)
+			r := queryProvider(ctx, provider, query)
+			logProviderResult(provider, r)
+			progress.Finish(provider, r)
 			results <- ModelResult{
 				Provider: provider,
 				Result:   r,
@@ -125,66 +761,251 @@ func runAllModels(ctx context.Context, query string) {
 		}(p)
 	}
 
-	go func() {
-		wg.Wait()
-		close(results)
-	}()
-
-	// Collect results
-	var modelResults []ModelResult
-	for mr := range results {
-		modelResults = append(modelResults, mr)
+	modelResults, interrupted := collectResults(ctx, stop, available, results)
+	progress.Stop()
+	if interrupted {
+		fmt.Printf("⏹️  Interrupted — showing %d of %d results, skipping judge\n\n", completedCount(modelResults), len(available))
 	}
+	return modelResults, interrupted, nil
+}
 
-	// Judge phase: validate links + LLM evaluation
+// printJudgingBanner announces the judge phase, with the active weights under -v.
+func printJudgingBanner(msg string) {
 	fmt.Println()
-	fmt.Println("⚖️  Judging results...")
-	modelResults, err := Judge(ctx, modelResults, query, verbose)
-	if err != nil {
-		fmt.Printf("⚠️  Judge error: %v (showing results unranked)\n", err)
+	fmt.Println(msg)
+	if verbosity >= VerbosityVerbose {
+		fmt.Printf("   weights: %s\n", judgeWeights)
+	}
+}
+
+// printJudgeError reports a failed judge call and how results will be ranked instead.
+func printJudgeError(err error) {
+	fallback := "ranking without judge scores"
+	if sortBy == SortOverall {
+		fallback = "ranking by link health"
+	}
+	fmt.Printf("⚠️  Judge error: %v (%s)\n", err, fallback)
+}
+
+// presentResults ranks one query's (possibly judged) results and prints every
+// answer, the summaries, and the optional extras and saved files.
+func presentResults(ctx context.Context, modelResults []ModelResult, query string, interrupted bool) {
+	// Overall falls back to link health for results the judge didn't score.
+	var checks map[string][]CitationCheck
+	if sortBy == SortOverall && needsLinkHealthFallback(modelResults) {
+		checks = checkAllCitations(ctx, modelResults)
 	}
+	rankResults(modelResults, checks)
 
 	// Print each response
-	for i, mr := range modelResults {
-		rank := i + 1
-		printModelResultWithRank(mr, rank)
+	for _, mr := range modelResults {
+		printModelResultWithRank(mr, mr.Rank)
 		fmt.Println()
 	}
 
 	printComparisonSummary(modelResults)
+	if explainScores {
+		printScoreBreakdown(ctx, modelResults)
+	}
 	printCombinedSummary(modelResults, query)
+	if compareDiff && !interrupted {
+		runClaimDiff(ctx, modelResults, query)
+	}
+	if synthesize && !interrupted {
+		runSynthesis(ctx, modelResults, query)
+	}
+	warnIfOverBudget(modelResults, budget)
+	saveHTMLReport(query, modelResults)
+	saveArchive(ctx, query, modelResults)
+	saveSnapshot(query, modelResults)
+}
+
+// needsLinkHealthFallback reports whether any successful result lacks a judge score.
+func needsLinkHealthFallback(results []ModelResult) bool {
+	for _, mr := range results {
+		if mr.Result.Error == nil && mr.JudgeScore == nil && len(mr.Result.Citations) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// interruptGrace is how long to wait for in-flight queries to return after Ctrl-C.
+const interruptGrace = 2 * time.Second
+
+// errInterrupted marks a provider whose query was cut short by Ctrl-C.
+var errInterrupted = errors.New("interrupted")
+
+// collectResults gathers one result per provider until all arrive or ctx is
+// canceled by an interrupt. After an interrupt it drains results for a short
+// grace period, then marks providers that never finished (or were canceled
+// mid-request) as interrupted. stop is called on interrupt so a second Ctrl-C
+// exits immediately.
+func collectResults(ctx context.Context, stop func(), available []Provider, results <-chan ModelResult) ([]ModelResult, bool) {
+	var collected []ModelResult
+	done := ctx.Done()
+	var grace <-chan time.Time
+	interrupted := false
+
+collect:
+	for len(collected) < len(available) {
+		select {
+		case mr := <-results:
+			collected = append(collected, mr)
+		case <-done:
+			interrupted = true
+			done = nil
+			stop()
+			grace = time.After(interruptGrace)
+		case <-grace:
+			break collect
+		}
+	}
+
+	if !interrupted {
+		return collected, false
+	}
+
+	finished := make(map[string]bool)
+	for i := range collected {
+		finished[collected[i].Provider.Name()] = true
+		if errors.Is(collected[i].Result.Error, context.Canceled) {
+			collected[i].Result.Error = errInterrupted
+			collected[i].Result.ErrorKind = ErrorKindOther
+		}
+	}
+	for _, p := range available {
+		if !finished[p.Name()] {
+			collected = append(collected, ModelResult{Provider: p, Result: Result{Error: errInterrupted, ErrorKind: ErrorKindOther}})
+		}
+	}
+	return collected, true
+}
+
+// completedCount counts results that finished without error.
+func completedCount(results []ModelResult) int {
+	n := 0
+	for _, mr := range results {
+		if mr.Result.Error == nil {
+			n++
+		}
+	}
+	return n
 }
 
-func runSingleModel(ctx context.Context, modelName, query string) {
+// runClaimDiff compares claims across successful results; it needs at least two answers.
+func runClaimDiff(ctx context.Context, results []ModelResult, query string) {
+	succeeded := 0
+	for _, mr := range results {
+		if mr.Result.Error == nil {
+			succeeded++
+		}
+	}
+	if succeeded < 2 {
+		fmt.Println("⚠️  -compare-diff needs at least two successful responses")
+		return
+	}
+
+	fmt.Println("⚖️  Comparing claims across models...")
+	clusters, err := CompareClaims(ctx, results, query)
+	if err != nil {
+		fmt.Printf("⚠️  Claim comparison error: %v\n", err)
+		return
+	}
+	printClaimDiff(clusters)
+}
+
+// runSynthesis merges successful results into one answer; it needs at least two.
+func runSynthesis(ctx context.Context, results []ModelResult, query string) {
+	if completedCount(results) < 2 {
+		fmt.Println("⚠️  -synthesize needs at least two successful responses")
+		return
+	}
+
+	fmt.Println("🧩 Synthesizing answer across models...")
+	s, err := Synthesize(ctx, results, query)
+	if err != nil {
+		fmt.Printf("⚠️  Synthesis error: %v\n", err)
+		return
+	}
+	printSynthesis(s)
+}
+
+// saveHTMLReport writes the -save-html report, if requested.
+func saveHTMLReport(query string, results []ModelResult) {
+	if saveHTML == "" {
+		return
+	}
+	if err := writeHTMLReport(saveHTML, query, results); err != nil {
+		fmt.Printf("⚠️  HTML report error: %v\n", err)
+		return
+	}
+	fmt.Printf("📄 HTML report saved to %s\n", saveHTML)
+}
+
+func runSingleModel(ctx context.Context, modelName, query string) ([]ModelResult, error) {
+	mr, err := querySingleModel(ctx, modelName, query)
+	if err != nil {
+		return nil, err
+	}
+	if !skipJudge {
+		// Judge even single model results
+		printJudgingBanner("⚖️  Judging results...")
+		if judged, err := Judge(ctx, []ModelResult{mr}, query); err != nil {
+			fmt.Printf("⚠️  Judge error: %v\n", err)
+		} else {
+			mr = judged[0]
+		}
+	}
+	presentSingleResult(ctx, mr, query)
+	return []ModelResult{mr}, nil
+}
+
+// querySingleModel checks and queries one named provider, returning an
+// *exitError if it can't run. The result is unjudged.
+func querySingleModel(ctx context.Context, modelName, query string) (ModelResult, error) {
 	p, ok := Get(modelName)
 	if !ok {
-		fmt.Fprintf(os.Stderr, "❌ Unknown model: %s\n", modelName)
-		fmt.Printf("Available models: %s\n", strings.Join(All(), ", "))
-		os.Exit(1)
+		return ModelResult{}, &exitError{Code: 1, Err: fmt.Errorf("Unknown model: %s (available: %s)", modelName, strings.Join(All(), ", "))}
 	}
 
-	if err := p.CheckAuth(); err != nil {
-		fmt.Printf("❌ %s %s: %s\n", p.Emoji(), p.DisplayName(), err.Error())
-		os.Exit(1)
+	if err := p.CheckAuth(); err != nil && !dryRun {
+		return ModelResult{}, &exitError{Code: noProvidersExitCode(), Err: fmt.Errorf("%s %s: %w", p.Emoji(), p.DisplayName(), err)}
 	}
 
+	if answerSchema != nil && !supportsAnswerSchema(p) {
+		return ModelResult{}, &exitError{Code: noProvidersExitCode(), Err: fmt.Errorf("%s %s: no structured output support (-answer-schema)", p.Emoji(), p.DisplayName())}
+	}
+
+	if budget > 0 && costUnknown(p.Name()) {
+		return ModelResult{}, &exitError{Code: 1, Err: fmt.Errorf("%s %s: cost unknown (no price for %s), can't keep within budget $%.4f", p.Emoji(), p.DisplayName(), modelIDOf(p), budget)}
+	}
+	if worst := WorstCaseCost(p.Name()); budget > 0 && worst > budget {
+		return ModelResult{}, &exitError{Code: 1, Err: fmt.Errorf("%s %s: worst-case cost ~$%.4f exceeds budget $%.4f", p.Emoji(), p.DisplayName(), worst, budget)}
+	}
+
+	warnUnsupportedFlags([]Provider{p})
 	fmt.Printf("🔍 Running with %s...\n", p.DisplayName())
 	fmt.Println(strings.Repeat("─", 60))
 
-	r := p.Query(ctx, query, verbose)
-	mr := ModelResult{
+	r := queryProvider(ctx, p, query)
+	logProviderResult(p, r)
+	return ModelResult{
 		Provider: p,
 		Result:   r,
-	}
+		Rank:     1, // Alone, so trivially the top result for -quiet and reports
+	}, nil
+}
 
-	// Judge even single model results
+// presentSingleResult prints a single-model answer and saves the requested files.
+func presentSingleResult(ctx context.Context, mr ModelResult, query string) {
 	fmt.Println()
-	fmt.Println("⚖️  Judging results...")
-	judged, err := Judge(ctx, []ModelResult{mr}, query, verbose)
-	if err != nil {
-		fmt.Printf("⚠️  Judge error: %v\n", err)
-		printModelResult(mr)
-	} else {
-		printModelResult(judged[0])
+	printModelResult(mr)
+	if explainScores && mr.JudgeScore != nil {
+		printScoreBreakdown(ctx, []ModelResult{mr})
 	}
+	saveHTMLReport(query, []ModelResult{mr})
+	saveArchive(ctx, query, []ModelResult{mr})
+	saveSnapshot(query, []ModelResult{mr})
+	warnIfOverBudget([]ModelResult{mr}, budget)
 }
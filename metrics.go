@@ -0,0 +1,205 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Metrics outputs for scheduled runs, set by -metrics-file and -statsd. Both
+// report every provider in the run, keyed by provider name.
+var (
+	metricsFile string // Prometheus textfile-collector output, rewritten each run
+	statsdAddr  string // host:port of a StatsD daemon (UDP)
+)
+
+// metricsPrefix starts every metric name.
+const metricsPrefix = "websearch"
+
+// providerMetrics is one provider's totals across the run (every query of a
+// -queries-file batch).
+type providerMetrics struct {
+	Queries      int
+	Errors       int
+	Latency      time.Duration // Sum over answered, uncached queries
+	Timed        int           // Queries counted in Latency
+	InputTokens  int
+	OutputTokens int
+	Cost         float64
+	JudgeScore   float64 // Sum of overall scores
+	Judged       int
+}
+
+// summarizeMetrics totals results by provider name.
+func summarizeMetrics(results []ModelResult) map[string]*providerMetrics {
+	byName := make(map[string]*providerMetrics)
+	for _, mr := range results {
+		name := mr.Provider.Name()
+		m := byName[name]
+		if m == nil {
+			m = &providerMetrics{}
+			byName[name] = m
+		}
+		r := mr.Result
+		m.Queries++
+		if r.Error != nil {
+			m.Errors++
+			continue
+		}
+		if !r.Cached {
+			m.Latency += r.Duration
+			m.Timed++
+		}
+		m.InputTokens += r.Tokens.Input
+		m.OutputTokens += r.Tokens.Output
+		m.Cost += r.EstimatedCost(name)
+		if js := mr.JudgeScore; js != nil {
+			m.JudgeScore += js.Overall
+			m.Judged++
+		}
+	}
+	return byName
+}
+
+// emitMetrics writes -metrics-file and sends -statsd metrics for a finished
+// run. Failures are reported but don't fail the run.
+func emitMetrics(results []ModelResult) {
+	if len(results) == 0 || (metricsFile == "" && statsdAddr == "") {
+		return
+	}
+	if metricsFile != "" {
+		if err := writePrometheusMetrics(metricsFile, summarizeMetrics(results)); err != nil {
+			fmt.Printf("⚠️  Metrics error: %v\n", err)
+		}
+	}
+	if statsdAddr != "" {
+		if err := sendStatsdMetrics(statsdAddr, results); err != nil {
+			fmt.Printf("⚠️  StatsD error: %v\n", err)
+		}
+	}
+}
+
+// writePrometheusMetrics writes gauges in the Prometheus text format for
+// node_exporter's textfile collector. The file is replaced atomically so the
+// collector never reads a partial run.
+func writePrometheusMetrics(path string, byName map[string]*providerMetrics) error {
+	names := make([]string, 0, len(byName))
+	for name := range byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	gauge := func(metric, help string, value func(m *providerMetrics) (float64, bool)) {
+		fmt.Fprintf(&b, "# HELP %s_%s %s\n# TYPE %s_%s gauge\n", metricsPrefix, metric, help, metricsPrefix, metric)
+		for _, name := range names {
+			if v, ok := value(byName[name]); ok {
+				fmt.Fprintf(&b, "%s_%s{provider=%q} %g\n", metricsPrefix, metric, name, v)
+			}
+		}
+	}
+	gauge("queries", "Queries sent to the provider in the last run.", func(m *providerMetrics) (float64, bool) {
+		return float64(m.Queries), true
+	})
+	gauge("errors", "Queries that failed in the last run.", func(m *providerMetrics) (float64, bool) {
+		return float64(m.Errors), true
+	})
+	gauge("latency_seconds", "Mean latency of answered, uncached queries in the last run.", func(m *providerMetrics) (float64, bool) {
+		return m.Latency.Seconds() / float64(m.Timed), m.Timed > 0
+	})
+	gauge("input_tokens", "Input tokens used in the last run.", func(m *providerMetrics) (float64, bool) {
+		return float64(m.InputTokens), true
+	})
+	gauge("output_tokens", "Output tokens used in the last run.", func(m *providerMetrics) (float64, bool) {
+		return float64(m.OutputTokens), true
+	})
+	gauge("cost_usd", "Estimated cost in USD of the last run.", func(m *providerMetrics) (float64, bool) {
+		return m.Cost, true
+	})
+	gauge("judge_score", "Mean overall judge score (0-10) in the last run.", func(m *providerMetrics) (float64, bool) {
+		return m.JudgeScore / float64(m.Judged), m.Judged > 0
+	})
+	fmt.Fprintf(&b, "# HELP %s_last_run_timestamp_seconds Unix time the last run finished.\n# TYPE %s_last_run_timestamp_seconds gauge\n%s_last_run_timestamp_seconds %d\n",
+		metricsPrefix, metricsPrefix, metricsPrefix, time.Now().Unix())
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".metrics-*.prom")
+	if err != nil {
+		return fmt.Errorf("create metrics file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.WriteString(b.String()); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write metrics file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write metrics file: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return fmt.Errorf("write metrics file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("write metrics file: %w", err)
+	}
+	return nil
+}
+
+// sendStatsdMetrics sends one sample per query and provider, so a batch shows
+// up as a latency distribution rather than a single value:
+//
+//	websearch.<provider>.queries:1|c
+//	websearch.<provider>.errors:1|c
+//	websearch.<provider>.latency:1234|ms
+//	websearch.<provider>.tokens.input:850|c
+//	websearch.<provider>.tokens.output:420|c
+//	websearch.<provider>.cost_usd:0.0123|c
+//	websearch.<provider>.judge_score:7.5|g
+func sendStatsdMetrics(addr string, results []ModelResult) error {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return fmt.Errorf("dial %s: %w", addr, err)
+	}
+	defer conn.Close()
+
+	for _, mr := range results {
+		r := mr.Result
+		key := metricsPrefix + "." + statsdName(mr.Provider.Name())
+		lines := []string{key + ".queries:1|c"}
+		if r.Error != nil {
+			lines = append(lines, key+".errors:1|c")
+		} else {
+			if !r.Cached {
+				lines = append(lines, fmt.Sprintf("%s.latency:%d|ms", key, r.Duration.Milliseconds()))
+			}
+			lines = append(lines,
+				fmt.Sprintf("%s.tokens.input:%d|c", key, r.Tokens.Input),
+				fmt.Sprintf("%s.tokens.output:%d|c", key, r.Tokens.Output),
+				fmt.Sprintf("%s.cost_usd:%g|c", key, r.EstimatedCost(mr.Provider.Name())))
+			if js := mr.JudgeScore; js != nil {
+				lines = append(lines, fmt.Sprintf("%s.judge_score:%g|g", key, js.Overall))
+			}
+		}
+		// One datagram per provider and query stays well under a UDP MTU.
+		if _, err := conn.Write([]byte(strings.Join(lines, "\n"))); err != nil {
+			return fmt.Errorf("send to %s: %w", addr, err)
+		}
+	}
+	return nil
+}
+
+// statsdName makes a provider name safe as one StatsD key segment: ':' and
+// '|' delimit the value and type, '@' and '#' the sample rate and tags, and
+// '.' nests, so a variant like "claude:sonnet-4.5" is sent as
+// "claude_sonnet-4_5".
+func statsdName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		}
+		return '_'
+	}, name)
+}
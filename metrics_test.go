@@ -0,0 +1,21 @@
+package main
+
+import "testing"
+
+func TestStatsdName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"claude", "claude"},
+		{"claude:opus", "claude_opus"},
+		{"claude:sonnet-4.5", "claude_sonnet-4_5"},
+		{"openrouter|x@y#z", "openrouter_x_y_z"},
+		{"my gateway", "my_gateway"},
+	}
+	for _, tt := range tests {
+		if got := statsdName(tt.name); got != tt.want {
+			t.Errorf("statsdName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	mistralModelID  = "mistral-medium-latest"
+	mistralEndpoint = "https://api.mistral.ai/v1/conversations"
+)
+
+func init() {
+	Register(&MistralProvider{})
+}
+
+// MistralProvider implements Provider for Mistral via the Conversations API
+// with the built-in web_search tool.
+// The HTTP client is created on first use and reused across queries.
+type MistralProvider struct {
+	clientOnce sync.Once
+	client     httpDoer
+	variant    modelVariant
+}
+
+func (p *MistralProvider) Name() string { return p.variant.name("mistral") }
+func (p *MistralProvider) DisplayName() string {
+	return p.variant.displayName(p.Name(), "Mistral Medium")
+}
+func (p *MistralProvider) Emoji() string   { return "🟡" }
+func (p *MistralProvider) ModelID() string { return p.variant.modelOr(mistralModelID) }
+
+// WithModel returns an instance for one of the other Mistral models, e.g. mistral-large-latest.
+func (p *MistralProvider) WithModel(label, model string) (Provider, error) {
+	return &MistralProvider{variant: modelVariant{label: label, model: model}}, nil
+}
+
+func (p *MistralProvider) Capabilities() Capabilities { return Capabilities{SystemPrompt: true} }
+
+func (p *MistralProvider) CheckAuth() error {
+	if os.Getenv("MISTRAL_API_KEY") == "" {
+		return &AuthError{Reason: "MISTRAL_API_KEY not set", Hint: "export MISTRAL_API_KEY=... (console.mistral.ai)"}
+	}
+	return nil
+}
+
+func (p *MistralProvider) Query(ctx context.Context, query string, v Verbosity) Result {
+	return p.QueryConversation(ctx, []Message{{Role: RoleUser, Text: query}}, v)
+}
+
+func (p *MistralProvider) QueryConversation(ctx context.Context, history []Message, v Verbosity) Result {
+	start := time.Now()
+	result := Result{}
+
+	// store=false keeps the exchange out of Mistral's conversation history;
+	// prior turns are sent in full each time like the other providers.
+	reqBody := mistralRequest{
+		Model:        p.ModelID(),
+		Inputs:       mistralInputs(history),
+		Tools:        []mistralTool{{Type: "web_search"}},
+		Instructions: systemPrompt,
+		Store:        false,
+	}
+
+	if dryRun {
+		return dryRunResult(p, map[string]any{
+			"endpoint": mistralEndpoint,
+			"body":     reqBody,
+		})
+	}
+
+	slog.Debug("sending request", "provider", p.Name(), "tool", "web_search")
+
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		result.Error = fmt.Errorf("marshal error: %w", err)
+		return result
+	}
+	dumpBytes(p.Name()+"-request", jsonData)
+
+	req, err := http.NewRequestWithContext(ctx, "POST", mistralEndpoint, bytes.NewBuffer(jsonData))
+	if err != nil {
+		result.Error = fmt.Errorf("request error: %w", err)
+		return result
+	}
+
+	req.Header.Set("Authorization", "Bearer "+os.Getenv("MISTRAL_API_KEY"))
+	req.Header.Set("Content-Type", "application/json")
+
+	p.clientOnce.Do(func() {
+		if p.client == nil {
+			p.client = newHTTPClient(5 * time.Minute)
+		}
+	})
+	resp, err := p.client.Do(req)
+	result.Duration = time.Since(start)
+
+	if err != nil {
+		result.Error = fmt.Errorf("API error: %w", err)
+		return result
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		result.Error = fmt.Errorf("read error: %w", err)
+		return result
+	}
+	dumpBytes(p.Name()+"-response", body)
+	keepRaw(&result, body)
+
+	if resp.StatusCode != http.StatusOK {
+		result.Error = &statusError{StatusCode: resp.StatusCode, Message: string(body)}
+		return result
+	}
+
+	var mistralResp mistralResponse
+	if err := json.Unmarshal(body, &mistralResp); err != nil {
+		result.Error = fmt.Errorf("%w: %w", errParse, err)
+		return result
+	}
+
+	result.Tokens.Input = mistralResp.Usage.PromptTokens + mistralResp.Usage.ConnectorTokens
+	result.Tokens.Output = mistralResp.Usage.CompletionTokens
+
+	parseMistralResponse(&mistralResp, &result)
+	for _, out := range mistralResp.Outputs {
+		if out.Type == "tool.execution" {
+			logToolCall(v, p.Name(), out.Name, out.Arguments)
+		}
+	}
+	mistralTimeline(&mistralResp, &result)
+	return result
+}
+
+// mistralTimeline records each output entry as a timeline step. Entries carry
+// created_at and completed_at, so tool runs and messages are timed.
+func mistralTimeline(resp *mistralResponse, result *Result) {
+	for _, out := range resp.Outputs {
+		var d time.Duration
+		if out.CreatedAt != nil && out.CompletedAt != nil {
+			d = out.CompletedAt.Sub(*out.CreatedAt)
+		}
+		switch {
+		case out.Type == "tool.execution" && strings.HasPrefix(out.Name, "web_search"):
+			var args struct {
+				Query string `json:"query"`
+			}
+			json.Unmarshal([]byte(out.Arguments), &args)
+			addSearchStep(result, args.Query, d)
+		case out.Type == "tool.execution":
+			addTimelineStep(result, StepTool, out.Name, d)
+		case out.Type == "message.output":
+			addTimelineStep(result, StepGenerate, "", d)
+		}
+	}
+}
+
+// mistralInputs maps conversation history to Conversations API input entries.
+func mistralInputs(history []Message) []mistralInput {
+	inputs := make([]mistralInput, 0, len(history))
+	for _, m := range history {
+		inputs = append(inputs, mistralInput{Role: m.Role, Content: m.Text})
+	}
+	return inputs
+}
+
+// --- Mistral API Types ---
+
+type mistralRequest struct {
+	Model        string         `json:"model"`
+	Inputs       []mistralInput `json:"inputs"`
+	Tools        []mistralTool  `json:"tools,omitempty"`
+	Instructions string         `json:"instructions,omitempty"`
+	Store        bool           `json:"store"`
+}
+
+type mistralInput struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type mistralTool struct {
+	Type string `json:"type"`
+}
+
+type mistralResponse struct {
+	Outputs []struct {
+		Type        string         `json:"type"` // "message.output", "tool.execution"
+		Content     mistralContent `json:"content"`
+		Name        string         `json:"name"`      // tool.execution: the tool run
+		Arguments   string         `json:"arguments"` // tool.execution: its JSON arguments
+		CreatedAt   *time.Time     `json:"created_at"`
+		CompletedAt *time.Time     `json:"completed_at"`
+	} `json:"outputs"`
+	Usage struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+		ConnectorTokens  int `json:"connector_tokens"` // Search results added to the context
+	} `json:"usage"`
+}
+
+// mistralContent is a message's content, which the API sends as a plain string
+// when there are no references and as a chunk list otherwise.
+type mistralContent []mistralChunk
+
+func (c *mistralContent) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*c = mistralContent{{Type: "text", Text: s}}
+		return nil
+	}
+	var chunks []mistralChunk
+	if err := json.Unmarshal(data, &chunks); err != nil {
+		return err
+	}
+	*c = chunks
+	return nil
+}
+
+// mistralChunk is one piece of a message: text, or a tool_reference citing the
+// text chunk before it.
+type mistralChunk struct {
+	Type  string `json:"type"`
+	Text  string `json:"text"`
+	Tool  string `json:"tool"`
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+// parseMistralResponse joins the assistant's text chunks into Result.Text.
+// Citations arrive inline as tool_reference chunks, each pointing back at the
+// text chunk it follows, so that chunk's range becomes the citation's span.
+func parseMistralResponse(resp *mistralResponse, result *Result) {
+	var text bytes.Buffer
+	seen := make(map[string]bool)
+	spanStart, spanEnd := 0, 0
+
+	for _, out := range resp.Outputs {
+		if out.Type != "message.output" {
+			continue
+		}
+		for _, chunk := range out.Content {
+			switch chunk.Type {
+			case "text":
+				spanStart = text.Len()
+				text.WriteString(chunk.Text)
+				spanEnd = text.Len()
+			case "tool_reference":
+				if chunk.URL == "" {
+					continue
+				}
+				DeduplicateCitations(&result.Citations, seen, Citation{
+					URL:        chunk.URL,
+					Title:      chunk.Title,
+					StartIndex: spanStart,
+					EndIndex:   spanEnd,
+				})
+			}
+		}
+	}
+
+	result.Text = text.String()
+}
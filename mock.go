@@ -0,0 +1,210 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Mock providers return deterministic results so the comparison, judging, ranking,
+// and cost paths can be exercised without API keys (e.g. in CI). They are only
+// registered when WEBSEARCH_MOCK is set, and are scripted via env:
+//
+//	WEBSEARCH_MOCK=3                 register mock, mock2, mock3 (1 registers just mock)
+//	WEBSEARCH_MOCK_CITATIONS=2       citations for mock; mockN returns N-1 more (default 3)
+//	WEBSEARCH_MOCK_ERROR=mock2,mock3 providers that fail ("all" fails every mock)
+//	WEBSEARCH_MOCK_DELAY=500ms       latency for mock; mockN waits N times as long
+//
+// While mocks are registered the judge is mocked too, scoring from citation
+// counts; a warning is logged if it scores any real provider.
+const mockEnv = "WEBSEARCH_MOCK"
+
+func init() {
+	registerMocks(mockConfigFromEnv())
+}
+
+// mockConfig scripts the mock providers; tests build one directly.
+type mockConfig struct {
+	Count     int           // Providers to register: mock, mock2, ...
+	Citations int           // Citations for mock; mockN returns N-1 more
+	Fail      []string      // Provider names that fail ("all" fails every mock)
+	Delay     time.Duration // Latency for mock; mockN waits N times as long
+}
+
+// mockConfigFromEnv reads the WEBSEARCH_MOCK variables. Count is 0 if unset.
+func mockConfigFromEnv() mockConfig {
+	cfg := mockConfig{Citations: 3}
+	if n, err := strconv.Atoi(os.Getenv(mockEnv)); err == nil && n > 0 {
+		cfg.Count = n
+	}
+	if n, err := strconv.Atoi(os.Getenv(mockEnv + "_CITATIONS")); err == nil && n >= 0 {
+		cfg.Citations = n
+	}
+	for _, name := range strings.Split(os.Getenv(mockEnv+"_ERROR"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			cfg.Fail = append(cfg.Fail, name)
+		}
+	}
+	if d, err := time.ParseDuration(os.Getenv(mockEnv + "_DELAY")); err == nil && d > 0 {
+		cfg.Delay = d
+	}
+	return cfg
+}
+
+// mocksRegistered is set once registerMocks adds any provider.
+var mocksRegistered bool
+
+// registerMocks registers cfg.Count mock providers with their pricing and
+// returns their names. Registering any swaps judgeEvaluate for mockJudge.
+func registerMocks(cfg mockConfig) []string {
+	var names []string
+	for i := 1; i <= cfg.Count; i++ {
+		p := &MockProvider{index: i, cfg: cfg}
+		Pricing[p.Name()] = struct{ Input, Output, CachedInput float64 }{1.00, 5.00, 0}
+		SearchCost[p.Name()] = 0.01
+		MaxTokenEstimate[p.Name()] = TokenUsage{Input: 1000 * i, Output: 500 * i}
+		Register(p)
+		names = append(names, p.Name())
+	}
+	if len(names) > 0 {
+		mocksRegistered = true
+		judgeEvaluate = mockJudge
+	}
+	return names
+}
+
+// mockEnabled reports whether mock providers, and so the mock judge, are registered.
+func mockEnabled() bool { return mocksRegistered }
+
+// MockProvider implements Provider with canned results. The index (1-based)
+// varies citations, tokens, and latency so mocks rank differently.
+type MockProvider struct {
+	index   int
+	cfg     mockConfig
+	variant modelVariant
+}
+
+func (p *MockProvider) Name() string {
+	if p.index == 1 {
+		return p.variant.name("mock")
+	}
+	return p.variant.name(fmt.Sprintf("mock%d", p.index))
+}
+
+func (p *MockProvider) DisplayName() string {
+	return p.variant.displayName(p.Name(), fmt.Sprintf("Mock Model %d", p.index))
+}
+func (p *MockProvider) Emoji() string { return "🧪" }
+
+// WithModel returns a copy under another name, for exercising
+// -compare-models-same-provider offline; the model ID only changes the name.
+func (p *MockProvider) WithModel(label, model string) (Provider, error) {
+	return &MockProvider{index: p.index, cfg: p.cfg, variant: modelVariant{label: label, model: model}}, nil
+}
+func (p *MockProvider) CheckAuth() error { return nil }
+
+func (p *MockProvider) Query(ctx context.Context, query string, v Verbosity) Result {
+	start := time.Now()
+	result := Result{}
+
+	if dryRun {
+		return dryRunResult(p, map[string]any{"query": query})
+	}
+
+	if d := p.cfg.Delay; d > 0 {
+		select {
+		case <-time.After(d * time.Duration(p.index)):
+		case <-ctx.Done():
+			result.Duration = time.Since(start)
+			result.Error = ctx.Err()
+			return result
+		}
+	}
+	result.Duration = time.Since(start)
+	logSearchQuery(v, p.Name(), query)
+	addSearchStep(&result, query, result.Duration)
+
+	if p.shouldFail() {
+		result.Error = fmt.Errorf("mock error (%s)", mockEnv+"_ERROR")
+		return result
+	}
+
+	citations := p.cfg.Citations + p.index - 1
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Mock answer %d for %q.\n", p.index, query)
+	seen := make(map[string]bool)
+	for i := 1; i <= citations; i++ {
+		c := Citation{
+			URL:   fmt.Sprintf("https://example.com/story-%d", i), // Shared across mocks, so sources overlap
+			Title: fmt.Sprintf("Mock story %d", i),
+		}
+		DeduplicateCitations(&result.Citations, seen, c)
+		fmt.Fprintf(&b, "\n- %s [%d]", c.Title, i)
+	}
+	result.Text = b.String()
+	result.SearchResults = citations
+	result.Tokens = TokenUsage{Input: 1000 * p.index, Output: 500 * p.index}
+	return result
+}
+
+// shouldFail reports whether the config's Fail list names this provider.
+func (p *MockProvider) shouldFail() bool {
+	for _, name := range p.cfg.Fail {
+		if name == "all" || name == p.Name() {
+			return true
+		}
+	}
+	return false
+}
+
+// mockJudgeNotices make mockJudge say once per run that scores are fake, and
+// warn once if they include real providers'.
+var mockJudgeNotices struct{ mocked, real sync.Once }
+
+// mockJudge stands in for llmJudge while mocks are registered.
+func mockJudge(ctx context.Context, results []ModelResult, query string, checks map[string][]CitationCheck) ([]judgeEvaluation, error) {
+	mockJudgeNotices.mocked.Do(func() {
+		slog.Info("judge is mocked; scores come from citation counts", "env", mockEnv)
+	})
+	var real []string
+	for _, mr := range results {
+		if _, ok := mr.Provider.(*MockProvider); !ok && mr.Result.Error == nil {
+			real = append(real, mr.Provider.Name())
+		}
+	}
+	if len(real) > 0 {
+		mockJudgeNotices.real.Do(func() {
+			slog.Warn("judge is mocked while "+mockEnv+" is set; these real providers get fake scores", "providers", strings.Join(real, ","))
+		})
+	}
+	evals := mockJudgeEvaluations(results)
+	recordJudgeExchange("", evals)
+	return evals, nil
+}
+
+// mockJudgeEvaluations scores successful results deterministically: more
+// citations means higher quality and recency.
+func mockJudgeEvaluations(results []ModelResult) []judgeEvaluation {
+	var evals []judgeEvaluation
+	for _, mr := range results {
+		if mr.Result.Error != nil {
+			continue
+		}
+		n := len(mr.Result.Citations)
+		evals = append(evals, judgeEvaluation{
+			Model:        mr.Provider.DisplayName(),
+			Quality:      min(10, 3+n),
+			Recency:      min(10, 2+n),
+			Significance: 6,
+			Impact:       5,
+			Reasoning:    fmt.Sprintf("Mock evaluation: %d citations.", n),
+		})
+	}
+	return evals
+}
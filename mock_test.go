@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+// useMocks registers cfg's mock providers for one test, with every mock
+// citation URL pre-checked healthy so the judge never touches the network.
+func useMocks(t *testing.T, cfg mockConfig) []Provider {
+	t.Helper()
+	names := registerMocks(cfg)
+	linkCheckCacheMu.Lock()
+	for i := 1; i <= cfg.Citations+cfg.Count; i++ {
+		url := fmt.Sprintf("https://example.com/story-%d", i)
+		linkCheckCache[url] = CitationCheck{URL: url, StatusCode: 200, Healthy: true}
+	}
+	linkCheckCacheMu.Unlock()
+	t.Cleanup(func() {
+		for _, name := range names {
+			delete(providers, name)
+			delete(Pricing, name)
+			delete(SearchCost, name)
+			delete(MaxTokenEstimate, name)
+		}
+		mocksRegistered = false
+		judgeEvaluate = llmJudge
+		linkCheckCacheMu.Lock()
+		linkCheckCache = make(map[string]CitationCheck)
+		linkCheckCacheMu.Unlock()
+	})
+
+	var ps []Provider
+	for _, name := range names {
+		p, _ := Get(name)
+		ps = append(ps, p)
+	}
+	return ps
+}
+
+// queryMocks queries each provider in order, as runAllModels would.
+func queryMocks(ps []Provider, query string) []ModelResult {
+	var results []ModelResult
+	for _, p := range ps {
+		results = append(results, ModelResult{Provider: p, Result: queryProvider(context.Background(), p, query)})
+	}
+	return results
+}
+
+// captureStdout returns what f prints to stdout.
+func captureStdout(t *testing.T, f func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	done := make(chan string)
+	go func() {
+		b, _ := io.ReadAll(r)
+		done <- string(b)
+	}()
+	defer func() { os.Stdout = stdout }()
+	f()
+	w.Close()
+	return <-done
+}
+
+func TestMockComparisonFlow(t *testing.T) {
+	tests := []struct {
+		name      string
+		cfg       mockConfig
+		wantOrder []string // Provider names by rank; nil when ties make it latency-dependent
+		wantJudge []bool   // Whether each ranked result has a judge score
+		wantCost  []float64
+		wantLines []string // Substrings of the comparison summary
+	}{
+		{
+			name:      "all succeed",
+			cfg:       mockConfig{Count: 3, Citations: 3},
+			wantOrder: []string{"mock3", "mock2", "mock"},
+			wantJudge: []bool{true, true, true},
+			// Tokens at $1/$5 per million plus the $0.01 search fee.
+			wantCost:  []float64{0.0105 + 0.01, 0.007 + 0.01, 0.0035 + 0.01},
+			wantLines: []string{"🏆 WINNER: Mock Model 3"},
+		},
+		{
+			name:      "one provider fails",
+			cfg:       mockConfig{Count: 3, Citations: 3, Fail: []string{"mock3"}},
+			wantOrder: []string{"mock2", "mock", "mock3"},
+			wantJudge: []bool{true, true, false},
+			wantCost:  []float64{0.007 + 0.01, 0.0035 + 0.01, 0.01},
+			wantLines: []string{"🏆 WINNER: Mock Model 2", "❌"},
+		},
+		{
+			name:      "every provider fails",
+			cfg:       mockConfig{Count: 2, Citations: 3, Fail: []string{"all"}},
+			wantJudge: []bool{false, false},
+			wantCost:  []float64{0.01, 0.01},
+			wantLines: []string{"❌"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ps := useMocks(t, tt.cfg)
+			const query = "what happened in go this week"
+			results := queryMocks(ps, query)
+
+			judged, err := Judge(context.Background(), results, query)
+			if err != nil {
+				t.Fatalf("Judge: %v", err)
+			}
+			rankResults(judged, nil)
+
+			for i, mr := range judged {
+				if got := mr.Provider.Name(); tt.wantOrder != nil && got != tt.wantOrder[i] {
+					t.Errorf("rank %d = %s, want %s", i+1, got, tt.wantOrder[i])
+				}
+				if mr.Rank != i+1 {
+					t.Errorf("%s: Rank = %d, want %d", mr.Provider.Name(), mr.Rank, i+1)
+				}
+				if got := mr.JudgeScore != nil; got != tt.wantJudge[i] {
+					t.Errorf("%s: judged = %v, want %v", mr.Provider.Name(), got, tt.wantJudge[i])
+				}
+				if got := mr.Result.EstimatedCost(mr.Provider.Name()); !approxEqual(got, tt.wantCost[i]) {
+					t.Errorf("%s: EstimatedCost = %.4f, want %.4f", mr.Provider.Name(), got, tt.wantCost[i])
+				}
+			}
+
+			out := captureStdout(t, func() { printComparisonSummary(judged) })
+			for _, want := range tt.wantLines {
+				if !strings.Contains(out, want) {
+					t.Errorf("summary missing %q:\n%s", want, out)
+				}
+			}
+			if strings.Contains(out, "WINNER") && !tt.wantJudge[0] {
+				t.Errorf("summary names a winner when every provider failed:\n%s", out)
+			}
+		})
+	}
+}
+
+func approxEqual(a, b float64) bool {
+	d := a - b
+	return d < 1e-9 && d > -1e-9
+}
+
+func TestMockConfigFromEnv(t *testing.T) {
+	t.Setenv(mockEnv, "2")
+	t.Setenv(mockEnv+"_CITATIONS", "5")
+	t.Setenv(mockEnv+"_ERROR", " mock2 , ,all")
+	t.Setenv(mockEnv+"_DELAY", "250ms")
+	cfg := mockConfigFromEnv()
+	if cfg.Count != 2 || cfg.Citations != 5 || cfg.Delay.Milliseconds() != 250 ||
+		strings.Join(cfg.Fail, ",") != "mock2,all" {
+		t.Errorf("mockConfigFromEnv() = %+v", cfg)
+	}
+
+	t.Setenv(mockEnv, "nope")
+	t.Setenv(mockEnv+"_CITATIONS", "")
+	t.Setenv(mockEnv+"_ERROR", "")
+	t.Setenv(mockEnv+"_DELAY", "")
+	if cfg := mockConfigFromEnv(); cfg.Count != 0 || cfg.Citations != 3 || cfg.Fail != nil || cfg.Delay != 0 {
+		t.Errorf("mockConfigFromEnv() with bad/unset env = %+v", cfg)
+	}
+}
@@ -2,62 +2,185 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"net/http"
+	"log/slog"
+	"regexp"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
 	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/smithy-go"
 )
 
 const (
 	novaModelID       = "us.amazon.nova-premier-v1:0"
 	novaGroundingTool = "nova_grounding"
+	novaRegion        = "us-east-1"
 )
 
+// novaModelARN overrides novaModelID via -nova-model-arn: an inference
+// profile or provisioned-throughput ARN, or a plain model / profile ID.
+var novaModelARN string
+
+// novaInferenceProfilePrefixes maps cross-region inference profile prefixes to
+// the region prefix they can be invoked from ("global." works anywhere).
+var novaInferenceProfilePrefixes = map[string]string{
+	"us.":     "us-",
+	"us-gov.": "us-gov-",
+	"eu.":     "eu-",
+	"apac.":   "ap-",
+	"global.": "",
+}
+
+// novaARNRegex matches Bedrock model ARNs; group 1 is the region, 2 the resource type.
+var novaARNRegex = regexp.MustCompile(`^arn:aws[a-z-]*:bedrock:([a-z0-9-]+):(?:\d{12})?:(foundation-model|inference-profile|application-inference-profile|provisioned-model)/[\w.:-]+$`)
+
+// novaModelIDRegex matches plain model IDs like amazon.nova-premier-v1:0.
+var novaModelIDRegex = regexp.MustCompile(`^[a-z0-9-]+\.[a-z0-9.-]+(:[\w.-]+)*$`)
+
+// novaTarget is the model identifier Converse is called with and the region
+// the Bedrock client must use for it.
+type novaTarget struct {
+	ModelID string
+	Region  string
+}
+
+// resolveNovaTarget validates id (novaModelID when empty) and picks the region.
+// ARNs carry their own region; IDs use novaRegion, and a cross-region profile
+// prefix must match it.
+func resolveNovaTarget(id string) (novaTarget, error) {
+	if id == "" {
+		id = novaModelID
+	}
+	if strings.HasPrefix(id, "arn:") {
+		m := novaARNRegex.FindStringSubmatch(id)
+		if m == nil {
+			return novaTarget{}, fmt.Errorf("invalid Bedrock ARN %q (want arn:aws:bedrock:REGION:ACCOUNT:inference-profile/..., application-inference-profile/..., or provisioned-model/...)", id)
+		}
+		if m[2] == "foundation-model" {
+			slog.Debug("foundation-model ARN uses on-demand throughput; the model may need an inference profile", "arn", id)
+		}
+		return novaTarget{ModelID: id, Region: m[1]}, nil
+	}
+
+	for prefix, regionPrefix := range novaInferenceProfilePrefixes {
+		if strings.HasPrefix(id, prefix) {
+			if !strings.HasPrefix(novaRegion, regionPrefix) {
+				return novaTarget{}, fmt.Errorf("inference profile %q can't be invoked from %s; use a profile for %s (e.g. us.%s) or pass the profile's full ARN", id, novaRegion, novaRegion, strings.TrimPrefix(id, prefix))
+			}
+			return novaTarget{ModelID: id, Region: novaRegion}, nil
+		}
+	}
+
+	if !novaModelIDRegex.MatchString(id) {
+		return novaTarget{}, fmt.Errorf("invalid Nova model %q (want a model ID like amazon.nova-premier-v1:0, an inference profile ID like us.amazon.nova-premier-v1:0, or an ARN)", id)
+	}
+	return novaTarget{ModelID: id, Region: novaRegion}, nil
+}
+
+// currentNovaTarget returns the target for -nova-model-arn, which main has
+// already validated.
+func currentNovaTarget() novaTarget {
+	t, err := resolveNovaTarget(novaModelARN)
+	if err != nil {
+		return novaTarget{ModelID: novaModelID, Region: novaRegion}
+	}
+	return t
+}
+
 func init() {
 	Register(&NovaProvider{})
 }
 
 // NovaProvider implements Provider for Amazon Nova Premier via AWS Bedrock.
-type NovaProvider struct{}
+// The Bedrock client is created on first use and reused across queries.
+type NovaProvider struct {
+	clientOnce sync.Once
+	client     *bedrockruntime.Client
+	clientErr  error
+	variant    modelVariant
+	target     novaTarget // Resolved variant model; unset for the default registration
+}
+
+func (p *NovaProvider) Name() string { return p.variant.name("nova") }
+func (p *NovaProvider) DisplayName() string {
+	return p.variant.displayName(p.Name(), "Nova Premier (AWS)")
+}
+func (p *NovaProvider) Emoji() string   { return "🟠" }
+func (p *NovaProvider) ModelID() string { return p.novaTarget().ModelID }
+
+// WithModel returns an instance for another Nova model, inference profile, or
+// ARN, validated like -nova-model-arn.
+func (p *NovaProvider) WithModel(label, model string) (Provider, error) {
+	target, err := resolveNovaTarget(model)
+	if err != nil {
+		return nil, err
+	}
+	return &NovaProvider{variant: modelVariant{label: label, model: model}, target: target}, nil
+}
+
+// novaTarget returns the variant's target, or -nova-model-arn's.
+func (p *NovaProvider) novaTarget() novaTarget {
+	if p.variant.model != "" {
+		return p.target
+	}
+	return currentNovaTarget()
+}
+
+func (p *NovaProvider) Capabilities() Capabilities {
+	return Capabilities{SystemPrompt: true, Vision: true}
+}
 
-func (p *NovaProvider) Name() string        { return "nova" }
-func (p *NovaProvider) DisplayName() string { return "Nova Premier (AWS)" }
-func (p *NovaProvider) Emoji() string       { return "🟠" }
+// DefaultTimeout allows for web grounding, which often runs past a minute.
+func (p *NovaProvider) DefaultTimeout() time.Duration { return 4 * time.Minute }
 
+// CheckAuth looks for AWS credentials, then verifies them with an STS
+// GetCallerIdentity call (free, no IAM permissions needed), so expired or
+// invalid credentials are caught before the Bedrock request.
 func (p *NovaProvider) CheckAuth() error {
-	ctx := context.Background()
-	cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion("us-east-1"))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(p.novaTarget().Region))
 	if err != nil {
-		return fmt.Errorf("AWS credentials not configured")
+		return &AuthError{Reason: "AWS config could not be loaded: " + err.Error(), Hint: "check ~/.aws/config and AWS_PROFILE"}
 	}
 	creds, err := cfg.Credentials.Retrieve(ctx)
 	if err != nil || creds.AccessKeyID == "" {
-		return fmt.Errorf("AWS credentials not found")
+		return &AuthError{
+			Reason: "no AWS credentials found",
+			Hint:   "run `aws configure` or `aws sso login`, or set AWS_ACCESS_KEY_ID/AWS_SECRET_ACCESS_KEY",
+		}
+	}
+
+	if _, err := sts.NewFromConfig(cfg).GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{}); err != nil {
+		reason := "AWS credentials rejected"
+		var apiErr smithy.APIError
+		if errors.As(err, &apiErr) {
+			reason += ": " + apiErr.ErrorCode()
+		}
+		return &AuthError{
+			Reason: reason,
+			Hint:   "credentials are present but invalid or expired; refresh with `aws sso login` or `aws configure`",
+		}
 	}
 	return nil
 }
 
-func (p *NovaProvider) Query(ctx context.Context, query string, verbose bool) Result {
+func (p *NovaProvider) Query(ctx context.Context, query string, v Verbosity) Result {
+	return p.QueryConversation(ctx, []Message{{Role: RoleUser, Text: query}}, v)
+}
+
+func (p *NovaProvider) QueryConversation(ctx context.Context, history []Message, v Verbosity) Result {
 	start := time.Now()
 	result := Result{}
 
-	client, err := createBedrockClient(ctx)
-	if err != nil {
-		result.Error = err
-		return result
-	}
-
-	userMessage := types.Message{
-		Role: types.ConversationRoleUser,
-		Content: []types.ContentBlock{
-			&types.ContentBlockMemberText{Value: query},
-		},
-	}
-
 	toolConfig := &types.ToolConfiguration{
 		Tools: []types.Tool{
 			&types.ToolMemberSystemTool{
@@ -69,57 +192,213 @@ func (p *NovaProvider) Query(ctx context.Context, query string, verbose bool) Re
 	}
 
 	input := &bedrockruntime.ConverseInput{
-		ModelId:    aws.String(novaModelID),
-		Messages:   []types.Message{userMessage},
+		ModelId:    aws.String(p.ModelID()),
+		Messages:   bedrockMessages(history),
 		ToolConfig: toolConfig,
 	}
+	if systemPrompt != "" {
+		input.System = []types.SystemContentBlock{&types.SystemContentBlockMemberText{Value: systemPrompt}}
+	}
+
+	if dryRun {
+		return dryRunResult(p, converseRequestJSON(input))
+	}
 
-	if verbose {
-		fmt.Printf("  [Nova] Sending request with web grounding...\n")
+	p.clientOnce.Do(func() { p.client, p.clientErr = createBedrockClient(ctx, p.novaTarget().Region) })
+	if p.clientErr != nil {
+		result.Error = p.clientErr
+		return result
 	}
+	client := p.client
+
+	slog.Debug("sending request", "provider", p.Name(), "tool", novaGroundingTool)
+	dumpJSON(p.Name()+"-request", converseRequestJSON(input))
 
-	output, err := client.Converse(ctx, input)
+	var output *bedrockruntime.ConverseOutput
+	attempts, err := withRetry(ctx, p.Name(), bedrockRetryable, func() error {
+		var err error
+		output, err = client.Converse(ctx, input)
+		return err
+	})
 	result.Duration = time.Since(start)
 
 	if err != nil {
-		result.Error = fmt.Errorf("API error: %w", err)
+		result.Error = bedrockError(err, attempts, p.novaTarget())
 		return result
 	}
+	dumpJSON(p.Name()+"-response", output)
+	keepRawJSON(&result, output)
 
 	// Extract token usage
 	if output.Usage != nil {
 		result.Tokens.Input = int(aws.ToInt32(output.Usage.InputTokens))
 		result.Tokens.Output = int(aws.ToInt32(output.Usage.OutputTokens))
 	}
+	result.FinishReason = string(output.StopReason)
 
 	parseBedrockResponse(output, &result)
+	if msg, ok := output.Output.(*types.ConverseOutputMemberMessage); ok {
+		for _, block := range msg.Value.Content {
+			if b, ok := block.(*types.ContentBlockMemberToolUse); ok {
+				logToolCall(v, p.Name(), aws.ToString(b.Value.Name), b.Value.Input)
+				if name := aws.ToString(b.Value.Name); name == novaGroundingTool {
+					addSearchStep(&result, "", 0)
+				} else {
+					addTimelineStep(&result, StepTool, name, 0)
+				}
+			}
+		}
+	}
 	return result
 }
 
 // --- Helpers ---
 
-type httpClientWithTimeout struct {
-	timeout time.Duration
+// bedrockMessages maps conversation history to Converse API messages.
+func bedrockMessages(history []Message) []types.Message {
+	messages := make([]types.Message, 0, len(history))
+	for _, m := range history {
+		role := types.ConversationRoleUser
+		if m.Role == RoleAssistant {
+			role = types.ConversationRoleAssistant
+		}
+		content := make([]types.ContentBlock, 0, len(m.Images)+1)
+		for _, img := range m.Images {
+			content = append(content, &types.ContentBlockMemberImage{Value: types.ImageBlock{
+				Format: types.ImageFormat(img.Format()),
+				Source: &types.ImageSourceMemberBytes{Value: img.Data},
+			}})
+		}
+		content = append(content, &types.ContentBlockMemberText{Value: m.Text})
+		messages = append(messages, types.Message{Role: role, Content: content})
+	}
+	return messages
 }
 
-func (c *httpClientWithTimeout) Do(req *http.Request) (*http.Response, error) {
-	client := &http.Client{Timeout: c.timeout}
-	return client.Do(req)
+// converseRequestJSON renders input in the Converse API's JSON wire format
+// (as `aws bedrock-runtime converse --cli-input-json` takes it), omitting
+// unset fields. The SDK types marshal as Go union wrappers instead.
+func converseRequestJSON(in *bedrockruntime.ConverseInput) map[string]any {
+	req := map[string]any{"modelId": aws.ToString(in.ModelId)}
+
+	var messages []map[string]any
+	for _, m := range in.Messages {
+		var content []map[string]any
+		for _, block := range m.Content {
+			switch b := block.(type) {
+			case *types.ContentBlockMemberText:
+				content = append(content, map[string]any{"text": b.Value})
+			case *types.ContentBlockMemberImage:
+				img := map[string]any{"format": string(b.Value.Format)}
+				if src, ok := b.Value.Source.(*types.ImageSourceMemberBytes); ok {
+					img["source"] = map[string]any{"bytes": src.Value} // base64, as on the wire
+				}
+				content = append(content, map[string]any{"image": img})
+			}
+		}
+		messages = append(messages, map[string]any{"role": string(m.Role), "content": content})
+	}
+	if len(messages) > 0 {
+		req["messages"] = messages
+	}
+
+	var system []map[string]any
+	for _, block := range in.System {
+		if b, ok := block.(*types.SystemContentBlockMemberText); ok {
+			system = append(system, map[string]any{"text": b.Value})
+		}
+	}
+	if len(system) > 0 {
+		req["system"] = system
+	}
+
+	if in.ToolConfig != nil {
+		var tools []map[string]any
+		for _, tool := range in.ToolConfig.Tools {
+			if t, ok := tool.(*types.ToolMemberSystemTool); ok {
+				tools = append(tools, map[string]any{"systemTool": map[string]any{"name": aws.ToString(t.Value.Name)}})
+			}
+		}
+		if len(tools) > 0 {
+			req["toolConfig"] = map[string]any{"tools": tools}
+		}
+	}
+
+	if ic := in.InferenceConfig; ic != nil {
+		cfg := make(map[string]any)
+		if ic.MaxTokens != nil {
+			cfg["maxTokens"] = *ic.MaxTokens
+		}
+		if ic.Temperature != nil {
+			cfg["temperature"] = *ic.Temperature
+		}
+		if ic.TopP != nil {
+			cfg["topP"] = *ic.TopP
+		}
+		if len(ic.StopSequences) > 0 {
+			cfg["stopSequences"] = ic.StopSequences
+		}
+		if len(cfg) > 0 {
+			req["inferenceConfig"] = cfg
+		}
+	}
+	return req
 }
 
-func createBedrockClient(ctx context.Context) (*bedrockruntime.Client, error) {
-	cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion("us-east-1"))
+func createBedrockClient(ctx context.Context, region string) (*bedrockruntime.Client, error) {
+	cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(region))
 	if err != nil {
 		return nil, fmt.Errorf("failed to load AWS config: %w", err)
 	}
 
 	client := bedrockruntime.NewFromConfig(cfg, func(o *bedrockruntime.Options) {
-		o.HTTPClient = &httpClientWithTimeout{timeout: 5 * time.Minute}
+		o.HTTPClient = newHTTPClient(5 * time.Minute)
+		o.RetryMaxAttempts = 1 // withRetry owns backoff so it isn't compounded
 	})
 
 	return client, nil
 }
 
+// bedrockRetryable reports whether a Converse error is transient: throttling,
+// capacity, or a model that is still loading.
+func bedrockRetryable(err error) bool {
+	var throttled *types.ThrottlingException
+	var unavailable *types.ServiceUnavailableException
+	var notReady *types.ModelNotReadyException
+	return errors.As(err, &throttled) || errors.As(err, &unavailable) || errors.As(err, &notReady)
+}
+
+// bedrockError turns a Converse error for target into an actionable message,
+// keeping the original error wrapped.
+func bedrockError(err error, attempts int, target novaTarget) error {
+	var (
+		throttled   *types.ThrottlingException
+		quota       *types.ServiceQuotaExceededException
+		denied      *types.AccessDeniedException
+		invalid     *types.ValidationException
+		notFound    *types.ResourceNotFoundException
+		unavailable *types.ServiceUnavailableException
+		notReady    *types.ModelNotReadyException
+	)
+	switch {
+	case errors.As(err, &throttled):
+		return fmt.Errorf("Bedrock throttled the request after %d attempts; retry later or raise the %s quota in Service Quotas: %w", attempts, target.ModelID, err)
+	case errors.As(err, &quota):
+		return fmt.Errorf("Bedrock quota exceeded; raise the %s quota in Service Quotas: %w", target.ModelID, err)
+	case errors.As(err, &denied):
+		return fmt.Errorf("%s not enabled in %s — request model access in the Bedrock console (or check IAM bedrock:InvokeModel): %w", target.ModelID, target.Region, err)
+	case errors.As(err, &notFound):
+		return fmt.Errorf("model %s not found in %s: %w", target.ModelID, target.Region, err)
+	case errors.As(err, &invalid) && strings.Contains(invalid.ErrorMessage(), "on-demand throughput"):
+		return fmt.Errorf("%s needs an inference profile or provisioned throughput; pass -nova-model-arn with an inference profile ID (%s) or ARN: %w", target.ModelID, novaModelID, err)
+	case errors.As(err, &invalid):
+		return fmt.Errorf("Bedrock rejected the request: %w", err)
+	case errors.As(err, &unavailable), errors.As(err, &notReady):
+		return fmt.Errorf("Bedrock unavailable after %d attempts; try again shortly: %w", attempts, err)
+	}
+	return fmt.Errorf("API error: %w", err)
+}
+
 func parseBedrockResponse(output *bedrockruntime.ConverseOutput, result *Result) {
 	msg, ok := output.Output.(*types.ConverseOutputMemberMessage)
 	if !ok {
@@ -135,6 +414,11 @@ func parseBedrockResponse(output *bedrockruntime.ConverseOutput, result *Result)
 		case *types.ContentBlockMemberText:
 			text += b.Value
 
+		case *types.ContentBlockMemberReasoningContent:
+			if rt, ok := b.Value.(*types.ReasoningContentBlockMemberReasoningText); ok {
+				AppendThinking(result, aws.ToString(rt.Value.Text))
+			}
+
 		case *types.ContentBlockMemberCitationsContent:
 			for _, content := range b.Value.Content {
 				if textContent, ok := content.(*types.CitationGeneratedContentMemberText); ok {
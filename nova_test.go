@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+)
+
+func TestConverseRequestJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		input *bedrockruntime.ConverseInput
+		want  string
+	}{
+		{
+			name: "grounded query",
+			input: &bedrockruntime.ConverseInput{
+				ModelId:  aws.String(novaModelID),
+				Messages: bedrockMessages([]Message{{Role: RoleUser, Text: "latest Go release?"}}),
+				ToolConfig: &types.ToolConfiguration{Tools: []types.Tool{
+					&types.ToolMemberSystemTool{Value: types.SystemTool{Name: aws.String(novaGroundingTool)}},
+				}},
+			},
+			want: `{"messages":[{"content":[{"text":"latest Go release?"}],"role":"user"}],` +
+				`"modelId":"us.amazon.nova-premier-v1:0",` +
+				`"toolConfig":{"tools":[{"systemTool":{"name":"nova_grounding"}}]}}`,
+		},
+		{
+			name: "system prompt, image, and inference config",
+			input: &bedrockruntime.ConverseInput{
+				ModelId: aws.String("amazon.nova-pro-v1:0"),
+				Messages: []types.Message{{Role: types.ConversationRoleUser, Content: []types.ContentBlock{
+					&types.ContentBlockMemberImage{Value: types.ImageBlock{
+						Format: types.ImageFormatPng,
+						Source: &types.ImageSourceMemberBytes{Value: []byte("png")},
+					}},
+					&types.ContentBlockMemberText{Value: "what is this?"},
+				}}},
+				System:          []types.SystemContentBlock{&types.SystemContentBlockMemberText{Value: "Be brief."}},
+				InferenceConfig: &types.InferenceConfiguration{MaxTokens: aws.Int32(512)},
+			},
+			want: `{"inferenceConfig":{"maxTokens":512},` +
+				`"messages":[{"content":[{"image":{"format":"png","source":{"bytes":"cG5n"}}},{"text":"what is this?"}],"role":"user"}],` +
+				`"modelId":"amazon.nova-pro-v1:0","system":[{"text":"Be brief."}]}`,
+		},
+		{
+			name:  "unset fields omitted",
+			input: &bedrockruntime.ConverseInput{ModelId: aws.String("m"), InferenceConfig: &types.InferenceConfiguration{}},
+			want:  `{"modelId":"m"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(converseRequestJSON(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBedrockErrorNamesTarget(t *testing.T) {
+	target := novaTarget{ModelID: "us.amazon.nova-pro-v1:0", Region: "us-west-2"}
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{&types.ThrottlingException{}, "raise the us.amazon.nova-pro-v1:0 quota"},
+		{&types.ServiceQuotaExceededException{}, "raise the us.amazon.nova-pro-v1:0 quota"},
+		{&types.AccessDeniedException{}, "us.amazon.nova-pro-v1:0 not enabled in us-west-2"},
+		{&types.ResourceNotFoundException{}, "model us.amazon.nova-pro-v1:0 not found in us-west-2"},
+	}
+	for _, tt := range tests {
+		got := bedrockError(tt.err, 3, target)
+		if !strings.Contains(got.Error(), tt.want) || strings.Contains(got.Error(), "Nova Premier") {
+			t.Errorf("bedrockError(%T) = %q, want it to contain %q", tt.err, got, tt.want)
+		}
+		if !errors.Is(got, tt.err) {
+			t.Errorf("bedrockError(%T) doesn't wrap the original error", tt.err)
+		}
+	}
+}
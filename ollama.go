@@ -0,0 +1,338 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	ollamaDefaultHost  = "http://localhost:11434"
+	ollamaDefaultModel = "llama3.1"
+	ollamaMaxToolTurns = 3 // Search rounds before forcing a final answer
+)
+
+func init() {
+	Register(&OllamaProvider{})
+}
+
+// OllamaProvider implements Provider for a local Ollama server. Ollama doesn't run
+// tools itself: the model is offered a web_search function, and calls are executed
+// against OLLAMA_SEARCH_URL (e.g. an MCP search shim) and fed back as tool messages.
+// The HTTP client is created on first use and reused across queries.
+type OllamaProvider struct {
+	clientOnce sync.Once
+	client     *http.Client
+	variant    modelVariant
+}
+
+func (p *OllamaProvider) Name() string { return p.variant.name("ollama") }
+func (p *OllamaProvider) DisplayName() string {
+	return p.variant.displayName(p.Name(), "Ollama ("+ollamaModel()+")")
+}
+func (p *OllamaProvider) Emoji() string   { return "🦙" }
+func (p *OllamaProvider) ModelID() string { return p.variant.modelOr(ollamaModel()) }
+
+// WithModel returns an instance for another locally pulled model.
+func (p *OllamaProvider) WithModel(label, model string) (Provider, error) {
+	return &OllamaProvider{variant: modelVariant{label: label, model: model}}, nil
+}
+
+// BaseURL returns the Ollama host from OLLAMA_HOST or the default. Like the ollama
+// CLI, a bare host:port is accepted and assumed to be http.
+func (p *OllamaProvider) BaseURL() string {
+	host := resolveBaseURL("", "OLLAMA_HOST", ollamaDefaultHost)
+	if !strings.Contains(host, "://") {
+		host = "http://" + host
+	}
+	return host
+}
+
+// ollamaModel returns the local model name from OLLAMA_MODEL or the default.
+func ollamaModel() string {
+	if m := os.Getenv("OLLAMA_MODEL"); m != "" {
+		return m
+	}
+	return ollamaDefaultModel
+}
+
+func (p *OllamaProvider) Capabilities() Capabilities { return Capabilities{SystemPrompt: true} }
+
+// DefaultTimeout allows for local generation across several tool-calling turns.
+func (p *OllamaProvider) DefaultTimeout() time.Duration { return 5 * time.Minute }
+
+// CheckAuth pings the Ollama host; there are no credentials to check.
+func (p *OllamaProvider) CheckAuth() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", p.BaseURL()+"/api/version", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := p.httpClient().Do(req)
+	if err != nil {
+		return &AuthError{
+			Reason: "Ollama not reachable at " + p.BaseURL(),
+			Hint:   "start it with `ollama serve` or set OLLAMA_HOST",
+		}
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return &AuthError{
+			Reason: fmt.Sprintf("Ollama at %s returned status %d", p.BaseURL(), resp.StatusCode),
+			Hint:   "check that OLLAMA_HOST points at an Ollama server",
+		}
+	}
+	return nil
+}
+
+func (p *OllamaProvider) httpClient() *http.Client {
+	p.clientOnce.Do(func() { p.client = newHTTPClient(10 * time.Minute) })
+	return p.client
+}
+
+func (p *OllamaProvider) Query(ctx context.Context, query string, v Verbosity) Result {
+	return p.QueryConversation(ctx, []Message{{Role: RoleUser, Text: query}}, v)
+}
+
+func (p *OllamaProvider) QueryConversation(ctx context.Context, history []Message, v Verbosity) Result {
+	start := time.Now()
+	result := Result{}
+
+	reqBody := ollamaRequest{
+		Model:    p.ModelID(),
+		Messages: ollamaMessages(history),
+		Tools:    []ollamaTool{ollamaSearchTool},
+		Stream:   false,
+	}
+
+	if dryRun {
+		return dryRunResult(p, map[string]any{
+			"endpoint": p.BaseURL() + "/api/chat",
+			"body":     reqBody,
+		})
+	}
+
+	seen := make(map[string]bool)
+	for turn := 0; ; turn++ {
+		// Withhold tools on the last turn so the model has to answer.
+		if turn == ollamaMaxToolTurns {
+			reqBody.Tools = nil
+		}
+
+		slog.Debug("sending request", "provider", p.Name(), "model", reqBody.Model, "turn", turn+1)
+
+		turnStart := time.Now()
+		resp, err := p.chat(ctx, reqBody, turn)
+		addTimelineStep(&result, StepGenerate, fmt.Sprintf("turn %d", turn+1), time.Since(turnStart))
+		if err != nil {
+			result.Duration = time.Since(start)
+			result.Error = err
+			return result
+		}
+
+		result.Tokens.Input += resp.PromptEvalCount
+		result.Tokens.Output += resp.EvalCount
+
+		if len(resp.Message.ToolCalls) == 0 {
+			result.Duration = time.Since(start)
+			result.Text = resp.Message.Content
+			keepRaw(&result, resp.raw) // The final turn; -dump-dir keeps every turn
+			result.FinishReason = resp.DoneReason
+			return result
+		}
+
+		reqBody.Messages = append(reqBody.Messages, resp.Message)
+		for _, call := range resp.Message.ToolCalls {
+			logToolCall(v, p.Name(), call.Function.Name, call.Function.Arguments)
+			searchStart := time.Now()
+			content, err := p.runSearch(ctx, call, &result, seen)
+			if call.Function.Name == ollamaSearchTool.Function.Name {
+				query, _ := call.Function.Arguments["query"].(string)
+				addSearchStep(&result, query, time.Since(searchStart))
+			} else {
+				addTimelineStep(&result, StepTool, call.Function.Name, time.Since(searchStart))
+			}
+			if err != nil {
+				result.Duration = time.Since(start)
+				result.Error = err
+				return result
+			}
+			reqBody.Messages = append(reqBody.Messages, ollamaMessage{Role: "tool", Content: content})
+		}
+	}
+}
+
+// chat sends one non-streaming /api/chat request. turn numbers the -dump-dir
+// files, since a query can take several tool-calling turns.
+func (p *OllamaProvider) chat(ctx context.Context, reqBody ollamaRequest, turn int) (*ollamaResponse, error) {
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("marshal error: %w", err)
+	}
+	dumpName := fmt.Sprintf("%s-turn%d", p.Name(), turn+1)
+	dumpBytes(dumpName+"-request", jsonData)
+
+	req, err := http.NewRequestWithContext(ctx, "POST", p.BaseURL()+"/api/chat", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("request error: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.httpClient().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("API error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read error: %w", err)
+	}
+	dumpBytes(dumpName+"-response", body)
+	if resp.StatusCode != http.StatusOK {
+		return nil, &statusError{StatusCode: resp.StatusCode, Message: string(body)}
+	}
+
+	var ollamaResp ollamaResponse
+	if err := json.Unmarshal(body, &ollamaResp); err != nil {
+		return nil, fmt.Errorf("%w: %w", errParse, err)
+	}
+	ollamaResp.raw = body
+	return &ollamaResp, nil
+}
+
+// runSearch executes a web_search tool call against OLLAMA_SEARCH_URL, records the
+// results as citations, and returns them formatted for the model.
+func (p *OllamaProvider) runSearch(ctx context.Context, call ollamaToolCall, result *Result, seen map[string]bool) (string, error) {
+	if call.Function.Name != ollamaSearchTool.Function.Name {
+		return fmt.Sprintf("unknown tool %q", call.Function.Name), nil
+	}
+
+	searchURL := os.Getenv("OLLAMA_SEARCH_URL")
+	if searchURL == "" {
+		return "", fmt.Errorf("model called web_search but OLLAMA_SEARCH_URL is not set")
+	}
+
+	query, _ := call.Function.Arguments["query"].(string)
+
+	jsonData, err := json.Marshal(map[string]string{"query": query})
+	if err != nil {
+		return "", fmt.Errorf("marshal error: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", searchURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return "", fmt.Errorf("search request error: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.httpClient().Do(req)
+	if err != nil {
+		return "", fmt.Errorf("search error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		result.SearchError = fmt.Sprintf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+		return "search failed: " + result.SearchError, nil
+	}
+
+	var hits []ollamaSearchResult
+	if err := json.NewDecoder(resp.Body).Decode(&hits); err != nil {
+		return "", fmt.Errorf("search parse error: %w", err)
+	}
+
+	result.SearchResults += len(hits)
+	var b strings.Builder
+	for i, h := range hits {
+		DeduplicateCitations(&result.Citations, seen, Citation{URL: h.URL, Title: h.Title})
+		fmt.Fprintf(&b, "[%d] %s\n%s\n%s\n\n", i+1, h.Title, h.URL, h.Content)
+	}
+	if b.Len() == 0 {
+		return "no results", nil
+	}
+	return b.String(), nil
+}
+
+// ollamaMessages maps conversation history to chat messages.
+func ollamaMessages(history []Message) []ollamaMessage {
+	messages := make([]ollamaMessage, 0, len(history)+1)
+	if systemPrompt != "" {
+		messages = append(messages, ollamaMessage{Role: "system", Content: systemPrompt})
+	}
+	for _, m := range history {
+		messages = append(messages, ollamaMessage{Role: m.Role, Content: m.Text})
+	}
+	return messages
+}
+
+// --- Ollama API Types ---
+
+type ollamaRequest struct {
+	Model    string          `json:"model"`
+	Messages []ollamaMessage `json:"messages"`
+	Tools    []ollamaTool    `json:"tools,omitempty"`
+	Stream   bool            `json:"stream"`
+}
+
+type ollamaMessage struct {
+	Role      string           `json:"role"`
+	Content   string           `json:"content"`
+	ToolCalls []ollamaToolCall `json:"tool_calls,omitempty"`
+}
+
+type ollamaToolCall struct {
+	Function struct {
+		Name      string         `json:"name"`
+		Arguments map[string]any `json:"arguments"`
+	} `json:"function"`
+}
+
+type ollamaTool struct {
+	Type     string `json:"type"`
+	Function struct {
+		Name        string         `json:"name"`
+		Description string         `json:"description"`
+		Parameters  map[string]any `json:"parameters"`
+	} `json:"function"`
+}
+
+type ollamaResponse struct {
+	raw []byte // Undecoded body, for -include-raw
+
+	Message         ollamaMessage `json:"message"`
+	DoneReason      string        `json:"done_reason"`
+	PromptEvalCount int           `json:"prompt_eval_count"`
+	EvalCount       int           `json:"eval_count"`
+}
+
+// ollamaSearchResult is one hit returned by the OLLAMA_SEARCH_URL endpoint.
+type ollamaSearchResult struct {
+	Title   string `json:"title"`
+	URL     string `json:"url"`
+	Content string `json:"content"`
+}
+
+var ollamaSearchTool = func() ollamaTool {
+	t := ollamaTool{Type: "function"}
+	t.Function.Name = "web_search"
+	t.Function.Description = "Search the web for current information. Cite result URLs in your answer."
+	t.Function.Parameters = map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"query": map[string]any{"type": "string", "description": "Search query"},
+		},
+		"required": []string{"query"},
+	}
+	return t
+}()
@@ -0,0 +1,307 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// API styles a -providers-file entry can speak.
+const (
+	compatAPIResponses = "responses" // POST /responses with a web_search tool (default)
+	compatAPIChat      = "chat"      // POST /chat/completions with web_search_options
+)
+
+// compatNameRegex is what a -providers-file name may look like: it's typed in
+// -providers and used in -dump-dir file names.
+var compatNameRegex = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
+
+// compatProviderConfig is one entry in a -providers-file:
+//
+//	providers:
+//	  - name: openrouter
+//	    display_name: GPT-4o (OpenRouter)
+//	    base_url: https://openrouter.ai/api/v1
+//	    model: openai/gpt-4o
+//	    api_key_env: OPENROUTER_API_KEY
+//	    api: chat            # responses (default) or chat
+//	    web_search: true
+//	    input_price: 2.50    # USD per million tokens, optional
+//	    output_price: 10.00
+type compatProviderConfig struct {
+	Name        string  `yaml:"name"`
+	DisplayName string  `yaml:"display_name"`
+	BaseURL     string  `yaml:"base_url"`
+	Model       string  `yaml:"model"`
+	APIKeyEnv   string  `yaml:"api_key_env"` // Empty for servers that need no key
+	API         string  `yaml:"api"`
+	WebSearch   bool    `yaml:"web_search"`
+	InputPrice  float64 `yaml:"input_price"`
+	OutputPrice float64 `yaml:"output_price"`
+}
+
+// validate checks an entry against the registry so far; base URLs and names
+// are checked here rather than at query time.
+func (c *compatProviderConfig) validate() error {
+	switch {
+	case c.Name == "":
+		return fmt.Errorf("missing name")
+	case !compatNameRegex.MatchString(c.Name):
+		return fmt.Errorf("name %q must be lowercase letters, digits, - or _", c.Name)
+	case c.Model == "":
+		return fmt.Errorf("missing model")
+	case c.BaseURL == "":
+		return fmt.Errorf("missing base_url")
+	case c.InputPrice < 0 || c.OutputPrice < 0:
+		return fmt.Errorf("prices must be >= 0")
+	}
+	if _, ok := Get(c.Name); ok {
+		return fmt.Errorf("name %q is already a provider", c.Name)
+	}
+	if err := validateBaseURL(c.BaseURL); err != nil {
+		return fmt.Errorf("invalid base_url %q: %v", c.BaseURL, err)
+	}
+	switch c.API {
+	case "":
+		c.API = compatAPIResponses
+	case compatAPIResponses, compatAPIChat:
+	default:
+		return fmt.Errorf("invalid api %q (use %s or %s)", c.API, compatAPIResponses, compatAPIChat)
+	}
+	return nil
+}
+
+// loadProvidersFile registers an OpenAICompatibleProvider for each valid entry
+// in a -providers-file. Malformed entries are skipped with a warning; an
+// unreadable file is an error.
+func loadProvidersFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read providers file: %w", err)
+	}
+	var file struct {
+		Providers []compatProviderConfig `yaml:"providers"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("parse providers file %s: %w", path, err)
+	}
+	if len(file.Providers) == 0 {
+		return fmt.Errorf("providers file %s has no providers", path)
+	}
+
+	for i, cfg := range file.Providers {
+		if err := cfg.validate(); err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  %s: skipping provider %d: %v\n", path, i+1, err)
+			continue
+		}
+		if cfg.InputPrice > 0 || cfg.OutputPrice > 0 {
+			Pricing[cfg.Name] = struct{ Input, Output, CachedInput float64 }{cfg.InputPrice, cfg.OutputPrice, 0}
+		}
+		Register(&OpenAICompatibleProvider{cfg: cfg})
+	}
+	return nil
+}
+
+// OpenAICompatibleProvider implements Provider for an endpoint defined in a
+// -providers-file (OpenRouter, Together, Groq, DeepSeek, a local vLLM, ...).
+type OpenAICompatibleProvider struct {
+	cfg     compatProviderConfig
+	api     responsesAPI
+	variant modelVariant
+}
+
+func (p *OpenAICompatibleProvider) Name() string { return p.variant.name(p.cfg.Name) }
+func (p *OpenAICompatibleProvider) DisplayName() string {
+	if p.variant.label != "" {
+		return p.variant.displayName(p.Name(), "")
+	}
+	if p.cfg.DisplayName != "" {
+		return p.cfg.DisplayName
+	}
+	return fmt.Sprintf("%s (%s)", p.cfg.Model, p.cfg.Name)
+}
+func (p *OpenAICompatibleProvider) Emoji() string   { return "🔷" }
+func (p *OpenAICompatibleProvider) ModelID() string { return p.cfg.Model }
+
+// WithModel returns an instance for another model on the same endpoint.
+func (p *OpenAICompatibleProvider) WithModel(label, model string) (Provider, error) {
+	cfg := p.cfg
+	cfg.Model = model
+	return &OpenAICompatibleProvider{cfg: cfg, variant: modelVariant{label: label, model: model}}, nil
+}
+
+// BaseURL returns the entry's base_url without a trailing slash.
+func (p *OpenAICompatibleProvider) BaseURL() string {
+	return strings.TrimRight(p.cfg.BaseURL, "/")
+}
+
+// SupportsAnswerSchema is true on the Responses API, which takes a json_schema
+// text format alongside tools.
+func (p *OpenAICompatibleProvider) SupportsAnswerSchema() bool {
+	return p.cfg.API == compatAPIResponses
+}
+
+// Capabilities: images are sent only over the Responses API.
+func (p *OpenAICompatibleProvider) Capabilities() Capabilities {
+	return Capabilities{SystemPrompt: true, Vision: p.cfg.API == compatAPIResponses}
+}
+
+func (p *OpenAICompatibleProvider) CheckAuth() error {
+	if p.cfg.APIKeyEnv != "" && os.Getenv(p.cfg.APIKeyEnv) == "" {
+		return &AuthError{Reason: p.cfg.APIKeyEnv + " not set", Hint: "export " + p.cfg.APIKeyEnv + "=... (from -providers-file)"}
+	}
+	return nil
+}
+
+func (p *OpenAICompatibleProvider) Query(ctx context.Context, query string, v Verbosity) Result {
+	return p.QueryConversation(ctx, []Message{{Role: RoleUser, Text: query}}, v)
+}
+
+func (p *OpenAICompatibleProvider) QueryConversation(ctx context.Context, history []Message, v Verbosity) Result {
+	if p.cfg.API == compatAPIChat {
+		return p.queryChat(ctx, history)
+	}
+
+	start := time.Now()
+	result := Result{}
+
+	reqBody := newResponsesRequest(p.cfg.Model, history)
+	if !p.cfg.WebSearch {
+		reqBody.Tools = nil
+	}
+
+	if dryRun {
+		return dryRunResult(p, map[string]any{
+			"endpoint": p.BaseURL() + "/responses",
+			"body":     reqBody,
+		})
+	}
+
+	slog.Debug("sending request", "provider", p.Name(), "model", p.cfg.Model, "web_search", p.cfg.WebSearch)
+
+	resp, err := p.api.send(ctx, p.Name(), p.BaseURL(), os.Getenv(p.cfg.APIKeyEnv), reqBody)
+	result.Duration = time.Since(start)
+	if err != nil {
+		result.Error = err
+		return result
+	}
+
+	keepRaw(&result, resp.raw)
+	parseResponsesOutput(resp, &result)
+	logResponsesSearches(v, p.Name(), resp)
+	return result
+}
+
+// queryChat sends history to /chat/completions, for endpoints without the
+// Responses API. Search results come back as url_citation annotations.
+func (p *OpenAICompatibleProvider) queryChat(ctx context.Context, history []Message) Result {
+	start := time.Now()
+	result := Result{}
+
+	reqBody := chatRequest{Model: p.cfg.Model}
+	if systemPrompt != "" {
+		reqBody.Messages = append(reqBody.Messages, chatMessage{Role: "system", Content: systemPrompt})
+	}
+	for _, m := range history {
+		reqBody.Messages = append(reqBody.Messages, chatMessage{Role: m.Role, Content: m.Text})
+	}
+	if p.cfg.WebSearch {
+		reqBody.WebSearchOptions = &struct{}{}
+	}
+
+	if dryRun {
+		return dryRunResult(p, map[string]any{
+			"endpoint": p.BaseURL() + "/chat/completions",
+			"body":     reqBody,
+		})
+	}
+
+	slog.Debug("sending request", "provider", p.Name(), "model", p.cfg.Model, "web_search", p.cfg.WebSearch)
+
+	body, err := p.api.post(ctx, p.Name(), p.BaseURL()+"/chat/completions", os.Getenv(p.cfg.APIKeyEnv), reqBody)
+	result.Duration = time.Since(start)
+	if err != nil {
+		result.Error = err
+		return result
+	}
+
+	var resp chatResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		result.Error = fmt.Errorf("%w: %w (body: %s)", errParse, err, bodySnippet(body))
+		return result
+	}
+	keepRaw(&result, body)
+
+	if resp.Usage != nil {
+		result.Tokens.Input = resp.Usage.PromptTokens
+		result.Tokens.Output = resp.Usage.CompletionTokens
+		result.Tokens.CachedInput = resp.Usage.PromptTokensDetails.CachedTokens
+		result.Tokens.Reasoning = resp.Usage.CompletionTokensDetails.ReasoningTokens
+	}
+
+	seen := make(map[string]bool)
+	if len(resp.Choices) > 0 {
+		choice := resp.Choices[0]
+		result.Text = choice.Message.Content
+		result.FinishReason = choice.FinishReason
+		AppendThinking(&result, choice.Message.ReasoningContent)
+		for _, a := range choice.Message.Annotations {
+			if a.Type == "url_citation" {
+				MergeCitation(&result.Citations, seen, Citation{URL: a.URLCitation.URL, Title: a.URLCitation.Title})
+			}
+		}
+	}
+	// Some gateways list sources at the top level instead of annotating the text.
+	for _, u := range resp.Citations {
+		DeduplicateCitations(&result.Citations, seen, Citation{URL: u})
+	}
+	return result
+}
+
+// --- Chat Completions API Types ---
+
+type chatRequest struct {
+	Model            string        `json:"model"`
+	Messages         []chatMessage `json:"messages"`
+	WebSearchOptions *struct{}     `json:"web_search_options,omitempty"`
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatResponse struct {
+	Choices []struct {
+		FinishReason string `json:"finish_reason"`
+		Message      struct {
+			Content          string `json:"content"`
+			ReasoningContent string `json:"reasoning_content"` // DeepSeek reasoner
+			Annotations      []struct {
+				Type        string `json:"type"`
+				URLCitation struct {
+					URL   string `json:"url"`
+					Title string `json:"title"`
+				} `json:"url_citation"`
+			} `json:"annotations"`
+		} `json:"message"`
+	} `json:"choices"`
+	Citations []string `json:"citations"`
+	Usage     *struct {
+		PromptTokens        int `json:"prompt_tokens"`
+		CompletionTokens    int `json:"completion_tokens"`
+		PromptTokensDetails struct {
+			CachedTokens int `json:"cached_tokens"`
+		} `json:"prompt_tokens_details"`
+		CompletionTokensDetails struct {
+			ReasoningTokens int `json:"reasoning_tokens"`
+		} `json:"completion_tokens_details"`
+	} `json:"usage"`
+}
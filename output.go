@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Output formats for -format.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Output settings: -quiet prints only the winning answer; -format json replaces
+// the report with a JSON document.
+var (
+	quiet        bool
+	outputFormat = FormatText
+)
+
+// reportSuppressed reports whether the normal report is replaced by
+// printFinalOutput.
+func reportSuppressed() bool {
+	return quiet || outputFormat == FormatJSON
+}
+
+// silenceStdout sends everything printed to stdout to the null device until
+// restore is called, so the usual progress and report output can run unchanged
+// underneath -quiet and -format json. Errors still go to stderr.
+func silenceStdout() (restore func()) {
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		return func() {}
+	}
+	orig := os.Stdout
+	os.Stdout = devNull
+	return func() {
+		os.Stdout = orig
+		devNull.Close()
+	}
+}
+
+// printFinalOutput prints ranked results for -quiet and -format json: the
+// full JSON record, or with -quiet only the top-ranked successful answer
+// (its text and sources, or its JSON object).
+func printFinalOutput(query string, results []ModelResult) error {
+	if !quiet {
+		return printJSON(newJSONQueryRecord(query, results))
+	}
+
+	winner := topRanked(results)
+	if winner == nil {
+		return fmt.Errorf("no provider returned an answer")
+	}
+
+	if outputFormat == FormatJSON {
+		return printJSON(newJSONModelResult(*winner))
+	}
+
+	fmt.Println(stripThinkingTags(winner.Result.Text))
+	if len(winner.Result.Citations) > 0 {
+		fmt.Println()
+		fmt.Println("Sources:")
+		nums := referenceNumbers(winner.Result.Citations)
+		for i, c := range winner.Result.Citations {
+			if c.Title != "" {
+				fmt.Printf("%d. %s - %s\n", nums[i], c.Title, displayURL(c.URL))
+			} else {
+				fmt.Printf("%d. %s\n", nums[i], displayURL(c.URL))
+			}
+		}
+	}
+	return nil
+}
+
+func printJSON(v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal output: %w", err)
+	}
+	fmt.Println(string(data))
+	return nil
+}
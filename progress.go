@@ -0,0 +1,164 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// progressInterval is how often the live status board redraws.
+const progressInterval = 100 * time.Millisecond
+
+// spinnerFrames animate providers that are still searching.
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+type progressState int
+
+const (
+	progressPending progressState = iota
+	progressSearching
+	progressDone
+	progressFailed
+)
+
+type providerProgress struct {
+	state     progressState
+	started   time.Time
+	duration  time.Duration
+	citations int
+}
+
+// progressBoard is the live per-provider status shown while runAllModels
+// waits for results. It is drawn in place below the run banner and erased on
+// Stop, so the formatted results that follow are unchanged. A nil board (see
+// newProgressBoard) ignores every call.
+type progressBoard struct {
+	mu        sync.Mutex
+	providers []Provider
+	status    map[string]*providerProgress
+	frame     int
+	drawn     int // Lines currently on screen
+	stop      chan struct{}
+	stopped   chan struct{}
+	stopOnce  sync.Once
+}
+
+// newProgressBoard starts a status board for providers, or returns nil when
+// stdout isn't a terminal, under -v (which logs progress instead), -dry-run
+// (which prints requests), or -quiet / -format json.
+func newProgressBoard(providers []Provider) *progressBoard {
+	if verbosity >= VerbosityVerbose || dryRun || reportSuppressed() || !term.IsTerminal(int(os.Stdout.Fd())) {
+		return nil
+	}
+	b := &progressBoard{
+		providers: providers,
+		status:    make(map[string]*providerProgress, len(providers)),
+		stop:      make(chan struct{}),
+		stopped:   make(chan struct{}),
+	}
+	for _, p := range providers {
+		b.status[p.Name()] = &providerProgress{}
+	}
+	go b.run()
+	return b
+}
+
+// Start marks p as searching.
+func (b *progressBoard) Start(p Provider) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	s := b.status[p.Name()]
+	s.state = progressSearching
+	s.started = time.Now()
+}
+
+// Finish records p's outcome.
+func (b *progressBoard) Finish(p Provider, r Result) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	s := b.status[p.Name()]
+	s.state = progressDone
+	if r.Error != nil {
+		s.state = progressFailed
+	}
+	s.duration = time.Since(s.started)
+	s.citations = len(r.Citations)
+}
+
+// Stop halts redrawing and erases the board.
+func (b *progressBoard) Stop() {
+	if b == nil {
+		return
+	}
+	b.stopOnce.Do(func() {
+		close(b.stop)
+		<-b.stopped
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.erase()
+	})
+}
+
+func (b *progressBoard) run() {
+	defer close(b.stopped)
+	ticker := time.NewTicker(progressInterval)
+	defer ticker.Stop()
+	for {
+		b.mu.Lock()
+		b.draw()
+		b.mu.Unlock()
+		select {
+		case <-ticker.C:
+		case <-b.stop:
+			return
+		}
+	}
+}
+
+// draw rewrites the board in place. Callers hold b.mu.
+func (b *progressBoard) draw() {
+	var out strings.Builder
+	if b.drawn > 0 {
+		fmt.Fprintf(&out, "\033[%dA", b.drawn)
+	}
+	spinner := spinnerFrames[b.frame%len(spinnerFrames)]
+	b.frame++
+	for _, p := range b.providers {
+		s := b.status[p.Name()]
+		var icon, detail string
+		switch s.state {
+		case progressPending:
+			icon, detail = "⏳", dim("pending")
+		case progressSearching:
+			icon, detail = spinner+" ", fmt.Sprintf("searching... %.1fs", time.Since(s.started).Seconds())
+		case progressDone:
+			icon, detail = "✅", green(fmt.Sprintf("done %.1fs", s.duration.Seconds()))+dim(fmt.Sprintf(" (%d citations)", s.citations))
+		case progressFailed:
+			icon, detail = "❌", red(fmt.Sprintf("error %.1fs", s.duration.Seconds()))
+		}
+		// The provider emoji is two columns wide, so the name pads one less.
+		fmt.Fprintf(&out, "\r\033[K   %s %-25s %s\n", icon, p.Emoji()+" "+p.DisplayName(), detail)
+	}
+	b.drawn = len(b.providers)
+	fmt.Print(out.String())
+}
+
+// erase clears the board's lines and leaves the cursor where it started.
+// Callers hold b.mu.
+func (b *progressBoard) erase() {
+	if b.drawn == 0 {
+		return
+	}
+	fmt.Printf("\033[%dA\r\033[J", b.drawn)
+	b.drawn = 0
+}
@@ -2,8 +2,18 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
 	"sort"
+	"strings"
 	"time"
+	"unicode/utf8"
+
+	"golang.org/x/net/publicsuffix"
 )
 
 // Provider defines the interface for AI model providers with web search.
@@ -21,64 +31,126 @@ type Provider interface {
 	CheckAuth() error
 
 	// Query performs a web-grounded search and returns the result
-	Query(ctx context.Context, query string, verbose bool) Result
+	Query(ctx context.Context, query string, v Verbosity) Result
 }
 
 // Citation represents a web source citation.
 type Citation struct {
-	URL    string
-	Domain string
-	Title  string
+	URL         string
+	Domain      string
+	Title       string
+	PublishedAt *time.Time // Publication date, when the provider or URL exposes one
+	Snippet     string     // Text the source was cited for, when the provider returns it
+	StartIndex  int        // Byte range in Result.Text the citation supports; both 0 if unknown
+	EndIndex    int
+	Index       int // In-text reference number ([n]) where the provider marks one; 0 if unknown
 }
 
 // TokenUsage tracks token counts for cost calculation.
 type TokenUsage struct {
-	Input  int
-	Output int
+	Input       int
+	Output      int
+	CachedInput int // Portion of Input served from the provider's prompt cache
+	Reasoning   int // Portion of Output spent on reasoning
 }
 
 // Result holds a provider's response with performance metrics.
 type Result struct {
-	Text      string
-	Citations []Citation
-	Duration  time.Duration
-	Tokens    TokenUsage
-	Error     error
+	Text              string
+	Thinking          string // Reasoning returned out-of-band (e.g. Claude thinking blocks); shown with -thinking
+	Citations         []Citation
+	Duration          time.Duration
+	Tokens            TokenUsage
+	Error             error
+	SearchResults     int             // Results returned by the search tool, where the provider reports them
+	SearchError       string          // Search tool failure reason (e.g. "max_uses_exceeded"); the answer may still be present
+	RePrompted        bool            // A follow-up turn asked for more citations (-min-citations)
+	Language          string          // Detected ISO 639-1 code of Text, "" if uncertain
+	FilteredCitations int             // Citations dropped by -allow-domains / -block-domains
+	FinishReason      string          // Stop reason as the provider reports it (e.g. "end_turn", "MAX_TOKENS"), "" if unknown
+	Ungrounded        bool            // Answered without web search after the search tool failed (-allow-ungrounded-fallback)
+	Raw               json.RawMessage // Provider's raw response, kept only under -include-raw
+	Cached            bool            // Served from the -cache-responses disk cache
+	ErrorKind         ErrorKind       // Category of Error, set by normalizeResult; "" on success
+	SearchCount       int             // Web searches the model ran, where the provider reports them
+	Timeline          []TimelineStep  // Searches, tool calls, and turns in order; shown with -vv
 }
 
+// Reasoning effort levels for -reasoning. ReasoningOff keeps each provider's default.
+const (
+	ReasoningOff    = "off"
+	ReasoningLow    = "low"
+	ReasoningMedium = "medium"
+	ReasoningHigh   = "high"
+)
+
 // Pricing per million tokens (USD).
-var Pricing = map[string]struct{ Input, Output float64 }{
-	"nova":   {2.50, 12.50},  // Nova Premier
-	"claude": {3.00, 15.00},  // Claude 4.5 Sonnet
-	"gemini": {2.00, 12.00},  // Gemini 3 Pro
-	"grok":   {3.00, 15.00},  // Grok 4
+// CachedInput is the discounted rate for cached prompt tokens; 0 means no discount.
+var Pricing = map[string]struct{ Input, Output, CachedInput float64 }{
+	"nova":    {2.50, 12.50, 0},    // Nova Premier
+	"claude":  {3.00, 15.00, 0.30}, // Claude 4.5 Sonnet
+	"gemini":  {2.00, 12.00, 0.20}, // Gemini 3 Pro
+	"grok":    {3.00, 15.00, 0.75}, // Grok 4
+	"cohere":  {2.50, 10.00, 0},    // Command R+ 08-2024
+	"ollama":  {0, 0, 0},           // Local model, no billing
+	"mistral": {0.40, 2.00, 0},     // Mistral Medium 3
 }
 
 // SearchCost per grounded query (USD).
 // These are estimated costs for web search/grounding tools.
 var SearchCost = map[string]float64{
-	"nova":   0.01,  // Estimated - not published by AWS
-	"claude": 0.01,  // $10 per 1,000 searches
-	"gemini": 0.035, // $35 per 1,000 grounded prompts
-	"grok":   0.00,  // Included in token pricing
+	"nova":    0.01,  // Estimated - not published by AWS
+	"claude":  0.01,  // $10 per 1,000 searches
+	"gemini":  0.035, // $35 per 1,000 grounded prompts
+	"grok":    0.00,  // Included in token pricing
+	"cohere":  0.00,  // web-search connector not billed separately
+	"ollama":  0.00,  // Local search shim
+	"mistral": 0.03,  // $30 per 1,000 web search calls
+}
+
+// MaxTokenEstimate is the worst-case token usage per query, used for -budget pre-checks
+// before real usage is known. Input includes search results injected by grounding tools.
+var MaxTokenEstimate = map[string]TokenUsage{
+	"nova":    {Input: 10_000, Output: 4_096},
+	"claude":  {Input: 50_000, Output: 4_096}, // web_search results count as input
+	"gemini":  {Input: 5_000, Output: 8_192},
+	"grok":    {Input: 40_000, Output: 8_192},
+	"cohere":  {Input: 20_000, Output: 4_096}, // connector documents count as input
+	"mistral": {Input: 20_000, Output: 4_096}, // connector_tokens (search results) count as input
 }
 
 // TokenCost calculates USD cost from token usage only.
+// Cached input tokens are billed at the provider's CachedInput rate when it has one.
 func (r Result) TokenCost(provider string) float64 {
 	p, ok := Pricing[provider]
-	if !ok {
+	if !ok || r.Cached {
 		return 0
 	}
-	return (float64(r.Tokens.Input)*p.Input + float64(r.Tokens.Output)*p.Output) / 1_000_000
+	cachedRate := p.CachedInput
+	if cachedRate == 0 {
+		cachedRate = p.Input
+	}
+	uncached := r.Tokens.Input - r.Tokens.CachedInput
+	return (float64(uncached)*p.Input + float64(r.Tokens.CachedInput)*cachedRate + float64(r.Tokens.Output)*p.Output) / 1_000_000
 }
 
 // EstimatedCost calculates total estimated cost (tokens + search).
+// Ungrounded answers carry no search fee, and cached ones cost nothing.
 func (r Result) EstimatedCost(provider string) float64 {
 	tokenCost := r.TokenCost(provider)
 	searchCost := SearchCost[provider]
+	if r.Ungrounded || r.Cached {
+		searchCost = 0
+	}
 	return tokenCost + searchCost
 }
 
+// WorstCaseCost estimates the maximum cost of one query using MaxTokenEstimate.
+func WorstCaseCost(provider string) float64 {
+	worst := Result{Tokens: MaxTokenEstimate[provider]}
+	return worst.EstimatedCost(provider)
+}
+
 // --- Provider Registry ---
 
 var providers = make(map[string]Provider)
@@ -104,10 +176,38 @@ func All() []string {
 	return names
 }
 
+// BaseURLProvider is implemented by HTTP providers whose API base can be
+// overridden (proxies, gateways like LiteLLM, regional endpoints).
+type BaseURLProvider interface {
+	BaseURL() string
+}
+
+// proxyTransport is shared by every client from newHTTPClient so connections
+// are pooled across providers, link checks, and archiving.
+var proxyTransport = func() *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.Proxy = http.ProxyFromEnvironment
+	return t
+}()
+
+// newHTTPClient returns a client with the given timeout that routes through
+// HTTP_PROXY / HTTPS_PROXY and honors NO_PROXY. Every http.Client the CLI
+// constructs should come from here so corporate proxies work everywhere.
+func newHTTPClient(timeout time.Duration) *http.Client {
+	return &http.Client{Timeout: timeout, Transport: proxyTransport}
+}
+
+// httpDoer is the part of *http.Client that HTTP providers use, so tests can
+// substitute a stub that returns canned responses.
+type httpDoer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
 // JudgeScore holds LLM judge evaluation scores (each 1-10).
 type JudgeScore struct {
 	Quality      int     // Content coherence, depth, accuracy
 	LinkHealth   int     // Based on HTTP HEAD validation (% of working links)
+	Diversity    int     // Unique source domains relative to citation count
 	Recency      int     // How current/recent the cited sources are
 	Significance int     // Newsworthy? WSJ front-page worthy?
 	Impact       int     // Business or topic impact
@@ -117,10 +217,302 @@ type JudgeScore struct {
 
 // --- Shared Helpers ---
 
+// AppendThinking adds a reasoning segment to Result.Thinking, separating segments with a blank line.
+func AppendThinking(r *Result, thinking string) {
+	thinking = strings.TrimSpace(thinking)
+	if thinking == "" {
+		return
+	}
+	if r.Thinking != "" {
+		r.Thinking += "\n\n"
+	}
+	r.Thinking += thinking
+}
+
 // DeduplicateCitations adds a citation if the URL hasn't been seen.
+// Missing domains and publication dates are derived from the URL when possible.
 func DeduplicateCitations(citations *[]Citation, seen map[string]bool, c Citation) {
 	if c.URL != "" && !seen[c.URL] {
 		seen[c.URL] = true
+		if c.Domain == "" {
+			c.Domain = domainFromURL(c.URL)
+		}
+		if c.PublishedAt == nil {
+			c.PublishedAt = publishedDateFromURL(c.URL)
+		}
 		*citations = append(*citations, c)
 	}
 }
+
+// MergeCitation is DeduplicateCitations for sources that may arrive twice
+// with different metadata: a repeated URL fills in whatever the stored copy
+// lacks (title, snippet, reference number, date) instead of being dropped.
+func MergeCitation(citations *[]Citation, seen map[string]bool, c Citation) {
+	if c.URL == "" || !seen[c.URL] {
+		DeduplicateCitations(citations, seen, c)
+		return
+	}
+	for i := range *citations {
+		if (*citations)[i].URL == c.URL {
+			fillCitation(&(*citations)[i], c)
+			return
+		}
+	}
+}
+
+// fillCitation copies c's metadata into dst's empty fields.
+func fillCitation(dst *Citation, c Citation) {
+	if dst.Title == "" {
+		dst.Title = c.Title
+	}
+	if dst.Domain == "" {
+		dst.Domain = c.Domain
+	}
+	if dst.Snippet == "" {
+		dst.Snippet = c.Snippet
+	}
+	if dst.Index == 0 {
+		dst.Index = c.Index
+	}
+	if dst.PublishedAt == nil {
+		dst.PublishedAt = c.PublishedAt
+	}
+}
+
+// sortCitationsByIndex orders citations by in-text reference number, so the
+// numbered sources list follows the [n] markers. Citations without a number
+// keep their discovery order after the numbered ones.
+func sortCitationsByIndex(citations []Citation) {
+	sort.SliceStable(citations, func(i, j int) bool {
+		a, b := citations[i].Index, citations[j].Index
+		return a > 0 && (b == 0 || a < b)
+	})
+}
+
+// referenceNumbers returns the number to show for each citation: its Index
+// when known, otherwise the next number after every Index in use, so labels
+// never collide with the [n] markers in the text.
+func referenceNumbers(citations []Citation) []int {
+	next := 1
+	for _, c := range citations {
+		next = max(next, c.Index+1)
+	}
+	nums := make([]int, len(citations))
+	for i, c := range citations {
+		if c.Index > 0 {
+			nums[i] = c.Index
+			continue
+		}
+		nums[i] = next
+		next++
+	}
+	return nums
+}
+
+// resolveBaseURL picks an API base: the flag override if set, else the env var,
+// else the default. Trailing slashes are trimmed so paths can be appended.
+func resolveBaseURL(override, envVar, def string) string {
+	base := override
+	if base == "" {
+		base = os.Getenv(envVar)
+	}
+	if base == "" {
+		base = def
+	}
+	return strings.TrimRight(base, "/")
+}
+
+// validateBaseURL checks that base is an absolute http(s) URL.
+func validateBaseURL(base string) error {
+	u, err := url.Parse(base)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("scheme must be http or https")
+	}
+	if u.Host == "" {
+		return fmt.Errorf("missing host")
+	}
+	return nil
+}
+
+// domainFromURL returns the URL's host without a leading "www.", or "" if unparseable.
+func domainFromURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
+
+// registrableDomain reduces a host to its registrable domain (eTLD+1),
+// e.g. "news.bbc.co.uk" becomes "bbc.co.uk". Unrecognized hosts are returned as-is.
+func registrableDomain(host string) string {
+	if d, err := publicsuffix.EffectiveTLDPlusOne(host); err == nil {
+		return d
+	}
+	return host
+}
+
+// readingWPM is the reading speed assumed by ReadingTime.
+const readingWPM = 200
+
+// WordCount counts words in the answer, excluding inline thinking tags.
+func (r Result) WordCount() int {
+	return len(strings.Fields(stripThinkingTags(r.Text)))
+}
+
+// ReadingTime estimates how long the answer takes to read at readingWPM.
+func (r Result) ReadingTime() time.Duration {
+	return time.Duration(r.WordCount()) * time.Minute / readingWPM
+}
+
+// truncationReasons are the lowercased stop reasons providers report when the
+// answer hit the output token limit: Claude and Bedrock "max_tokens", Gemini and
+// Cohere "MAX_TOKENS", Responses API "max_output_tokens", Ollama "length".
+var truncationReasons = map[string]bool{"max_tokens": true, "max_output_tokens": true, "length": true}
+
+// Truncated reports whether the answer was cut off at the output token limit.
+func (r Result) Truncated() bool {
+	return truncationReasons[strings.ToLower(r.FinishReason)]
+}
+
+// UniqueDomains counts distinct registrable domains across the result's citations.
+func (r Result) UniqueDomains() int {
+	domains := make(map[string]bool)
+	for _, c := range r.Citations {
+		d := c.Domain
+		if d == "" {
+			d = domainFromURL(c.URL)
+		}
+		if d != "" {
+			domains[registrableDomain(d)] = true
+		}
+	}
+	return len(domains)
+}
+
+// DomainDiversity is unique registrable domains / distinct stories (0-1).
+// Ten citations from one site score 0.1; ten from distinct sites score 1.0.
+// The same article cited under several URLs counts once.
+func (r Result) DomainDiversity() float64 {
+	stories := len(CollapseDuplicateStories(r.Citations))
+	if stories == 0 {
+		return 0
+	}
+	return float64(r.UniqueDomains()) / float64(stories)
+}
+
+// urlDateRegex matches date segments commonly used in news URLs: /2024/03/, /2024/03/15/, /2024-03-15/.
+var urlDateRegex = regexp.MustCompile(`/((?:19|20)\d{2})[/-](0[1-9]|1[0-2])(?:[/-](0[1-9]|[12]\d|3[01]))?(?:[/-]|$)`)
+
+// publishedDateFromURL extracts a publication date from the URL path, or nil if none is present.
+// Month-only paths (/2024/03/) resolve to the first of the month.
+func publishedDateFromURL(url string) *time.Time {
+	m := urlDateRegex.FindStringSubmatch(url)
+	if m == nil {
+		return nil
+	}
+	day := m[3]
+	if day == "" {
+		day = "01"
+	}
+	t, err := time.Parse("2006-01-02", m[1]+"-"+m[2]+"-"+day)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
+
+// Near-duplicate story detection. Titles shorter than minStoryTitleLen are too
+// generic ("Home", "News") to compare, so they are never merged.
+const (
+	minStoryTitleLen  = 20
+	storyTitleMaxDiff = 0.1 // Max edit distance as a fraction of the longer title
+)
+
+var titleNonWordRegex = regexp.MustCompile(`[^\p{L}\p{N}]+`)
+
+// normalizeTitle lowercases a title and collapses punctuation and whitespace.
+func normalizeTitle(title string) string {
+	return strings.TrimSpace(titleNonWordRegex.ReplaceAllString(strings.ToLower(title), " "))
+}
+
+// sameStory reports whether two citations are the same article under different
+// URLs (AMP vs canonical, syndicated copies): same registrable domain and
+// near-identical titles.
+func sameStory(a, b Citation) bool {
+	da, db := a.Domain, b.Domain
+	if da == "" {
+		da = domainFromURL(a.URL)
+	}
+	if db == "" {
+		db = domainFromURL(b.URL)
+	}
+	if da == "" || registrableDomain(da) != registrableDomain(db) {
+		return false
+	}
+
+	ta, tb := normalizeTitle(a.Title), normalizeTitle(b.Title)
+	// Lengths are in runes, like levenshtein, so non-ASCII titles get the
+	// same edit budget as ASCII ones.
+	la, lb := utf8.RuneCountInString(ta), utf8.RuneCountInString(tb)
+	if la < minStoryTitleLen || lb < minStoryTitleLen {
+		return false
+	}
+	longer := max(la, lb)
+	return levenshtein(ta, tb) <= int(float64(longer)*storyTitleMaxDiff)
+}
+
+// CollapseDuplicateStories returns citations with same-story duplicates removed,
+// keeping the first occurrence. Exact URL duplicates are also dropped, after
+// filling in any title or snippet the kept copy lacks.
+func CollapseDuplicateStories(citations []Citation) []Citation {
+	var out []Citation
+	seen := make(map[string]bool)
+	for _, c := range citations {
+		if seen[c.URL] {
+			for i := range out {
+				if out[i].URL == c.URL {
+					fillCitation(&out[i], c)
+				}
+			}
+			continue
+		}
+		dup := false
+		for _, kept := range out {
+			if sameStory(kept, c) {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			seen[c.URL] = true
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// levenshtein returns the edit distance between a and b in runes.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"", "abc", 3},
+		{"kitten", "sitting", 3},
+		{"flaw", "lawn", 2},
+		{"same", "same", 0},
+		{"café", "cafe", 1}, // Runes, not bytes
+		{"日本語", "日本", 1},
+	}
+	for _, tt := range tests {
+		if got := levenshtein(tt.a, tt.b); got != tt.want {
+			t.Errorf("levenshtein(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+		if got := levenshtein(tt.b, tt.a); got != tt.want {
+			t.Errorf("levenshtein(%q, %q) = %d, want %d (symmetric)", tt.b, tt.a, got, tt.want)
+		}
+	}
+}
+
+func TestCollapseDuplicateStories(t *testing.T) {
+	const title = "Fed holds interest rates steady as inflation cools"
+	tests := []struct {
+		name      string
+		citations []Citation
+		want      []string // Kept URLs, in order
+	}{
+		{
+			name: "AMP and canonical copies of one story",
+			citations: []Citation{
+				{URL: "https://www.reuters.com/markets/fed-holds-rates", Title: title},
+				{URL: "https://amp.reuters.com/markets/fed-holds-rates", Title: title + "."},
+			},
+			want: []string{"https://www.reuters.com/markets/fed-holds-rates"},
+		},
+		{
+			name: "near-identical titles within the edit budget",
+			citations: []Citation{
+				{URL: "https://www.bbc.co.uk/news/business-1", Title: title},
+				{URL: "https://news.bbc.co.uk/2/business-1", Title: "Fed holds interest rate steady as inflation cools"},
+			},
+			want: []string{"https://www.bbc.co.uk/news/business-1"},
+		},
+		{
+			name: "same title on different sites",
+			citations: []Citation{
+				{URL: "https://www.reuters.com/a", Title: title},
+				{URL: "https://apnews.com/b", Title: title},
+			},
+			want: []string{"https://www.reuters.com/a", "https://apnews.com/b"},
+		},
+		{
+			name: "different stories on one site",
+			citations: []Citation{
+				{URL: "https://www.reuters.com/a", Title: title},
+				{URL: "https://www.reuters.com/b", Title: "Oil prices climb after OPEC+ extends output cuts"},
+			},
+			want: []string{"https://www.reuters.com/a", "https://www.reuters.com/b"},
+		},
+		{
+			name: "accented near-identical titles",
+			citations: []Citation{
+				{URL: "https://www.lemonde.fr/economie/a", Title: "La BCE maintient ses taux malgré une inflation tenace"},
+				{URL: "https://amp.lemonde.fr/economie/a", Title: "La BCE maintient ses taux malgre une inflation tenace"},
+			},
+			want: []string{"https://www.lemonde.fr/economie/a"},
+		},
+		{
+			// 25 runes but 75 bytes: a byte-length budget would allow 7 edits.
+			name: "different CJK stories on one site",
+			citations: []Citation{
+				{URL: "https://www.nikkei.com/a", Title: "日本銀行が政策金利を据え置き円相場は小幅に上昇した"},
+				{URL: "https://www.nikkei.com/b", Title: "日本銀行が政策金利を引き上げ円相場は大幅に下落した"},
+			},
+			want: []string{"https://www.nikkei.com/a", "https://www.nikkei.com/b"},
+		},
+		{
+			name: "short generic titles are never merged",
+			citations: []Citation{
+				{URL: "https://example.com/news", Title: "News"},
+				{URL: "https://example.com/home", Title: "News"},
+			},
+			want: []string{"https://example.com/news", "https://example.com/home"},
+		},
+		{
+			name: "exact URL duplicate",
+			citations: []Citation{
+				{URL: "https://example.com/a"},
+				{URL: "https://example.com/a", Title: "Filled in later"},
+			},
+			want: []string{"https://example.com/a"},
+		},
+		{name: "empty", citations: nil, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CollapseDuplicateStories(tt.citations)
+			if len(got) != len(tt.want) {
+				t.Fatalf("kept %d citations %+v, want %v", len(got), got, tt.want)
+			}
+			for i, url := range tt.want {
+				if got[i].URL != url {
+					t.Errorf("citation %d = %s, want %s", i, got[i].URL, url)
+				}
+			}
+		})
+	}
+}
+
+func TestCollapseDuplicateStoriesFillsExactDuplicate(t *testing.T) {
+	got := CollapseDuplicateStories([]Citation{
+		{URL: "https://example.com/a"},
+		{URL: "https://example.com/a", Title: "A title", Snippet: "A snippet"},
+	})
+	if len(got) != 1 || got[0].Title != "A title" || got[0].Snippet != "A snippet" {
+		t.Errorf("got %+v, want one citation with the duplicate's title and snippet", got)
+	}
+}
+
+func TestPublishedDateFromURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string // YYYY-MM-DD, or "" for none
+	}{
+		{"https://www.nytimes.com/2024/03/15/business/fed-rates.html", "2024-03-15"},
+		{"https://techcrunch.com/2024/03/15/", "2024-03-15"},
+		{"https://example.com/news/2024-03-15/story", "2024-03-15"},
+		{"https://example.com/blog/2023/11/some-post", "2023-11-01"}, // Month only
+		{"https://example.com/archive/2023/11", "2023-11-01"},
+		{"https://example.com/1999/12/31/party", "1999-12-31"},
+		{"https://example.com/2024/02/30/story", ""}, // Not a real date
+		{"https://example.com/2024/13/01/story", ""},
+		{"https://example.com/2024/3/5/story", ""}, // Unpadded
+		{"https://example.com/1899/01/01/old", ""},
+		{"https://example.com/products/20240315", ""},
+		{"https://example.com/item-2024/03", ""}, // Not its own path segment
+		{"https://example.com/about", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		got := publishedDateFromURL(tt.url)
+		switch {
+		case tt.want == "" && got != nil:
+			t.Errorf("publishedDateFromURL(%q) = %s, want nil", tt.url, got.Format(time.DateOnly))
+		case tt.want != "" && got == nil:
+			t.Errorf("publishedDateFromURL(%q) = nil, want %s", tt.url, tt.want)
+		case tt.want != "" && got.Format(time.DateOnly) != tt.want:
+			t.Errorf("publishedDateFromURL(%q) = %s, want %s", tt.url, got.Format(time.DateOnly), tt.want)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Ranking criteria for -sort.
+const (
+	SortOverall   = "overall"
+	SortQuality   = "quality"
+	SortRecency   = "recency"
+	SortCost      = "cost"
+	SortSpeed     = "speed"
+	SortCitations = "citations"
+)
+
+var sortModes = []string{SortOverall, SortQuality, SortRecency, SortCost, SortSpeed, SortCitations}
+
+// sortBy is the -sort ranking criterion.
+var sortBy = SortOverall
+
+// dedupeDomainScore is set by -dedupe-domain-score: citation-based scoring
+// counts at most one citation per registrable domain, so a model can't pad its
+// score with many pages from one site. All citations are still displayed.
+var dedupeDomainScore bool
+
+// pinOrder is set by -pin-order: results are still ranked (Rank, medals, the
+// winner), but displayed in registry order so a model stays in the same place
+// across queries.
+var pinOrder bool
+
+// reverseRank is set by -reverse: the ranking runs worst first, so Rank 1 is
+// the bottom performer, flagged for improvement instead of crowned. Errored
+// results still sort last.
+var reverseRank bool
+
+// scoredCitationCount is the citation count used for ranking: every citation,
+// or one per domain under -dedupe-domain-score.
+func scoredCitationCount(r Result) int {
+	if dedupeDomainScore {
+		return r.UniqueDomains()
+	}
+	return len(r.Citations)
+}
+
+// validateSortMode checks a -sort value.
+func validateSortMode(mode string) error {
+	for _, m := range sortModes {
+		if mode == m {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid -sort %q (use %s)", mode, strings.Join(sortModes, ", "))
+}
+
+// rankResults orders results by sortBy, best first (worst first under
+// -reverse), and sets each Rank. This
+// is the single place ranking happens; medals and ranks follow Rank. Errored
+// results always sort last. Ties are broken by fewer total tokens, then faster
+// duration. Under -pin-order the slice is then put back in registry order.
+//
+// Overall uses the judge score when present; without one (judge disabled or
+// failed) it falls back to the link health score from checks.
+func rankResults(results []ModelResult, checks map[string][]CitationCheck) {
+	key := func(mr ModelResult) float64 {
+		js := mr.JudgeScore
+		r := mr.Result
+		switch sortBy {
+		case SortQuality:
+			if js != nil {
+				return float64(js.Quality)
+			}
+		case SortRecency:
+			if js != nil {
+				return float64(js.Recency)
+			}
+		case SortCost:
+			return -r.EstimatedCost(mr.Provider.Name())
+		case SortSpeed:
+			return -r.Duration.Seconds()
+		case SortCitations:
+			return float64(scoredCitationCount(r))
+		default:
+			if js != nil {
+				return js.Overall
+			}
+			return float64(linkHealthScore(checks[mr.Provider.Name()]))
+		}
+		return 0
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if (a.Result.Error == nil) != (b.Result.Error == nil) {
+			return a.Result.Error == nil
+		}
+		if ka, kb := key(a), key(b); ka != kb {
+			return (ka > kb) != reverseRank
+		}
+		ta := a.Result.Tokens.Input + a.Result.Tokens.Output
+		tb := b.Result.Tokens.Input + b.Result.Tokens.Output
+		if ta != tb {
+			return ta < tb
+		}
+		return a.Result.Duration < b.Result.Duration
+	})
+	for i := range results {
+		results[i].Rank = i + 1
+	}
+	if pinOrder {
+		sortRegistryOrder(results)
+	}
+}
+
+// sortRegistryOrder orders results as All() lists their providers.
+func sortRegistryOrder(results []ModelResult) {
+	pos := make(map[string]int)
+	for i, name := range All() {
+		pos[name] = i
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return pos[results[i].Provider.Name()] < pos[results[j].Provider.Name()]
+	})
+}
+
+// rankMedal returns the marker shown before a rank: medals for the top three,
+// or under -reverse a needs-improvement marker for the bottom performer only.
+func rankMedal(rank int) string {
+	if reverseRank {
+		if rank == 1 {
+			return "🔻"
+		}
+		return "  "
+	}
+	medals := []string{"🥇", "🥈", "🥉", "  "}
+	return medals[min(max(rank, 1)-1, 3)]
+}
+
+// topRanked returns the Rank 1 successful result (the best, or under -reverse
+// the worst), or nil when every provider failed.
+func topRanked(results []ModelResult) *ModelResult {
+	for i := range results {
+		if results[i].Rank == 1 && results[i].Result.Error == nil {
+			return &results[i]
+		}
+	}
+	return nil
+}
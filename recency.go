@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sinceTime is the start of the -since window; zero means no recency constraint.
+var sinceTime time.Time
+
+// RecencyProvider is implemented by providers whose search tool filters by
+// publication date natively. Others get the window as a prompt instruction.
+type RecencyProvider interface {
+	SupportsRecencyFilter() bool
+}
+
+// parseSince parses a relative window ("24h", "7d", "2w", "3m") or a date
+// ("2025-01-15") into the window's start time.
+func parseSince(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if t, err := time.ParseInLocation("2006-01-02", s, now.Location()); err == nil {
+		if t.After(now) {
+			return time.Time{}, fmt.Errorf("-since %s is in the future", s)
+		}
+		return t, nil
+	}
+	if len(s) < 2 {
+		return time.Time{}, fmt.Errorf("invalid -since %q (use e.g. 24h, 7d, 2w, 3m, or 2025-01-15)", s)
+	}
+	n, err := strconv.Atoi(s[:len(s)-1])
+	if err != nil || n <= 0 {
+		return time.Time{}, fmt.Errorf("invalid -since %q (use e.g. 24h, 7d, 2w, 3m, or 2025-01-15)", s)
+	}
+	switch s[len(s)-1] {
+	case 'h':
+		return now.Add(-time.Duration(n) * time.Hour), nil
+	case 'd':
+		return now.AddDate(0, 0, -n), nil
+	case 'w':
+		return now.AddDate(0, 0, -7*n), nil
+	case 'm':
+		return now.AddDate(0, -n, 0), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid -since %q (use e.g. 24h, 7d, 2w, 3m, or 2025-01-15)", s)
+}
+
+// sinceLabel formats the window start for prompts and display.
+func sinceLabel() string {
+	return sinceTime.Format("January 2, 2006")
+}
+
+// applyRecency appends the -since window to the query for providers without a
+// native recency filter.
+func applyRecency(p Provider, query string) string {
+	if sinceTime.IsZero() {
+		return query
+	}
+	if rp, ok := p.(RecencyProvider); ok && rp.SupportsRecencyFilter() {
+		return query
+	}
+	return query + "\n\nOnly use sources published on or after " + sinceLabel() + "."
+}
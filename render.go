@@ -0,0 +1,59 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+// renderMarkdown is set by -render: answers are shown with markdown turned
+// into terminal styling. It only applies when color is enabled, so redirected
+// output, -no-color, and NO_COLOR keep the raw text; exports (JSON, JSONL,
+// HTML) always carry the raw text.
+var renderMarkdown bool
+
+// mdRefLinkRegex matches Grok's [[n]](url) citation markers.
+var mdRefLinkRegex = regexp.MustCompile(`\[\[(\d+)\]\]\((https?://[^)\s]+)\)`)
+
+// renderEnabled reports whether answers should be rendered for the terminal.
+func renderEnabled() bool {
+	return renderMarkdown && colorEnabled
+}
+
+// renderMarkdownTerminal converts the markdown subset handled by
+// renderMarkdownHTML to ANSI styling: headers bold, bullets as "•", links as
+// "text (url)". [[n]](url) citation markers shrink to [n], since the sources
+// list carries the URL. Fenced code blocks are left as-is.
+func renderMarkdownTerminal(text string) string {
+	lines := strings.Split(text, "\n")
+	inCode := false
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		switch {
+		case strings.HasPrefix(trimmed, "```"):
+			inCode = !inCode
+		case inCode:
+		case strings.HasPrefix(trimmed, "#"):
+			title := strings.TrimSpace(strings.TrimLeft(trimmed, "#"))
+			lines[i] = ansiBold + renderInlineTerminal(title) + ansiReset
+		case trimmed == "---" || trimmed == "***":
+			lines[i] = dim(strings.Repeat("─", 40))
+		case strings.HasPrefix(trimmed, "- ") || strings.HasPrefix(trimmed, "* "):
+			lines[i] = indent + "• " + renderInlineTerminal(strings.TrimSpace(trimmed[2:]))
+		default:
+			lines[i] = renderInlineTerminal(line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderInlineTerminal applies inline markdown styling to one line. Links go
+// first: the escape sequences added for other spans contain "[".
+func renderInlineTerminal(s string) string {
+	s = mdRefLinkRegex.ReplaceAllString(s, "[$1]")
+	s = mdLinkRegex.ReplaceAllString(s, "$1 ("+ansiDim+"$2"+ansiReset+")")
+	s = mdCodeRegex.ReplaceAllString(s, ansiYellow+"$1"+ansiReset)
+	s = mdBoldRegex.ReplaceAllString(s, ansiBold+"$1"+ansiReset)
+	s = mdItalicRegex.ReplaceAllString(s, "$1"+ansiItalic+"$2"+ansiReset)
+	return s
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// runREPL reads queries from stdin in a loop and runs them against the selected model.
+// Provider clients are created lazily and reused, so each query skips startup cost.
+// Each provider keeps its own conversation history so follow-up questions have context.
+// A query that can't run (unknown model, missing credentials, over -budget) is
+// reported and the prompt returns, rather than ending the session.
+func runREPL(ctx context.Context, model string) {
+	conversations = make(map[string]*Conversation)
+
+	fmt.Println("💬 Interactive mode. Type a question, or \\help for commands.")
+	fmt.Println()
+
+	scanner := bufio.NewScanner(os.Stdin)
+	for {
+		fmt.Printf("web-search [%s]> ", replTarget(model))
+		if !scanner.Scan() {
+			fmt.Println()
+			return
+		}
+
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, `\`) {
+			if quit := handleMetaCommand(line, &model); quit {
+				return
+			}
+			continue
+		}
+
+		fmt.Printf("📝 Query: %s\n\n", line)
+		if _, err := runQuery(ctx, model, line); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+		}
+		fmt.Println()
+	}
+}
+
+// replTarget names what queries run against: the -providers list while it is
+// set, otherwise model.
+func replTarget(model string) string {
+	if len(providerList) > 0 {
+		return strings.Join(providerList, ",")
+	}
+	return model
+}
+
+// handleMetaCommand applies a backslash command. Returns true if the REPL should exit.
+func handleMetaCommand(line string, model *string) bool {
+	fields := strings.Fields(line)
+	cmd := fields[0]
+	arg := ""
+	if len(fields) > 1 {
+		arg = fields[1]
+	}
+
+	switch cmd {
+	case `\quit`, `\q`, `\exit`:
+		return true
+
+	case `\model`:
+		if arg == "" {
+			fmt.Printf("Current model: %s (available: all, %s)\n", replTarget(*model), strings.Join(All(), ", "))
+			return false
+		}
+		if _, ok := Get(arg); !ok && arg != "all" {
+			fmt.Printf("❌ Unknown model: %s (available: all, %s)\n", arg, strings.Join(All(), ", "))
+			return false
+		}
+		*model = arg
+		if len(providerList) > 0 {
+			// -providers takes precedence over the model, so switching replaces it.
+			fmt.Printf("Model set to %s (replacing -providers %s)\n", arg, strings.Join(providerList, ","))
+			providerList = nil
+			return false
+		}
+		fmt.Printf("Model set to %s\n", arg)
+
+	case `\judge`:
+		switch arg {
+		case "on":
+			skipJudge = false
+		case "off":
+			skipJudge = true
+		default:
+			fmt.Println("Usage: \\judge on|off")
+			return false
+		}
+		fmt.Printf("Judge %s\n", arg)
+
+	case `\reset`:
+		conversationsMu.Lock()
+		conversations = make(map[string]*Conversation)
+		conversationsMu.Unlock()
+		fmt.Println("Conversation history cleared")
+
+	case `\help`, `\?`:
+		fmt.Printf(`Commands:
+  \model NAME   Switch model (all, %s)
+  \judge on|off Enable or disable the LLM judge
+  \reset        Clear conversation history
+  \quit         Exit
+`, strings.Join(All(), ", "))
+
+	default:
+		fmt.Printf("Unknown command: %s (try \\help)\n", cmd)
+	}
+	return false
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// htmlReportData is the view model for the HTML report template.
+type htmlReportData struct {
+	Query     string
+	Generated string
+	Cards     []htmlReportCard
+}
+
+type htmlReportCard struct {
+	Rank        int
+	Emoji       string
+	Name        string
+	Duration    string
+	Error       string
+	Words       int
+	Cost        string
+	Score       string
+	ScorePct    int // 0-100, width of the score bar
+	ScoreClass  string
+	Reasoning   string
+	Body        template.HTML
+	Citations   []Citation
+	HasJudgment bool
+}
+
+// writeHTMLReport renders results as a self-contained HTML file for sharing.
+func writeHTMLReport(path, query string, results []ModelResult) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create report: %w", err)
+	}
+	defer f.Close()
+	return renderHTMLReport(f, query, results)
+}
+
+// renderHTMLReport writes the HTML report for results to w.
+func renderHTMLReport(w io.Writer, query string, results []ModelResult) error {
+	data := htmlReportData{
+		Query:     query,
+		Generated: time.Now().Format("2006-01-02 15:04 MST"),
+	}
+
+	for _, mr := range results {
+		r := mr.Result
+		card := htmlReportCard{
+			Rank:      mr.Rank,
+			Emoji:     mr.Provider.Emoji(),
+			Name:      mr.Provider.DisplayName(),
+			Duration:  r.Duration.Round(time.Millisecond).String(),
+			Words:     r.WordCount(),
+			Cost:      costLabel(mr.Provider.Name(), r),
+			Citations: displayCitations(r.Citations),
+		}
+		if r.Error != nil {
+			card.Error = r.Error.Error()
+		} else {
+			card.Body = renderMarkdownHTML(stripThinkingTags(r.Text))
+		}
+		if js := mr.JudgeScore; js != nil {
+			card.HasJudgment = true
+			card.Score = fmt.Sprintf("%.1f", js.Overall)
+			card.ScorePct = int(js.Overall * 10)
+			card.Reasoning = js.Reasoning
+			switch {
+			case js.Overall >= 7:
+				card.ScoreClass = "high"
+			case js.Overall >= 4:
+				card.ScoreClass = "mid"
+			default:
+				card.ScoreClass = "low"
+			}
+		}
+		data.Cards = append(data.Cards, card)
+	}
+
+	if err := htmlReportTemplate.Execute(w, data); err != nil {
+		return fmt.Errorf("render report: %w", err)
+	}
+	return nil
+}
+
+var (
+	mdLinkRegex   = regexp.MustCompile(`\[([^\]]+)\]\((https?://[^)\s]+)\)`)
+	mdBoldRegex   = regexp.MustCompile(`\*\*([^*]+)\*\*`)
+	mdItalicRegex = regexp.MustCompile(`(^|[^*])\*([^*\s][^*]*)\*`)
+	mdCodeRegex   = regexp.MustCompile("`([^`]+)`")
+)
+
+// renderMarkdownHTML converts the common subset of markdown that models emit
+// (headers, bullets, bold, italic, code, links) to HTML. Input is escaped first,
+// and only http(s) links become anchors.
+func renderMarkdownHTML(text string) template.HTML {
+	var b strings.Builder
+	inList := false
+	var para []string
+
+	flushPara := func() {
+		if len(para) > 0 {
+			b.WriteString("<p>" + strings.Join(para, "<br>") + "</p>\n")
+			para = nil
+		}
+	}
+	closeList := func() {
+		if inList {
+			b.WriteString("</ul>\n")
+			inList = false
+		}
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+			flushPara()
+			closeList()
+		case strings.HasPrefix(trimmed, "#"):
+			flushPara()
+			closeList()
+			hashes := len(trimmed) - len(strings.TrimLeft(trimmed, "#"))
+			level := min(hashes+2, 6) // model "#" headers sit below the card title
+			fmt.Fprintf(&b, "<h%d>%s</h%d>\n", level, renderInlineMarkdown(strings.TrimSpace(trimmed[hashes:])), level)
+		case strings.HasPrefix(trimmed, "- ") || strings.HasPrefix(trimmed, "* ") || strings.HasPrefix(trimmed, "• "):
+			flushPara()
+			if !inList {
+				b.WriteString("<ul>\n")
+				inList = true
+			}
+			item := strings.TrimSpace(strings.TrimLeft(trimmed, "-*• "))
+			b.WriteString("<li>" + renderInlineMarkdown(item) + "</li>\n")
+		default:
+			closeList()
+			para = append(para, renderInlineMarkdown(trimmed))
+		}
+	}
+	flushPara()
+	closeList()
+
+	return template.HTML(b.String())
+}
+
+// renderInlineMarkdown escapes a line and applies inline markdown formatting.
+func renderInlineMarkdown(s string) string {
+	s = html.EscapeString(s)
+	s = mdCodeRegex.ReplaceAllString(s, "<code>$1</code>")
+	s = mdLinkRegex.ReplaceAllString(s, `<a href="$2" target="_blank" rel="noopener">$1</a>`)
+	s = mdBoldRegex.ReplaceAllString(s, "<strong>$1</strong>")
+	s = mdItalicRegex.ReplaceAllString(s, "$1<em>$2</em>")
+	return s
+}
+
+var htmlReportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Web Search Comparison: {{.Query}}</title>
+<style>
+  body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", sans-serif; margin: 0; background: #f5f6f8; color: #1f2328; }
+  header { background: #1f2328; color: #fff; padding: 24px 32px; }
+  header h1 { margin: 0 0 8px; font-size: 1.5rem; }
+  header p { margin: 0; opacity: .75; font-size: .9rem; }
+  main { max-width: 1100px; margin: 0 auto; padding: 24px; }
+  table { width: 100%; border-collapse: collapse; background: #fff; border-radius: 8px; overflow: hidden; margin-bottom: 24px; }
+  th, td { text-align: left; padding: 10px 12px; border-bottom: 1px solid #eaecef; font-size: .9rem; }
+  th { background: #f0f2f5; }
+  .bar { background: #eaecef; border-radius: 4px; height: 10px; width: 120px; display: inline-block; vertical-align: middle; margin-right: 6px; }
+  .bar span { display: block; height: 100%; border-radius: 4px; }
+  .high { background: #2da44e; } .mid { background: #d4a72c; } .low { background: #cf222e; }
+  .cards { display: grid; grid-template-columns: repeat(auto-fit, minmax(320px, 1fr)); gap: 16px; }
+  .card { background: #fff; border-radius: 8px; padding: 16px 20px; box-shadow: 0 1px 3px rgba(0,0,0,.08); }
+  .card h2 { margin: 0 0 4px; font-size: 1.1rem; }
+  .meta { color: #656d76; font-size: .85rem; margin-bottom: 12px; }
+  .error { color: #cf222e; }
+  .reasoning { font-style: italic; color: #656d76; font-size: .85rem; }
+  details { margin-top: 12px; }
+  details li { margin-bottom: 4px; word-break: break-all; font-size: .85rem; }
+  code { background: #f0f2f5; padding: 1px 4px; border-radius: 3px; }
+</style>
+</head>
+<body>
+<header>
+  <h1>{{.Query}}</h1>
+  <p>Generated {{.Generated}}</p>
+</header>
+<main>
+  <table>
+    <tr><th>#</th><th>Model</th><th>Score</th><th>Words</th><th>Citations</th><th>Time</th><th>Cost</th></tr>
+    {{- range .Cards}}
+    <tr>
+      <td>{{.Rank}}</td>
+      <td>{{.Emoji}} {{.Name}}{{if .Error}} <span class="error">✗</span>{{end}}</td>
+      <td>{{if .HasJudgment}}<span class="bar"><span class="{{.ScoreClass}}" style="width: {{.ScorePct}}%"></span></span>{{.Score}}{{else}}n/a{{end}}</td>
+      <td>{{.Words}}</td>
+      <td>{{len .Citations}}</td>
+      <td>{{.Duration}}</td>
+      <td>{{.Cost}}</td>
+    </tr>
+    {{- end}}
+  </table>
+
+  <div class="cards">
+  {{- range .Cards}}
+    <section class="card">
+      <h2>#{{.Rank}} {{.Emoji}} {{.Name}}</h2>
+      <div class="meta">{{.Duration}} · {{.Words}} words · {{len .Citations}} citations · {{.Cost}}{{if .HasJudgment}} · judge {{.Score}}/10{{end}}</div>
+      {{- if .Error}}
+      <p class="error">Error: {{.Error}}</p>
+      {{- else}}
+      {{- if .Reasoning}}<p class="reasoning">{{.Reasoning}}</p>{{end}}
+      {{.Body}}
+      {{- if .Citations}}
+      <details>
+        <summary>Sources ({{len .Citations}})</summary>
+        <ol>
+        {{- range .Citations}}
+          <li><a href="{{.URL}}" target="_blank" rel="noopener">{{if .Title}}{{.Title}}{{else}}{{.URL}}{{end}}</a>{{if .Domain}} <span class="meta">{{with .Trust.Marker}}{{.}} {{end}}{{.Domain}}</span>{{end}}</li>
+        {{- end}}
+        </ol>
+      </details>
+      {{- end}}
+      {{- end}}
+    </section>
+  {{- end}}
+  </div>
+</main>
+</body>
+</html>
+`))
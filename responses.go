@@ -0,0 +1,357 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// responsesAPI is a client for the OpenAI-style /responses endpoint with the
+// web_search tool, shared by providers that speak it (xAI today; OpenAI and
+// compatible gateways like OpenRouter take the same shape). A provider supplies
+// the model, base URL, and key, and tweaks the request for model quirks.
+// The HTTP client is created on first use and reused across queries; set
+// client beforehand to substitute a stub.
+type responsesAPI struct {
+	clientOnce sync.Once
+	client     httpDoer
+}
+
+func (a *responsesAPI) httpClient() httpDoer {
+	a.clientOnce.Do(func() {
+		if a.client == nil {
+			a.client = newHTTPClient(5 * time.Minute)
+		}
+	})
+	return a.client
+}
+
+// newResponsesRequest builds a web-search request for model from the
+// conversation, led by the -system prompt as a system-role message and with the
+// -answer-schema text format when set.
+func newResponsesRequest(model string, history []Message) responsesRequest {
+	var input []responsesMessage
+	if systemPrompt != "" {
+		input = append(input, responsesMessage{Role: "system", Content: systemPrompt})
+	}
+	req := responsesRequest{
+		Model: model,
+		Input: append(input, responsesMessages(history)...),
+		Tools: []responsesTool{
+			{Type: "web_search"},
+		},
+	}
+	if answerSchema != nil {
+		req.Text = &responsesTextConfig{Format: responsesTextFormat{Type: "json_schema", Name: "answer", Schema: answerSchema}}
+	}
+	return req
+}
+
+// send POSTs reqBody to baseURL/responses and decodes the response. provider
+// names the -dump-dir files.
+func (a *responsesAPI) send(ctx context.Context, provider, baseURL, apiKey string, reqBody responsesRequest) (*responsesResponse, error) {
+	body, err := a.post(ctx, provider, baseURL+"/responses", apiKey, reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	var out responsesResponse
+	if err := json.Unmarshal(body, &out); err != nil {
+		return nil, fmt.Errorf("%w: %w (body: %s)", errParse, err, bodySnippet(body))
+	}
+	out.raw = body
+	return &out, nil
+}
+
+// post sends reqBody as JSON to an OpenAI-style endpoint and returns the
+// response body, turning error statuses and error envelopes into errors. An
+// empty apiKey sends no Authorization header, for local servers.
+func (a *responsesAPI) post(ctx context.Context, provider, endpoint, apiKey string, reqBody any) ([]byte, error) {
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("marshal error: %w", err)
+	}
+	dumpBytes(provider+"-request", jsonData)
+
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("request error: %w", err)
+	}
+
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := a.httpClient().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("API error: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read error: %w", err)
+	}
+	dumpBytes(provider+"-response", body)
+
+	if resp.StatusCode != http.StatusOK {
+		if msg := responsesErrorMessage(body); msg != "" {
+			return nil, &statusError{StatusCode: resp.StatusCode, Message: msg}
+		}
+		return nil, &statusError{StatusCode: resp.StatusCode, Message: bodySnippet(body)}
+	}
+
+	// A streamed (SSE) body on the non-streaming endpoint usually means a
+	// proxy or gateway rewrote the request; it can't be decoded as one object.
+	trimmed := bytes.TrimSpace(body)
+	if bytes.HasPrefix(trimmed, []byte("data:")) || bytes.HasPrefix(trimmed, []byte("event:")) {
+		return nil, fmt.Errorf("%w: got a streaming (SSE) response from a non-streaming request: %s", errParse, bodySnippet(body))
+	}
+
+	if msg := responsesErrorMessage(body); msg != "" {
+		return nil, fmt.Errorf("API error: %s", msg)
+	}
+	return body, nil
+}
+
+// responsesErrorMessage extracts the error from a JSON body, or returns "" if
+// there is none. xAI sends {"code": ..., "error": "message"}; OpenAI-style
+// gateways send {"error": {"message": ..., "type": ..., "code": ...}}.
+func responsesErrorMessage(body []byte) string {
+	var envelope struct {
+		Code  any             `json:"code"`
+		Error json.RawMessage `json:"error"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil || len(envelope.Error) == 0 || string(envelope.Error) == "null" {
+		return ""
+	}
+
+	var msg string
+	if err := json.Unmarshal(envelope.Error, &msg); err == nil {
+		if envelope.Code != nil {
+			return fmt.Sprintf("%s (%v)", msg, envelope.Code)
+		}
+		return msg
+	}
+
+	var obj struct {
+		Message string `json:"message"`
+		Type    string `json:"type"`
+		Code    any    `json:"code"`
+	}
+	if err := json.Unmarshal(envelope.Error, &obj); err != nil || obj.Message == "" {
+		return string(envelope.Error)
+	}
+	switch {
+	case obj.Code != nil:
+		return fmt.Sprintf("%s (%v)", obj.Message, obj.Code)
+	case obj.Type != "":
+		return fmt.Sprintf("%s (%s)", obj.Message, obj.Type)
+	}
+	return obj.Message
+}
+
+// bodySnippetBytes is how much of an unparseable response body goes into the error.
+const bodySnippetBytes = 200
+
+// bodySnippet quotes the start of body for error messages.
+func bodySnippet(body []byte) string {
+	if len(body) > bodySnippetBytes {
+		return fmt.Sprintf("%q...", body[:bodySnippetBytes])
+	}
+	return fmt.Sprintf("%q", body)
+}
+
+// responsesMessages maps conversation history to Responses API input messages.
+func responsesMessages(history []Message) []responsesMessage {
+	messages := make([]responsesMessage, 0, len(history))
+	for _, m := range history {
+		if len(m.Images) == 0 {
+			messages = append(messages, responsesMessage{Role: m.Role, Content: m.Text})
+			continue
+		}
+		parts := make([]responsesContentPart, 0, len(m.Images)+1)
+		for _, img := range m.Images {
+			parts = append(parts, responsesContentPart{Type: "input_image", ImageURL: img.DataURL()})
+		}
+		parts = append(parts, responsesContentPart{Type: "input_text", Text: m.Text})
+		messages = append(messages, responsesMessage{Role: m.Role, Content: parts})
+	}
+	return messages
+}
+
+// --- Responses API Types ---
+
+type responsesRequest struct {
+	Model     string               `json:"model"`
+	Input     []responsesMessage   `json:"input"`
+	Tools     []responsesTool      `json:"tools,omitempty"`
+	Reasoning *responsesReasoning  `json:"reasoning,omitempty"`
+	Text      *responsesTextConfig `json:"text,omitempty"`
+}
+
+type responsesTextConfig struct {
+	Format responsesTextFormat `json:"format"`
+}
+
+type responsesTextFormat struct {
+	Type   string         `json:"type"`
+	Name   string         `json:"name"`
+	Schema map[string]any `json:"schema"`
+}
+
+type responsesReasoning struct {
+	Effort string `json:"effort"`
+}
+
+type responsesMessage struct {
+	Role    string `json:"role"`
+	Content any    `json:"content"` // A string, or []responsesContentPart when images are attached
+}
+
+type responsesContentPart struct {
+	Type     string `json:"type"` // "input_text" or "input_image"
+	Text     string `json:"text,omitempty"`
+	ImageURL string `json:"image_url,omitempty"`
+}
+
+type responsesTool struct {
+	Type string `json:"type"`
+}
+
+type responsesResponse struct {
+	raw []byte // Undecoded body, for -include-raw
+
+	Status            string `json:"status"`
+	IncompleteDetails *struct {
+		Reason string `json:"reason"`
+	} `json:"incomplete_details,omitempty"`
+	OutputText string `json:"output_text"`
+	Output     []struct {
+		Type    string `json:"type"`
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content,omitempty"`
+		Summary []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"summary,omitempty"`
+		Action struct {
+			Type    string `json:"type"`
+			Query   string `json:"query"`
+			URL     string `json:"url"`
+			Sources []struct {
+				URL   string `json:"url"`
+				Title string `json:"title"`
+			} `json:"sources"`
+		} `json:"action,omitempty"`
+	} `json:"output"`
+	Usage *struct {
+		InputTokens        int `json:"input_tokens"`
+		OutputTokens       int `json:"output_tokens"`
+		InputTokensDetails struct {
+			CachedTokens int `json:"cached_tokens"`
+		} `json:"input_tokens_details"`
+		OutputTokensDetails struct {
+			ReasoningTokens int `json:"reasoning_tokens"`
+		} `json:"output_tokens_details"`
+	} `json:"usage,omitempty"`
+}
+
+// responsesCitationRegex matches inline [[n]](url) citation links. The URL may
+// contain one level of balanced parentheses, as Wikipedia titles do.
+var responsesCitationRegex = regexp.MustCompile(`\[\[(\d+)\]\]\((https?://(?:[^()\s]|\([^()\s]*\))+)\)`)
+
+// logResponsesSearches records each web_search_call's query, under -vv.
+func logResponsesSearches(v Verbosity, provider string, resp *responsesResponse) {
+	for _, out := range resp.Output {
+		if out.Type == "web_search_call" {
+			logSearchQuery(v, provider, out.Action.Query)
+		}
+	}
+}
+
+// parseResponsesOutput fills result with the answer text, reasoning summaries,
+// token usage, and citations from inline links and web_search_call sources.
+func parseResponsesOutput(resp *responsesResponse, result *Result) {
+	if resp.Usage != nil {
+		result.Tokens.Input = resp.Usage.InputTokens
+		result.Tokens.Output = resp.Usage.OutputTokens
+		result.Tokens.CachedInput = resp.Usage.InputTokensDetails.CachedTokens
+		result.Tokens.Reasoning = resp.Usage.OutputTokensDetails.ReasoningTokens
+	}
+
+	// An incomplete response says why (e.g. "max_output_tokens"); otherwise the status is the reason.
+	result.FinishReason = resp.Status
+	if resp.IncompleteDetails != nil && resp.IncompleteDetails.Reason != "" {
+		result.FinishReason = resp.IncompleteDetails.Reason
+	}
+
+	// Get the main text response
+	result.Text = resp.OutputText
+
+	// Extract text from Output array content blocks
+	if result.Text == "" {
+		for _, out := range resp.Output {
+			for _, content := range out.Content {
+				if content.Type == "output_text" && content.Text != "" {
+					result.Text = content.Text
+					break
+				}
+			}
+			if result.Text != "" {
+				break
+			}
+		}
+	}
+
+	// Reasoning items carry summaries when the model exposes them; search
+	// calls make up the timeline (open_page and find_in_page are tool steps).
+	for _, out := range resp.Output {
+		switch {
+		case out.Type == "reasoning":
+			for _, sum := range out.Summary {
+				AppendThinking(result, sum.Text)
+			}
+		case out.Type == "web_search_call" && (out.Action.Type == "search" || out.Action.Type == ""):
+			addSearchStep(result, out.Action.Query, 0)
+		case out.Type == "web_search_call":
+			addTimelineStep(result, StepTool, strings.TrimSpace(out.Action.Type+" "+out.Action.URL), 0)
+		}
+	}
+
+	seen := make(map[string]bool)
+
+	// Extract citations from markdown links in text [[n]](url) pattern; n is
+	// the reference number shown in the text.
+	for _, match := range responsesCitationRegex.FindAllStringSubmatch(result.Text, -1) {
+		n, _ := strconv.Atoi(match[1])
+		DeduplicateCitations(&result.Citations, seen, Citation{
+			URL:   match[2],
+			Index: n,
+		})
+	}
+
+	// Also extract from web_search_call action sources, which carry the
+	// titles the inline links lack
+	for _, out := range resp.Output {
+		if out.Type == "web_search_call" && out.Action.Type == "search" {
+			for _, src := range out.Action.Sources {
+				MergeCitation(&result.Citations, seen, Citation{
+					URL:   src.URL,
+					Title: src.Title,
+				})
+			}
+		}
+	}
+}
@@ -0,0 +1,354 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// stubDoer is an httpDoer that returns one canned response and records the request.
+type stubDoer struct {
+	status int
+	body   string
+
+	req     *http.Request
+	reqBody []byte
+}
+
+func (s *stubDoer) Do(req *http.Request) (*http.Response, error) {
+	s.req = req
+	if req.Body != nil {
+		s.reqBody, _ = io.ReadAll(req.Body)
+	}
+	return &http.Response{
+		StatusCode: s.status,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(s.body)),
+		Request:    req,
+	}, nil
+}
+
+func TestResponsesCitationRegex(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []Citation // URL and Index only
+	}{
+		{
+			name: "single link",
+			text: "Go 1.23 shipped [[1]](https://go.dev/blog/go1.23)",
+			want: []Citation{{URL: "https://go.dev/blog/go1.23", Index: 1}},
+		},
+		{
+			name: "nested parens",
+			text: "See [[1]](https://en.wikipedia.org/wiki/Go_(programming_language)) for history.",
+			want: []Citation{{URL: "https://en.wikipedia.org/wiki/Go_(programming_language)", Index: 1}},
+		},
+		{
+			name: "trailing punctuation",
+			text: "It was announced [[2]](https://example.com/news/launch). Then [[3]](https://example.com/b), and more.",
+			want: []Citation{
+				{URL: "https://example.com/news/launch", Index: 2},
+				{URL: "https://example.com/b", Index: 3},
+			},
+		},
+		{
+			name: "duplicate n with different URLs",
+			text: "[[1]](https://a.example.com/x) and [[1]](https://b.example.com/y)",
+			want: []Citation{
+				{URL: "https://a.example.com/x", Index: 1},
+				{URL: "https://b.example.com/y", Index: 1},
+			},
+		},
+		{
+			name: "repeated URL keeps first n",
+			text: "[[1]](https://example.com/a) again [[4]](https://example.com/a)",
+			want: []Citation{{URL: "https://example.com/a", Index: 1}},
+		},
+		{
+			name: "plain markdown link ignored",
+			text: "[docs](https://go.dev/doc) and [[x]](https://example.com)",
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var result Result
+			parseResponsesOutput(&responsesResponse{OutputText: tt.text}, &result)
+			if len(result.Citations) != len(tt.want) {
+				t.Fatalf("got %d citations %+v, want %d", len(result.Citations), result.Citations, len(tt.want))
+			}
+			for i, want := range tt.want {
+				got := result.Citations[i]
+				if got.URL != want.URL || got.Index != want.Index {
+					t.Errorf("citation %d = {%q, %d}, want {%q, %d}", i, got.URL, got.Index, want.URL, want.Index)
+				}
+			}
+		})
+	}
+}
+
+func TestParseResponsesOutputSources(t *testing.T) {
+	body := `{
+		"status": "completed",
+		"output": [
+			{"type": "web_search_call", "action": {"type": "search", "query": "go release", "sources": [
+				{"url": "https://go.dev/blog/go1.23", "title": "Go 1.23 is released"},
+				{"url": "https://example.com/other", "title": "Other coverage"}
+			]}},
+			{"type": "web_search_call", "action": {"type": "open_page", "url": "https://go.dev/blog/go1.23"}},
+			{"type": "message", "content": [
+				{"type": "output_text", "text": "Go 1.23 is out [[1]](https://go.dev/blog/go1.23)."}
+			]}
+		],
+		"usage": {"input_tokens": 120, "output_tokens": 45, "output_tokens_details": {"reasoning_tokens": 10}}
+	}`
+	var resp responsesResponse
+	if err := json.Unmarshal([]byte(body), &resp); err != nil {
+		t.Fatal(err)
+	}
+	var result Result
+	parseResponsesOutput(&resp, &result)
+
+	if result.Text != "Go 1.23 is out [[1]](https://go.dev/blog/go1.23)." {
+		t.Errorf("Text = %q", result.Text)
+	}
+	want := []Citation{
+		{URL: "https://go.dev/blog/go1.23", Title: "Go 1.23 is released", Index: 1},
+		{URL: "https://example.com/other", Title: "Other coverage"},
+	}
+	if len(result.Citations) != len(want) {
+		t.Fatalf("got %d citations %+v, want %d", len(result.Citations), result.Citations, len(want))
+	}
+	for i, w := range want {
+		got := result.Citations[i]
+		if got.URL != w.URL || got.Title != w.Title || got.Index != w.Index {
+			t.Errorf("citation %d = {%q, %q, %d}, want {%q, %q, %d}", i, got.URL, got.Title, got.Index, w.URL, w.Title, w.Index)
+		}
+	}
+	if result.Tokens.Input != 120 || result.Tokens.Output != 45 || result.Tokens.Reasoning != 10 {
+		t.Errorf("Tokens = %+v", result.Tokens)
+	}
+	if result.FinishReason != "completed" {
+		t.Errorf("FinishReason = %q, want completed", result.FinishReason)
+	}
+}
+
+func TestResponsesPost(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		body       string
+		wantErr    string // Substring; empty for success
+		wantStatus int    // Expected statusError code, 0 if none
+		wantParse  bool   // Expect errParse
+	}{
+		{
+			name:   "ok",
+			status: http.StatusOK,
+			body:   `{"status": "completed", "output_text": "hi"}`,
+		},
+		{
+			name:       "xai error envelope on error status",
+			status:     http.StatusTooManyRequests,
+			body:       `{"code": "rate_limit", "error": "Too many requests"}`,
+			wantErr:    "Too many requests (rate_limit)",
+			wantStatus: http.StatusTooManyRequests,
+		},
+		{
+			name:       "non-JSON error status",
+			status:     http.StatusBadGateway,
+			body:       `<html>bad gateway</html>`,
+			wantErr:    "bad gateway",
+			wantStatus: http.StatusBadGateway,
+		},
+		{
+			name:    "openai error envelope on 200",
+			status:  http.StatusOK,
+			body:    `{"error": {"message": "model not found", "type": "invalid_request_error"}}`,
+			wantErr: "API error: model not found (invalid_request_error)",
+		},
+		{
+			name:    "error object with code",
+			status:  http.StatusOK,
+			body:    `{"error": {"message": "quota exceeded", "code": 42}}`,
+			wantErr: "quota exceeded (42)",
+		},
+		{
+			name:   "null error is not an error",
+			status: http.StatusOK,
+			body:   `{"error": null, "output_text": "hi"}`,
+		},
+		{
+			name:      "SSE body",
+			status:    http.StatusOK,
+			body:      "event: response.created\ndata: {\"type\": \"response.created\"}\n\n",
+			wantErr:   "streaming (SSE)",
+			wantParse: true,
+		},
+		{
+			name:      "SSE data-only body",
+			status:    http.StatusOK,
+			body:      "\n  data: {\"delta\": \"hi\"}\n",
+			wantErr:   "streaming (SSE)",
+			wantParse: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := &stubDoer{status: tt.status, body: tt.body}
+			api := &responsesAPI{client: stub}
+			body, err := api.post(context.Background(), "test", "https://api.example.com/v1/responses", "sk-test", map[string]string{"model": "m"})
+
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if string(body) != tt.body {
+					t.Errorf("body = %q, want %q", body, tt.body)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("got nil error, want %q", tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %q, want it to contain %q", err, tt.wantErr)
+			}
+			var se *statusError
+			if got := errors.As(err, &se); got != (tt.wantStatus != 0) || (got && se.StatusCode != tt.wantStatus) {
+				t.Errorf("statusError = %v, want status %d", se, tt.wantStatus)
+			}
+			if errors.Is(err, errParse) != tt.wantParse {
+				t.Errorf("errors.Is(err, errParse) = %v, want %v", !tt.wantParse, tt.wantParse)
+			}
+		})
+	}
+}
+
+func TestResponsesPostHeaders(t *testing.T) {
+	for _, key := range []string{"sk-test", ""} {
+		stub := &stubDoer{status: http.StatusOK, body: `{}`}
+		api := &responsesAPI{client: stub}
+		if _, err := api.post(context.Background(), "test", "http://localhost:8080/v1/responses", key, map[string]string{"model": "m"}); err != nil {
+			t.Fatal(err)
+		}
+		want := ""
+		if key != "" {
+			want = "Bearer " + key
+		}
+		if got := stub.req.Header.Get("Authorization"); got != want {
+			t.Errorf("key %q: Authorization = %q, want %q", key, got, want)
+		}
+		if got := stub.req.Header.Get("Content-Type"); got != "application/json" {
+			t.Errorf("Content-Type = %q", got)
+		}
+		if string(stub.reqBody) != `{"model":"m"}` {
+			t.Errorf("request body = %s", stub.reqBody)
+		}
+	}
+}
+
+func TestGrokQuery(t *testing.T) {
+	t.Setenv("XAI_API_KEY", "xai-test")
+	t.Setenv("XAI_BASE_URL", "")
+
+	tests := []struct {
+		name      string
+		status    int
+		body      string
+		wantText  string
+		wantURLs  []string
+		wantErr   string
+		wantKind  ErrorKind
+		wantInput int
+	}{
+		{
+			name:   "answer with citations",
+			status: http.StatusOK,
+			body: `{
+				"status": "completed",
+				"output": [
+					{"type": "reasoning", "summary": [{"type": "summary_text", "text": "Searching for the release."}]},
+					{"type": "web_search_call", "action": {"type": "search", "query": "go 1.23 release", "sources": [
+						{"url": "https://go.dev/blog/go1.23", "title": "Go 1.23 is released"}
+					]}},
+					{"type": "message", "content": [{"type": "output_text",
+						"text": "Go 1.23 added range-over-func [[1]](https://go.dev/blog/go1.23) [[2]](https://tip.golang.org/doc/go1.23)."}]}
+				],
+				"usage": {"input_tokens": 900, "output_tokens": 60}
+			}`,
+			wantText:  "Go 1.23 added range-over-func [[1]](https://go.dev/blog/go1.23) [[2]](https://tip.golang.org/doc/go1.23).",
+			wantURLs:  []string{"https://go.dev/blog/go1.23", "https://tip.golang.org/doc/go1.23"},
+			wantInput: 900,
+		},
+		{
+			name:     "rate limited",
+			status:   http.StatusTooManyRequests,
+			body:     `{"code": "Some resource has been exhausted", "error": "Rate limit reached"}`,
+			wantErr:  "Rate limit reached",
+			wantKind: ErrorKindRateLimit,
+		},
+		{
+			name:     "malformed JSON",
+			status:   http.StatusOK,
+			body:     `{"status": "completed", "output": [`,
+			wantErr:  "parse error",
+			wantKind: ErrorKindParse,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := &stubDoer{status: tt.status, body: tt.body}
+			p := &GrokProvider{api: responsesAPI{client: stub}}
+			r := p.Query(context.Background(), "what's new in go 1.23", VerbosityNormal)
+
+			if got := stub.req.URL.String(); got != grokDefaultBaseURL+"/responses" {
+				t.Errorf("request URL = %s", got)
+			}
+			if got := stub.req.Header.Get("Authorization"); got != "Bearer xai-test" {
+				t.Errorf("Authorization = %q", got)
+			}
+			var req responsesRequest
+			if err := json.Unmarshal(stub.reqBody, &req); err != nil {
+				t.Fatalf("request body: %v", err)
+			}
+			if req.Model != grokModelID || len(req.Tools) != 1 || req.Tools[0].Type != "web_search" {
+				t.Errorf("request = %s", stub.reqBody)
+			}
+
+			if tt.wantErr != "" {
+				if r.Error == nil || !strings.Contains(r.Error.Error(), tt.wantErr) {
+					t.Fatalf("Error = %v, want %q", r.Error, tt.wantErr)
+				}
+				if got := classifyError(r.Error); got != tt.wantKind {
+					t.Errorf("error kind = %v, want %v", got, tt.wantKind)
+				}
+				return
+			}
+			if r.Error != nil {
+				t.Fatalf("unexpected error: %v", r.Error)
+			}
+			if r.Text != tt.wantText {
+				t.Errorf("Text = %q, want %q", r.Text, tt.wantText)
+			}
+			var urls []string
+			for _, c := range r.Citations {
+				urls = append(urls, c.URL)
+			}
+			if strings.Join(urls, " ") != strings.Join(tt.wantURLs, " ") {
+				t.Errorf("citation URLs = %v, want %v", urls, tt.wantURLs)
+			}
+			if r.Tokens.Input != tt.wantInput {
+				t.Errorf("input tokens = %d, want %d", r.Tokens.Input, tt.wantInput)
+			}
+			if r.Citations[0].Title != "Go 1.23 is released" {
+				t.Errorf("title from web_search_call source = %q", r.Citations[0].Title)
+			}
+		})
+	}
+}
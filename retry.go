@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"math/rand/v2"
+	"time"
+)
+
+const (
+	retryAttempts  = 4 // Total calls, including the first
+	retryBaseDelay = 1 * time.Second
+	retryMaxDelay  = 10 * time.Second
+)
+
+// withRetry calls fn until it succeeds, returns an error retryable rejects, or
+// retryAttempts calls have been made. Waits between calls double from
+// retryBaseDelay (capped at retryMaxDelay), jittered to 0.5-1.5x so parallel
+// providers hitting the same limit don't retry in lockstep. It returns the
+// number of calls made and fn's last error.
+func withRetry(ctx context.Context, name string, retryable func(error) bool, fn func() error) (int, error) {
+	delay := retryBaseDelay
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt == retryAttempts || !retryable(err) {
+			return attempt, err
+		}
+
+		wait := time.Duration(rand.Int64N(int64(delay))) + delay/2
+		slog.Warn("retrying after transient error", "provider", name, "attempt", attempt, "wait", wait.Round(time.Millisecond), "error", err)
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return attempt, err
+		}
+		delay = min(delay*2, retryMaxDelay)
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// judgeRubric is loaded from -judge-rubric. When set it replaces the judge's
+// news-editor persona and dimension descriptions; link health, citation dates,
+// and the score_models tool are unchanged.
+var judgeRubric string
+
+// judgeDimensions are the dimensions score_models asks the judge to score.
+var judgeDimensions = []string{"quality", "recency", "significance", "impact"}
+
+// defaultJudgeRubric frames the judge for breaking-news queries.
+const defaultJudgeRubric = `You are a news editor evaluating web search results from multiple AI models.
+
+For EACH model below, score these dimensions from 1-10:
+- quality: depth, coherence, factual accuracy of the response
+- recency: how current the information and cited sources are (today > this week > this month > older)
+- significance: is this newsworthy and substantial? Would it make WSJ or major outlets?
+- impact: how impactful is this to the relevant business, industry, or topic?`
+
+// loadJudgeRubric reads a -judge-rubric file. The rubric must describe every
+// scored dimension by name, or the judge would fill in scores it was never
+// told how to give.
+func loadJudgeRubric(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("read judge rubric: %w", err)
+	}
+	rubric := strings.TrimSpace(string(data))
+	if rubric == "" {
+		return "", fmt.Errorf("judge rubric %s is empty", path)
+	}
+	var missing []string
+	for _, d := range judgeDimensions {
+		if !regexp.MustCompile(`(?i)\b` + d + `\b`).MatchString(rubric) {
+			missing = append(missing, d)
+		}
+	}
+	if len(missing) > 0 {
+		return "", fmt.Errorf("judge rubric %s doesn't describe %s (the judge scores %s)",
+			path, strings.Join(missing, ", "), strings.Join(judgeDimensions, ", "))
+	}
+	return rubric, nil
+}
+
+// activeJudgeRubric returns the -judge-rubric text, or the default news rubric.
+func activeJudgeRubric() string {
+	if judgeRubric != "" {
+		return judgeRubric
+	}
+	return defaultJudgeRubric
+}
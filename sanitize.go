@@ -0,0 +1,69 @@
+package main
+
+import (
+	"log/slog"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// sanitizeCitations drops citations whose URL isn't an absolute http(s) link
+// (relative paths, javascript:, mailto:, garbage) and repairs obvious
+// truncation, so link checks aren't spent on junk. Repaired URLs that now
+// match another citation are merged into it. Drops are logged (-v).
+func sanitizeCitations(r *Result, provider string) {
+	if len(r.Citations) == 0 {
+		return
+	}
+	var kept []Citation
+	seen := make(map[string]bool)
+	for _, c := range r.Citations {
+		fixed, ok := sanitizeCitationURL(c.URL)
+		if !ok {
+			slog.Info("dropped citation with invalid URL", "provider", provider, "url", c.URL)
+			continue
+		}
+		if fixed != c.URL {
+			slog.Debug("repaired citation URL", "provider", provider, "from", c.URL, "to", fixed)
+			c.URL = fixed
+		}
+		MergeCitation(&kept, seen, c)
+	}
+	r.Citations = kept
+}
+
+// sanitizeCitationURL returns rawURL cleaned up, and false if it can't be a
+// web link. Repairs: surrounding whitespace and quotes, a missing scheme on a
+// "www." host, trailing sentence punctuation, and a closing paren cut off by
+// markdown link parsing (".../Foo_(bar" becomes ".../Foo_(bar)"). Other
+// unbalanced parens are left alone.
+func sanitizeCitationURL(rawURL string) (string, bool) {
+	s := strings.Trim(strings.TrimSpace(rawURL), `"'<>`)
+	if strings.HasPrefix(strings.ToLower(s), "www.") {
+		s = "https://" + s
+	}
+	s = strings.TrimRight(s, ".,;:!?")
+	for strings.HasSuffix(s, ")") && strings.Count(s, ")") > strings.Count(s, "(") {
+		s = strings.TrimRight(strings.TrimSuffix(s, ")"), ".,;:!?")
+	}
+	if truncatedParenRegex.MatchString(s) {
+		s += ")"
+	}
+
+	u, err := url.Parse(s)
+	if err != nil || u.Host == "" || strings.ContainsAny(u.Host, " \t") {
+		return "", false
+	}
+	if scheme := strings.ToLower(u.Scheme); scheme != "http" && scheme != "https" {
+		return "", false
+	}
+	if host := u.Hostname(); host != "localhost" && !strings.Contains(host, ".") {
+		return "", false // Truncated host ("https://en") or a bare word
+	}
+	return s, true
+}
+
+// truncatedParenRegex matches a last path segment ending in "_(...", the shape
+// of a Wikipedia-style title whose closing paren markdown parsing took as the
+// end of the link.
+var truncatedParenRegex = regexp.MustCompile(`/[^/?#]*_\([^()/?#]*$`)
@@ -0,0 +1,73 @@
+package main
+
+import "testing"
+
+func TestSanitizeCitationURL(t *testing.T) {
+	tests := []struct {
+		raw    string
+		want   string
+		wantOK bool
+	}{
+		{"https://example.com/a", "https://example.com/a", true},
+		{"  <https://example.com/a>  ", "https://example.com/a", true},
+		{`"https://example.com/a"`, "https://example.com/a", true},
+		{"www.example.com/news", "https://www.example.com/news", true},
+		{"WWW.Example.com", "https://WWW.Example.com", true},
+		{"https://example.com/story.", "https://example.com/story", true},
+		{"https://example.com/story?!", "https://example.com/story", true},
+		{"https://example.com/story).", "https://example.com/story", true},
+		{"https://example.com/a))", "https://example.com/a", true},
+
+		// Balanced parens are part of the URL.
+		{"https://en.wikipedia.org/wiki/Go_(programming_language)", "https://en.wikipedia.org/wiki/Go_(programming_language)", true},
+		{"https://en.wikipedia.org/wiki/Go_(programming_language)).", "https://en.wikipedia.org/wiki/Go_(programming_language)", true},
+		// Markdown truncation of a "_(...)" title is repaired...
+		{"https://en.wikipedia.org/wiki/Go_(programming_language", "https://en.wikipedia.org/wiki/Go_(programming_language)", true},
+		{"https://en.wikipedia.org/wiki/Mercury_(planet", "https://en.wikipedia.org/wiki/Mercury_(planet)", true},
+		// ...but other unbalanced parens are left as they are.
+		{"https://example.com/search?q=(a", "https://example.com/search?q=(a", true},
+		{"https://example.com/(draft/page", "https://example.com/(draft/page", true},
+		{"https://example.com/note(1", "https://example.com/note(1", true},
+		{"https://example.com/wiki/Foo_(bar#section", "https://example.com/wiki/Foo_(bar#section", true},
+
+		{"http://localhost:8080/x", "http://localhost:8080/x", true},
+		{"/relative/path", "", false},
+		{"javascript:alert(1)", "", false},
+		{"mailto:someone@example.com", "", false},
+		{"ftp://example.com/file", "", false},
+		{"https://en", "", false},
+		{"https://exa mple.com/a", "", false},
+		{"", "", false},
+		{"not a url", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := sanitizeCitationURL(tt.raw)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("sanitizeCitationURL(%q) = %q, %v; want %q, %v", tt.raw, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestSanitizeCitations(t *testing.T) {
+	r := Result{Citations: []Citation{
+		{URL: "https://example.com/a.", Index: 1},
+		{URL: "javascript:void(0)", Title: "junk"},
+		{URL: "https://example.com/a", Title: "A", Index: 2},
+		{URL: "www.example.org/b", Title: "B"},
+	}}
+	sanitizeCitations(&r, "test")
+
+	want := []Citation{
+		{URL: "https://example.com/a", Title: "A", Index: 1},
+		{URL: "https://www.example.org/b", Title: "B"},
+	}
+	if len(r.Citations) != len(want) {
+		t.Fatalf("got %d citations %+v, want %d", len(r.Citations), r.Citations, len(want))
+	}
+	for i, w := range want {
+		got := r.Citations[i]
+		if got.URL != w.URL || got.Title != w.Title || got.Index != w.Index {
+			t.Errorf("citation %d = {%q, %q, %d}, want {%q, %q, %d}", i, got.URL, got.Title, got.Index, w.URL, w.Title, w.Index)
+		}
+	}
+}
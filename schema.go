@@ -0,0 +1,151 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+)
+
+// answerSchema is the JSON schema loaded from -answer-schema; nil means free-form answers.
+var answerSchema map[string]any
+
+// SchemaProvider is implemented by providers that can constrain their answer to
+// answerSchema while still searching. Others are skipped when -answer-schema is set.
+type SchemaProvider interface {
+	SupportsAnswerSchema() bool
+}
+
+// supportsAnswerSchema reports whether p can honor -answer-schema.
+func supportsAnswerSchema(p Provider) bool {
+	sp, ok := p.(SchemaProvider)
+	return ok && sp.SupportsAnswerSchema()
+}
+
+// loadAnswerSchema reads a JSON schema file. The root must be an object schema,
+// since that is what every structured-output API accepts.
+func loadAnswerSchema(path string) (map[string]any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read schema: %w", err)
+	}
+	var schema map[string]any
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, fmt.Errorf("parse schema: %w", err)
+	}
+	if t, _ := schema["type"].(string); t != "object" {
+		return nil, fmt.Errorf("schema root must have \"type\": \"object\"")
+	}
+	return schema, nil
+}
+
+// validateAnswer checks that text is JSON conforming to schema.
+func validateAnswer(text string, schema map[string]any) error {
+	var v any
+	if err := json.Unmarshal([]byte(strings.TrimSpace(text)), &v); err != nil {
+		return fmt.Errorf("answer is not valid JSON: %w", err)
+	}
+	return validateValue(v, schema, "$")
+}
+
+// validateValue checks v against the commonly used subset of JSON Schema:
+// type, properties, required, additionalProperties (false), items, and enum.
+// Unsupported keywords are ignored rather than rejected.
+func validateValue(v any, schema map[string]any, path string) error {
+	if enum, ok := schema["enum"].([]any); ok {
+		found := false
+		for _, e := range enum {
+			if fmt.Sprint(e) == fmt.Sprint(v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%s: %v is not one of %v", path, v, enum)
+		}
+	}
+
+	if t, ok := schema["type"]; ok && !matchesType(v, t) {
+		return fmt.Errorf("%s: expected %v, got %s", path, t, jsonTypeName(v))
+	}
+
+	switch val := v.(type) {
+	case map[string]any:
+		props, _ := schema["properties"].(map[string]any)
+		if required, ok := schema["required"].([]any); ok {
+			for _, r := range required {
+				name, _ := r.(string)
+				if _, ok := val[name]; !ok {
+					return fmt.Errorf("%s: missing required property %q", path, name)
+				}
+			}
+		}
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			sub, ok := props[k].(map[string]any)
+			if !ok {
+				if ap, ok := schema["additionalProperties"].(bool); ok && !ap {
+					return fmt.Errorf("%s: unexpected property %q", path, k)
+				}
+				continue
+			}
+			if err := validateValue(val[k], sub, path+"."+k); err != nil {
+				return err
+			}
+		}
+	case []any:
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, item := range val {
+				if err := validateValue(item, items, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// matchesType reports whether v has the schema type t (a name or list of names).
+func matchesType(v any, t any) bool {
+	switch tt := t.(type) {
+	case string:
+		got := jsonTypeName(v)
+		return got == tt || (tt == "number" && got == "integer")
+	case []any:
+		for _, name := range tt {
+			if matchesType(v, name) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// jsonTypeName returns the JSON Schema type name of a decoded JSON value.
+func jsonTypeName(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		if val == math.Trunc(val) {
+			return "integer"
+		}
+		return "number"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return "unknown"
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestValidateValue(t *testing.T) {
+	const schema = `{
+		"type": "object",
+		"required": ["title", "stories"],
+		"additionalProperties": false,
+		"properties": {
+			"title": {"type": "string"},
+			"count": {"type": "integer"},
+			"score": {"type": "number"},
+			"note": {"type": ["string", "null"]},
+			"tone": {"enum": ["positive", "neutral", "negative"]},
+			"stories": {
+				"type": "array",
+				"items": {
+					"type": "object",
+					"required": ["url"],
+					"properties": {"url": {"type": "string"}}
+				}
+			}
+		}
+	}`
+	tests := []struct {
+		name    string
+		value   string
+		wantErr string // Substring of the error; empty means valid
+	}{
+		{name: "minimal", value: `{"title": "Go", "stories": []}`},
+		{
+			name:  "every property",
+			value: `{"title": "Go", "count": 2, "score": 7.5, "note": null, "tone": "neutral", "stories": [{"url": "https://go.dev", "extra": 1}]}`,
+		},
+		{name: "integer is a number", value: `{"title": "Go", "score": 7, "stories": []}`},
+		{name: "type list", value: `{"title": "Go", "note": "hi", "stories": []}`},
+		{name: "not an object", value: `["Go"]`, wantErr: "$: expected object, got array"},
+		{name: "missing required", value: `{"title": "Go"}`, wantErr: `$: missing required property "stories"`},
+		{name: "additional property", value: `{"title": "Go", "stories": [], "x": 1}`, wantErr: `$: unexpected property "x"`},
+		{name: "wrong property type", value: `{"title": 3, "stories": []}`, wantErr: "$.title: expected string, got integer"},
+		{name: "fraction is not an integer", value: `{"title": "Go", "count": 1.5, "stories": []}`, wantErr: "$.count: expected integer, got number"},
+		{name: "not in type list", value: `{"title": "Go", "note": 1, "stories": []}`, wantErr: "$.note: expected [string null], got integer"},
+		{name: "not in enum", value: `{"title": "Go", "tone": "angry", "stories": []}`, wantErr: "$.tone: angry is not one of"},
+		{name: "bad array item", value: `{"title": "Go", "stories": [{"url": "a"}, {}]}`, wantErr: `$.stories[1]: missing required property "url"`},
+		{name: "bad nested type", value: `{"title": "Go", "stories": [{"url": false}]}`, wantErr: "$.stories[0].url: expected string, got boolean"},
+	}
+
+	var s map[string]any
+	if err := json.Unmarshal([]byte(schema), &s); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var v any
+			if err := json.Unmarshal([]byte(tt.value), &v); err != nil {
+				t.Fatal(err)
+			}
+			err := validateValue(v, s, "$")
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("validateValue(%s) = %v, want nil", tt.value, err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("validateValue(%s) = %v, want error containing %q", tt.value, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// snapshotPath is set by -snapshot-query-results: after a single query, every
+// input and intermediate result is bundled there (a directory, or a zip file
+// when the path ends in .zip) so the run can be shared and inspected offline.
+var snapshotPath string
+
+// snapshotJudge holds the judge exchange for the bundle; only one query runs
+// under -snapshot-query-results.
+var (
+	snapshotJudge   *snapshotJudgeExchange
+	snapshotJudgeMu sync.Mutex
+)
+
+type snapshotJudgeExchange struct {
+	Prompt      string            // Empty for the mock judge
+	Evaluations []judgeEvaluation // As returned, before validation
+}
+
+// recordJudgeExchange keeps the judge's prompt and evaluations for the
+// snapshot bundle.
+func recordJudgeExchange(prompt string, evals []judgeEvaluation) {
+	if snapshotPath == "" {
+		return
+	}
+	snapshotJudgeMu.Lock()
+	defer snapshotJudgeMu.Unlock()
+	snapshotJudge = &snapshotJudgeExchange{Prompt: prompt, Evaluations: evals}
+}
+
+// snapshotManifest is snapshot.json: what was asked, how, and the outcome.
+type snapshotManifest struct {
+	Query      string            `json:"query"`
+	Created    time.Time         `json:"created"`
+	Flags      map[string]string `json:"flags"` // Set on the command line or in -config
+	JudgeModel string            `json:"judge_model,omitempty"`
+	Ranking    []snapshotRank    `json:"ranking"`
+	Files      []string          `json:"files"`
+}
+
+type snapshotRank struct {
+	Rank     int      `json:"rank"`
+	Provider string   `json:"provider"`
+	Overall  *float64 `json:"overall,omitempty"`
+	Error    string   `json:"error,omitempty"`
+}
+
+type snapshotLinkCheck struct {
+	URL          string     `json:"url"`
+	Status       int        `json:"status,omitempty"`
+	Healthy      bool       `json:"healthy"`
+	LatencyMS    int64      `json:"latency_ms"`
+	LastModified *time.Time `json:"last_modified,omitempty"`
+	Error        string     `json:"error,omitempty"`
+}
+
+// saveSnapshot writes the -snapshot-query-results bundle, if requested.
+func saveSnapshot(query string, results []ModelResult) {
+	if snapshotPath == "" {
+		return
+	}
+	files, err := buildSnapshot(query, results)
+	if err == nil {
+		err = writeSnapshot(snapshotPath, files)
+	}
+	if err != nil {
+		fmt.Printf("⚠️  Snapshot error: %v\n", err)
+		return
+	}
+	fmt.Printf("📦 Snapshot saved to %s\n", snapshotPath)
+}
+
+// buildSnapshot assembles the bundle's files by name: the manifest, every
+// result with its raw response, link checks, the judge exchange, and the
+// HTML report.
+func buildSnapshot(query string, results []ModelResult) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	add := func(name string, v any) error {
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshal %s: %w", name, err)
+		}
+		files[name] = data
+		return nil
+	}
+
+	rec := newJSONQueryRecord(query, results)
+	for i, mr := range results {
+		rec.Results[i].Raw = mr.Result.Raw
+	}
+	if err := add("results.json", rec); err != nil {
+		return nil, err
+	}
+
+	checks := make(map[string][]snapshotLinkCheck)
+	linkCheckCacheMu.Lock()
+	for _, mr := range results {
+		for _, c := range mr.Result.Citations {
+			if check, ok := linkCheckCache[c.URL]; ok {
+				checks[mr.Provider.Name()] = append(checks[mr.Provider.Name()], snapshotLinkCheck{
+					URL:          check.URL,
+					Status:       check.StatusCode,
+					Healthy:      check.Healthy,
+					LatencyMS:    check.Latency.Milliseconds(),
+					LastModified: check.LastModified,
+					Error:        check.Error,
+				})
+			}
+		}
+	}
+	linkCheckCacheMu.Unlock()
+	if len(checks) > 0 {
+		if err := add("link-checks.json", checks); err != nil {
+			return nil, err
+		}
+	}
+
+	snapshotJudgeMu.Lock()
+	judge := snapshotJudge
+	snapshotJudgeMu.Unlock()
+	if judge != nil {
+		if judge.Prompt != "" {
+			files["judge-prompt.txt"] = []byte(judge.Prompt)
+		}
+		if err := add("judge-response.json", judge.Evaluations); err != nil {
+			return nil, err
+		}
+	}
+
+	var report bytes.Buffer
+	if err := renderHTMLReport(&report, query, results); err != nil {
+		return nil, err
+	}
+	files["report.html"] = report.Bytes()
+
+	m := snapshotManifest{Query: query, Created: time.Now(), Flags: make(map[string]string)}
+	flag.Visit(func(f *flag.Flag) { m.Flags[f.Name] = f.Value.String() })
+	if judge != nil && !mockEnabled() {
+		m.JudgeModel = judgeModelID
+	}
+	for _, mr := range results {
+		rank := snapshotRank{Rank: mr.Rank, Provider: mr.Provider.Name()}
+		if mr.JudgeScore != nil {
+			rank.Overall = &mr.JudgeScore.Overall
+		}
+		if mr.Result.Error != nil {
+			rank.Error = mr.Result.Error.Error()
+		}
+		m.Ranking = append(m.Ranking, rank)
+	}
+	sort.SliceStable(m.Ranking, func(i, j int) bool { return m.Ranking[i].Rank < m.Ranking[j].Rank })
+	for name := range files {
+		m.Files = append(m.Files, name)
+	}
+	m.Files = append(m.Files, "snapshot.json")
+	sort.Strings(m.Files)
+	if err := add("snapshot.json", m); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// writeSnapshot writes files into a directory at path, or a zip file when path
+// ends in .zip.
+func writeSnapshot(path string, files map[string][]byte) error {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if !strings.HasSuffix(strings.ToLower(path), ".zip") {
+		if err := os.MkdirAll(path, 0o755); err != nil {
+			return fmt.Errorf("create snapshot dir: %w", err)
+		}
+		for _, name := range names {
+			if err := os.WriteFile(filepath.Join(path, name), files[name], 0o644); err != nil {
+				return fmt.Errorf("write snapshot: %w", err)
+			}
+		}
+		return nil
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	now := time.Now()
+	for _, name := range names {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: now})
+		if err != nil {
+			return fmt.Errorf("zip snapshot: %w", err)
+		}
+		if _, err := w.Write(files[name]); err != nil {
+			return fmt.Errorf("zip snapshot: %w", err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("zip snapshot: %w", err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("write snapshot: %w", err)
+	}
+	return nil
+}
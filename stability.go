@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+// stabilityStats summarizes how consistent one provider's answers are across
+// repeated runs of the same query. Similarities are over successful runs.
+type stabilityStats struct {
+	Provider    Provider
+	Runs        int
+	Errors      int
+	CiteJaccard float64 // Mean pairwise Jaccard similarity of citation sets
+	CiteMin     float64 // Least similar pair of citation sets
+	TextOverlap float64 // Mean pairwise Jaccard similarity of answer word sets
+	Drift       float64 // Mean share of a run's sources that the previous run didn't cite
+	Core        int     // Sources cited in every successful run
+	Sources     int     // Distinct sources across all successful runs
+}
+
+// runRepeat runs query n times against each provider and prints a stability
+// table. Providers run in parallel; runs for one provider are sequential.
+func runRepeat(ctx context.Context, names []string, query string, n int) {
+	available, statuses := checkProviders(names)
+	printAuthTable(statuses)
+	warnUnsupportedFlags(available)
+
+	if len(available) == 0 {
+		failNoProviders(statuses)
+	}
+
+	fmt.Printf("🔁 Repeating query %d times on %d providers to measure stability...\n", n, len(available))
+	fmt.Println(strings.Repeat("═", 65))
+	fmt.Println()
+
+	stats := make([]stabilityStats, len(available))
+	var wg sync.WaitGroup
+	for i, p := range available {
+		wg.Add(1)
+		go func(i int, p Provider) {
+			defer wg.Done()
+			results := make([]Result, 0, n)
+			for run := range n {
+				r := queryWithHistory(ctx, p, query)
+				slog.Debug("repeat run", "provider", p.Name(), "run", run+1, "citations", len(r.Citations), "error", r.Error)
+				results = append(results, r)
+			}
+			stats[i] = summarizeStability(p, results)
+		}(i, p)
+	}
+	wg.Wait()
+
+	printStabilityTable(stats)
+}
+
+// summarizeStability compares every pair of successful runs.
+func summarizeStability(p Provider, results []Result) stabilityStats {
+	s := stabilityStats{Provider: p, Runs: len(results)}
+
+	var cites, words []map[string]bool
+	for _, r := range results {
+		if r.Error != nil {
+			s.Errors++
+			continue
+		}
+		set := make(map[string]bool)
+		for _, c := range r.Citations {
+			set[normalizeURL(c.URL)] = true
+		}
+		cites = append(cites, set)
+		words = append(words, wordSet(stripThinkingTags(r.Text)))
+	}
+	if len(cites) == 0 {
+		return s
+	}
+
+	all := make(map[string]int)
+	for _, set := range cites {
+		for k := range set {
+			all[k]++
+		}
+	}
+	s.Sources = len(all)
+	for _, count := range all {
+		if count == len(cites) {
+			s.Core++
+		}
+	}
+
+	if len(cites) < 2 {
+		s.CiteJaccard, s.CiteMin, s.TextOverlap = 1, 1, 1
+		return s
+	}
+
+	pairs := 0
+	s.CiteMin = 1
+	for i := range cites {
+		for j := i + 1; j < len(cites); j++ {
+			cj := jaccard(cites[i], cites[j])
+			s.CiteJaccard += cj
+			s.CiteMin = min(s.CiteMin, cj)
+			s.TextOverlap += jaccard(words[i], words[j])
+			pairs++
+		}
+	}
+	s.CiteJaccard /= float64(pairs)
+	s.TextOverlap /= float64(pairs)
+
+	for i := 1; i < len(cites); i++ {
+		if len(cites[i]) == 0 {
+			continue
+		}
+		added := 0
+		for k := range cites[i] {
+			if !cites[i-1][k] {
+				added++
+			}
+		}
+		s.Drift += float64(added) / float64(len(cites[i]))
+	}
+	s.Drift /= float64(len(cites) - 1)
+
+	return s
+}
+
+// jaccard returns |a∩b| / |a∪b|, or 1 when both sets are empty.
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1
+	}
+	inter := 0
+	for k := range a {
+		if b[k] {
+			inter++
+		}
+	}
+	return float64(inter) / float64(len(a)+len(b)-inter)
+}
+
+// wordSet returns the distinct lowercased words in text.
+func wordSet(text string) map[string]bool {
+	set := make(map[string]bool)
+	for _, w := range strings.Fields(normalizeTitle(text)) {
+		set[w] = true
+	}
+	return set
+}
+
+func printStabilityTable(stats []stabilityStats) {
+	fmt.Println("╔══════════════════════════════════════════════════════════════════════════════════════╗")
+	fmt.Println("║                                     STABILITY                                        ║")
+	fmt.Println("╠══════════════════════════════════════════════════════════════════════════════════════╣")
+	fmt.Printf("║ %-27s %4s %3s %13s %9s %7s %15s ║\n", "Model", "Runs", "Err", "Cites (μ/min)", "Text", "Drift", "Core/Sources")
+	fmt.Println("╠══════════════════════════════════════════════════════════════════════════════════════╣")
+
+	pct := func(f float64) string { return fmt.Sprintf("%.0f%%", f*100) }
+	for _, s := range stats {
+		name := fmt.Sprintf("%s %s", s.Provider.Emoji(), s.Provider.DisplayName())
+		if s.Errors == s.Runs {
+			fmt.Printf("║ %-27s %4d %3d   %-46s ║\n", name, s.Runs, s.Errors, "all runs failed")
+			continue
+		}
+		fmt.Printf("║ %-27s %4d %3d %13s %9s %7s %15s ║\n",
+			name, s.Runs, s.Errors, pct(s.CiteJaccard)+"/"+pct(s.CiteMin), pct(s.TextOverlap), pct(s.Drift),
+			fmt.Sprintf("%d/%d", s.Core, s.Sources))
+	}
+
+	fmt.Println("╠══════════════════════════════════════════════════════════════════════════════════════╣")
+	fmt.Println("║ Cites/Text: pairwise Jaccard similarity. Drift: new sources vs. the previous run.    ║")
+	fmt.Println("║ Core: sources cited in every successful run. Higher similarity = more consistent.    ║")
+	fmt.Println("╚══════════════════════════════════════════════════════════════════════════════════════╝")
+	fmt.Println()
+}
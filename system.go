@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// systemPrompt is the shared -system / -system-file instruction, sent to each
+// provider in its native system slot. Empty sends none.
+var systemPrompt string
+
+// loadSystemPrompt returns the -system text, or the contents of -system-file.
+func loadSystemPrompt(text, file string) (string, error) {
+	if text != "" && file != "" {
+		return "", fmt.Errorf("use either -system or -system-file, not both")
+	}
+	if file == "" {
+		return strings.TrimSpace(text), nil
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return "", fmt.Errorf("read system prompt: %w", err)
+	}
+	prompt := strings.TrimSpace(string(data))
+	if prompt == "" {
+		return "", fmt.Errorf("system prompt file %s is empty", file)
+	}
+	return prompt, nil
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Timeline step kinds.
+const (
+	StepSearch   = "search"   // A web search the model ran
+	StepTool     = "tool"     // Another tool call (page fetch, grounding tool, ...)
+	StepGenerate = "generate" // A model turn, where the provider is driven turn by turn
+)
+
+// TimelineStep is one search, tool call, or model turn within a query, in the
+// order it happened. Most APIs run their tools server-side and report the
+// steps without timing; Duration is 0 then.
+type TimelineStep struct {
+	Kind     string
+	Detail   string // Search query, tool name, or turn label
+	Duration time.Duration
+}
+
+// addSearchStep records a web search in r's timeline and SearchCount.
+func addSearchStep(r *Result, query string, d time.Duration) {
+	r.SearchCount++
+	r.Timeline = append(r.Timeline, TimelineStep{Kind: StepSearch, Detail: query, Duration: d})
+}
+
+// addTimelineStep records a non-search step in r's timeline.
+func addTimelineStep(r *Result, kind, detail string, d time.Duration) {
+	r.Timeline = append(r.Timeline, TimelineStep{Kind: kind, Detail: detail, Duration: d})
+}
+
+// stepIcon returns the emoji for a timeline step kind.
+func stepIcon(kind string) string {
+	switch kind {
+	case StepSearch:
+		return "🔍"
+	case StepGenerate:
+		return "✍️"
+	}
+	return "🔧"
+}
+
+// printTimeline shows r's steps under -vv, with the time accounted for by
+// timed steps so searching and generating can be told apart.
+func printTimeline(r Result) {
+	if verbosity < VerbosityDebug || len(r.Timeline) == 0 {
+		return
+	}
+	var timed time.Duration
+	for _, s := range r.Timeline {
+		timed += s.Duration
+	}
+	summary := fmt.Sprintf("%d %s, %d %s", r.SearchCount, plural(r.SearchCount, "search", "searches"),
+		len(r.Timeline), plural(len(r.Timeline), "step", "steps"))
+	if timed >= time.Millisecond {
+		summary += fmt.Sprintf("; %s of %s timed", timed.Round(time.Millisecond), r.Duration.Round(time.Millisecond))
+	}
+	fmt.Printf("│ ⏱️  Timeline (%s):\n", summary)
+	for i, s := range r.Timeline {
+		line := fmt.Sprintf("%d. %s %s", i+1, stepIcon(s.Kind), s.Kind)
+		switch {
+		case s.Kind == StepSearch && s.Detail != "":
+			line += fmt.Sprintf(" %q", truncateRunes(strings.TrimSpace(s.Detail), 60))
+		case s.Detail != "":
+			line += " " + s.Detail
+		}
+		if s.Duration >= time.Millisecond {
+			line += " " + dim(s.Duration.Round(time.Millisecond).String())
+		}
+		fmt.Printf("│    %s\n", line)
+	}
+}
+
+// plural picks the singular or plural form for n.
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
+
+// truncateRunes shortens s to at most n runes, ending in "..." when cut.
+func truncateRunes(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n-3]) + "..."
+	}
+	return s
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// defaultQueryTimeout bounds a query for providers that don't declare their own.
+const defaultQueryTimeout = 2 * time.Minute
+
+// queryTimeout is set by -timeout; when non-zero it replaces every provider's
+// default.
+var queryTimeout time.Duration
+
+// TimeoutProvider is implemented by providers whose queries legitimately take
+// longer (or should give up sooner) than defaultQueryTimeout, e.g. Nova
+// grounding or Grok's multi-step search.
+type TimeoutProvider interface {
+	DefaultTimeout() time.Duration
+}
+
+// providerTimeout returns how long one query to p may take: -timeout if set,
+// else p's DefaultTimeout, else defaultQueryTimeout.
+func providerTimeout(p Provider) time.Duration {
+	if queryTimeout > 0 {
+		return queryTimeout
+	}
+	if tp, ok := p.(TimeoutProvider); ok {
+		if d := tp.DefaultTimeout(); d > 0 {
+			return d
+		}
+	}
+	return defaultQueryTimeout
+}
+
+// withProviderTimeout runs query under p's timeout. A query cut off by the
+// deadline reports how long it was given, so a slow provider isn't mistaken
+// for a broken one.
+func withProviderTimeout(ctx context.Context, p Provider, query func(context.Context) Result) Result {
+	d := providerTimeout(p)
+	ctx, cancel := context.WithTimeout(ctx, d)
+	defer cancel()
+
+	r := query(ctx)
+	if r.Error != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		r.Error = fmt.Errorf("timed out after %v (-timeout to raise): %w", d, r.Error)
+	}
+	return r
+}
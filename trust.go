@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// TrustTier is a coarse credibility label for a citation's domain, shown next
+// to sources for quick scanning and passed to the judge. It says nothing about
+// an unlisted domain: TrustUnknown is not a penalty.
+type TrustTier string
+
+const (
+	TrustUnknown TrustTier = ""
+	TrustGov     TrustTier = "gov"     // Government and military sites
+	TrustEdu     TrustTier = "edu"     // Universities and academic institutions
+	TrustNews    TrustTier = "news"    // Major news organizations
+	TrustTrusted TrustTier = "trusted" // A team's own allowlist (-trust-file)
+)
+
+// Marker returns the emoji shown before a source of this tier, or "".
+func (t TrustTier) Marker() string {
+	switch t {
+	case TrustGov:
+		return "🏛️"
+	case TrustEdu:
+		return "🎓"
+	case TrustNews:
+		return "📰"
+	case TrustTrusted:
+		return "⭐"
+	}
+	return ""
+}
+
+// trustTiers lists every tier a -trust-file may name, in lookup order.
+var trustTiers = []TrustTier{TrustTrusted, TrustGov, TrustEdu, TrustNews}
+
+// trustDomains holds explicit domains per tier; subdomains match too. The
+// built-in news list is extended (not replaced) by -trust-file.
+var trustDomains = map[TrustTier][]string{
+	TrustNews: {
+		"apnews.com", "reuters.com", "bbc.com", "bbc.co.uk", "nytimes.com",
+		"wsj.com", "washingtonpost.com", "ft.com", "bloomberg.com",
+		"economist.com", "theguardian.com", "npr.org", "cnbc.com", "cnn.com",
+		"axios.com", "politico.com", "latimes.com", "usatoday.com",
+		"aljazeera.com", "lemonde.fr", "spiegel.de", "nikkei.com",
+	},
+}
+
+// trustSuffixes are public-suffix labels that identify gov and edu sites
+// without listing them ("nasa.gov", "ox.ac.uk", "canada.gc.ca").
+var trustSuffixes = map[TrustTier][]string{
+	TrustGov: {"gov", "mil", "gov.uk", "gov.au", "gc.ca", "gov.in", "gouv.fr", "europa.eu"},
+	TrustEdu: {"edu", "ac.uk", "edu.au", "ac.jp", "edu.cn"},
+}
+
+// trustTier classifies domain. Explicit lists win over suffix rules, so a
+// -trust-file can mark a .edu blog as trusted or a news site as gov.
+func trustTier(domain string) TrustTier {
+	domain = strings.TrimPrefix(strings.ToLower(domain), "www.")
+	if domain == "" {
+		return TrustUnknown
+	}
+	for _, t := range trustTiers {
+		if domainMatches(domain, trustDomains[t]) {
+			return t
+		}
+	}
+	for _, t := range []TrustTier{TrustGov, TrustEdu} {
+		if domainMatches(domain, trustSuffixes[t]) {
+			return t
+		}
+	}
+	return TrustUnknown
+}
+
+// Trust returns the citation's trust tier, from its domain or URL.
+func (c Citation) Trust() TrustTier {
+	domain := c.Domain
+	if domain == "" {
+		domain = domainFromURL(c.URL)
+	}
+	return trustTier(domain)
+}
+
+// trustLabel formats c's tier marker followed by a space, or "" when unknown.
+func trustLabel(c Citation) string {
+	if m := c.Trust().Marker(); m != "" {
+		return m + " "
+	}
+	return ""
+}
+
+// loadTrustFile adds a team's domains from a -trust-file, a YAML map of tier
+// to domain list:
+//
+//	trusted: [internal.example.com, arxiv.org]
+//	news: [theinformation.com]
+func loadTrustFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read trust file: %w", err)
+	}
+	var file map[string][]string
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("parse trust file %s: %w", path, err)
+	}
+	for name, list := range file {
+		tier := TrustTier(name)
+		if tier.Marker() == "" {
+			return fmt.Errorf("trust file %s: unknown tier %q (use trusted, gov, edu, news)", path, name)
+		}
+		domains, err := parseDomainList(strings.Join(list, ","))
+		if err != nil {
+			return fmt.Errorf("trust file %s: %s: %w", path, name, err)
+		}
+		trustDomains[tier] = append(trustDomains[tier], domains...)
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ModelVariantProvider is implemented by providers that can run another model
+// from the same API, so -compare-models-same-provider can register several
+// instances of one provider side by side.
+type ModelVariantProvider interface {
+	// WithModel returns a new instance registered as "<name>:<label>" that
+	// queries model instead of the provider's default.
+	WithModel(label, model string) (Provider, error)
+}
+
+// modelVariant is embedded in providers that implement ModelVariantProvider.
+// The zero value is the provider's default registration.
+type modelVariant struct {
+	label string // Registry suffix after "<name>:"
+	model string // Model ID to query
+}
+
+// name returns base, or "base:label" for a variant.
+func (v modelVariant) name(base string) string {
+	if v.label == "" {
+		return base
+	}
+	return base + ":" + v.label
+}
+
+// displayName returns base, or the variant's registry name ("claude:opus"),
+// which keeps every instance distinct in the summary and for the judge.
+func (v modelVariant) displayName(name, base string) string {
+	if v.label == "" {
+		return base
+	}
+	return name
+}
+
+// modelOr returns the variant's model, or def for the default registration.
+func (v modelVariant) modelOr(def string) string {
+	if v.model == "" {
+		return def
+	}
+	return v.model
+}
+
+// variantLabelRegex is what a variant label may look like: it becomes part
+// of the provider name used in -providers and -dump-dir file names.
+var variantLabelRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+
+// modelPricing is per-million-token pricing (USD) for models other than each
+// provider's default, which is in Pricing. Variants of models not listed here
+// need an "@input/output" price in the -compare-models-same-provider entry.
+var modelPricing = map[string]struct{ Input, Output, CachedInput float64 }{
+	"claude-opus-4-1":            {15.00, 75.00, 1.50},
+	"claude-opus-4-1-20250805":   {15.00, 75.00, 1.50},
+	"claude-opus-4-5":            {5.00, 25.00, 0.50},
+	"claude-opus-4-5-20251101":   {5.00, 25.00, 0.50},
+	"claude-sonnet-4-5":          {3.00, 15.00, 0.30},
+	"claude-sonnet-4-5-20250929": {3.00, 15.00, 0.30},
+	"claude-haiku-4-5":           {1.00, 5.00, 0.10},
+	"claude-haiku-4-5-20251001":  {1.00, 5.00, 0.10},
+	"gemini-2.5-pro":             {1.25, 10.00, 0.125}, // Prompts up to 200k tokens
+	"gemini-2.5-flash":           {0.30, 2.50, 0.03},
+	"grok-4-fast-reasoning":      {0.20, 0.50, 0.05},
+	"grok-4-fast-non-reasoning":  {0.20, 0.50, 0.05},
+	"grok-3-mini":                {0.30, 0.50, 0.075},
+	"mistral-small-latest":       {0.10, 0.30, 0},
+	"us.amazon.nova-pro-v1:0":    {0.80, 3.20, 0},
+	"us.amazon.nova-lite-v1:0":   {0.06, 0.24, 0},
+	"command-a-03-2025":          {2.50, 10.00, 0},
+	"command-r-08-2024":          {0.15, 0.60, 0},
+}
+
+// unpricedVariants holds variants whose model has no known token price; their
+// cost is shown as unknown rather than guessed.
+var unpricedVariants = make(map[string]bool)
+
+// costUnknown reports whether provider's token cost can't be estimated.
+func costUnknown(provider string) bool {
+	return unpricedVariants[provider]
+}
+
+// variantPriceRegex matches an "@input/output" price suffix in USD per
+// million tokens. Model IDs may contain '@' themselves (Vertex versions).
+var variantPriceRegex = regexp.MustCompile(`@(\d+(?:\.\d+)?)/(\d+(?:\.\d+)?)$`)
+
+// registerModelVariants parses a -compare-models-same-provider list of
+// "provider:label=model" entries ("provider:model" uses the model ID as the
+// label), registers an instance for each, and returns their names. A
+// "model@input/output" suffix sets the price per million tokens; otherwise it
+// comes from modelPricing, or the base provider's when the model is its
+// default or the provider is free. Any other variant's token cost is unknown.
+func registerModelVariants(spec string) ([]string, error) {
+	var names []string
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		base, rest, ok := strings.Cut(entry, ":")
+		if !ok || rest == "" {
+			return nil, fmt.Errorf("%q: want provider:label=model or provider:model", entry)
+		}
+		var price *struct{ Input, Output, CachedInput float64 }
+		if m := variantPriceRegex.FindStringSubmatch(rest); m != nil {
+			in, _ := strconv.ParseFloat(m[1], 64)
+			out, _ := strconv.ParseFloat(m[2], 64)
+			price = &struct{ Input, Output, CachedInput float64 }{in, out, 0}
+			rest = strings.TrimSuffix(rest, m[0])
+		}
+		label, model, ok := strings.Cut(rest, "=")
+		if !ok {
+			model = rest
+		}
+		label, model = strings.ToLower(strings.TrimSpace(label)), strings.TrimSpace(model)
+		if model == "" {
+			return nil, fmt.Errorf("%q: missing model ID", entry)
+		}
+		if !variantLabelRegex.MatchString(label) {
+			return nil, fmt.Errorf("%q: label %q must be lowercase letters, digits, '.', '-' or '_'", entry, label)
+		}
+
+		p, ok := Get(base)
+		if !ok {
+			return nil, fmt.Errorf("%q: unknown provider %q (available: %s)", entry, base, strings.Join(All(), ", "))
+		}
+		vp, ok := p.(ModelVariantProvider)
+		if !ok {
+			return nil, fmt.Errorf("%q: %s doesn't support other models", entry, base)
+		}
+		variant, err := vp.WithModel(label, model)
+		if err != nil {
+			return nil, fmt.Errorf("%q: %w", entry, err)
+		}
+		name := variant.Name()
+		if _, ok := Get(name); ok {
+			return nil, fmt.Errorf("%q: %s is already a provider", entry, name)
+		}
+
+		basePrice, baseOK := Pricing[base]
+		switch known, ok := modelPricing[model]; {
+		case price != nil:
+			Pricing[name] = *price
+		case ok:
+			Pricing[name] = known
+		case baseOK && (model == modelIDOf(p) || basePrice == struct{ Input, Output, CachedInput float64 }{}):
+			Pricing[name] = basePrice
+		default:
+			unpricedVariants[name] = true
+		}
+		// Search and grounding fees are per provider, whatever the model.
+		if fee, ok := SearchCost[base]; ok {
+			SearchCost[name] = fee
+		}
+		if worst, ok := MaxTokenEstimate[base]; ok {
+			MaxTokenEstimate[name] = worst
+		}
+		Register(variant)
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no models given")
+	}
+	return names, nil
+}
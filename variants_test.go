@@ -0,0 +1,76 @@
+package main
+
+import "testing"
+
+func TestRegisterModelVariantsPricing(t *testing.T) {
+	type price = struct{ Input, Output, CachedInput float64 }
+	tests := []struct {
+		spec        string
+		name        string
+		wantModel   string
+		wantPrice   price
+		wantUnknown bool
+	}{
+		{"claude:opus=claude-opus-4-1", "claude:opus", "claude-opus-4-1", price{15, 75, 1.50}, false},
+		{"claude:sonnet=" + claudeModelID, "claude:sonnet", claudeModelID, Pricing["claude"], false},
+		{"claude:next=claude-next-5@4.5/22", "claude:next", "claude-next-5", price{4.5, 22, 0}, false},
+		{"claude:opus-cheap=claude-opus-4-1@1/2", "claude:opus-cheap", "claude-opus-4-1", price{1, 2, 0}, false},
+		{"gemini:vertex=gemini-2.5-pro@001", "gemini:vertex", "gemini-2.5-pro@001", price{}, true},
+		{"grok:future=grok-9", "grok:future", "grok-9", price{}, true},
+		{"ollama:qwen=qwen3", "ollama:qwen", "qwen3", Pricing["ollama"], false},
+		{"mistral:mistral-small-latest", "mistral:mistral-small-latest", "mistral-small-latest", price{0.10, 0.30, 0}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			names, err := registerModelVariants(tt.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() {
+				delete(providers, tt.name)
+				delete(Pricing, tt.name)
+				delete(SearchCost, tt.name)
+				delete(MaxTokenEstimate, tt.name)
+				delete(unpricedVariants, tt.name)
+			})
+			if len(names) != 1 || names[0] != tt.name {
+				t.Fatalf("names = %v, want [%s]", names, tt.name)
+			}
+			p, _ := Get(tt.name)
+			if got := modelIDOf(p); got != tt.wantModel {
+				t.Errorf("model = %q, want %q", got, tt.wantModel)
+			}
+			if got := costUnknown(tt.name); got != tt.wantUnknown {
+				t.Errorf("costUnknown = %v, want %v", got, tt.wantUnknown)
+			}
+			got, ok := Pricing[tt.name]
+			if ok == tt.wantUnknown || got != tt.wantPrice {
+				t.Errorf("Pricing = %+v (set %v), want %+v", got, ok, tt.wantPrice)
+			}
+		})
+	}
+}
+
+func TestRegisterModelVariantsErrors(t *testing.T) {
+	for _, spec := range []string{
+		"claude",
+		"claude:",
+		"claude:opus=",
+		"claude:Opus!=claude-opus-4-1",
+		"nosuch:x=y",
+		"claude:x=claude-opus-4-1,claude:x=claude-opus-4-1",
+		" , ",
+	} {
+		t.Run(spec, func(t *testing.T) {
+			t.Cleanup(func() {
+				delete(providers, "claude:x")
+				delete(Pricing, "claude:x")
+				delete(SearchCost, "claude:x")
+				delete(MaxTokenEstimate, "claude:x")
+			})
+			if _, err := registerModelVariants(spec); err == nil {
+				t.Errorf("registerModelVariants(%q) succeeded, want error", spec)
+			}
+		})
+	}
+}
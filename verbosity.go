@@ -0,0 +1,32 @@
+package main
+
+import "log/slog"
+
+// Verbosity is how much diagnostic output a run prints. Reasoning traces are
+// separate (-thinking), so raising verbosity doesn't flood the answers.
+type Verbosity int
+
+const (
+	VerbosityNormal  Verbosity = iota
+	VerbosityVerbose           // -v: timing, progress, judge weights, citation snippets
+	VerbosityDebug             // -vv: also the search queries and raw tool calls each model made
+)
+
+// verbosity is set by -v / -vv.
+var verbosity Verbosity
+
+// logSearchQuery records a web search a model ran, under -vv.
+func logSearchQuery(v Verbosity, provider, query string) {
+	if v < VerbosityDebug || query == "" {
+		return
+	}
+	slog.Debug("search query", "provider", provider, "query", query)
+}
+
+// logToolCall records a tool call a model made with its raw arguments, under -vv.
+func logToolCall(v Verbosity, provider, tool string, args any) {
+	if v < VerbosityDebug {
+		return
+	}
+	slog.Debug("tool call", "provider", provider, "tool", tool, "args", args)
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// JudgeWeights are the contributions of each dimension to JudgeScore.Overall.
+// They always sum to 1.
+type JudgeWeights struct {
+	Quality      float64
+	LinkHealth   float64
+	Diversity    float64
+	Recency      float64
+	Significance float64
+	Impact       float64
+}
+
+// judgeWeights are the active weights; -judge-weights overrides individual entries.
+var judgeWeights = JudgeWeights{
+	Quality:      0.25,
+	LinkHealth:   0.10,
+	Diversity:    0.05,
+	Recency:      0.20,
+	Significance: 0.20,
+	Impact:       0.20,
+}
+
+// fields maps -judge-weights keys to the weight they set.
+func (w *JudgeWeights) fields() map[string]*float64 {
+	return map[string]*float64{
+		"quality":      &w.Quality,
+		"links":        &w.LinkHealth,
+		"diversity":    &w.Diversity,
+		"recency":      &w.Recency,
+		"significance": &w.Significance,
+		"impact":       &w.Impact,
+	}
+}
+
+// parseJudgeWeights applies "key=value,..." overrides to base and normalizes the
+// result to sum to 1, so "recency=0.6" for breaking news works without
+// rebalancing the rest by hand.
+func parseJudgeWeights(spec string, base JudgeWeights) (JudgeWeights, error) {
+	w := base
+	fields := w.fields()
+	for _, pair := range strings.Split(spec, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		key, val, ok := strings.Cut(pair, "=")
+		if !ok {
+			return w, fmt.Errorf("invalid weight %q (want name=value)", pair)
+		}
+		field, ok := fields[strings.ToLower(strings.TrimSpace(key))]
+		if !ok {
+			return w, fmt.Errorf("unknown weight %q (use quality, links, diversity, recency, significance, impact)", key)
+		}
+		v, err := strconv.ParseFloat(strings.TrimSpace(val), 64)
+		if err != nil || v < 0 || math.IsInf(v, 0) || math.IsNaN(v) {
+			return w, fmt.Errorf("invalid weight %q: must be a non-negative number", pair)
+		}
+		*field = v
+	}
+
+	var sum float64
+	for _, f := range fields {
+		sum += *f
+	}
+	if sum == 0 {
+		return w, fmt.Errorf("weights sum to zero")
+	}
+	for _, f := range fields {
+		*f /= sum
+	}
+	return w, nil
+}
+
+// Overall combines dimension scores (each 1-10) into the weighted composite.
+func (w JudgeWeights) Overall(quality, links, diversity, recency, significance, impact int) float64 {
+	return float64(quality)*w.Quality +
+		float64(links)*w.LinkHealth +
+		float64(diversity)*w.Diversity +
+		float64(recency)*w.Recency +
+		float64(significance)*w.Significance +
+		float64(impact)*w.Impact
+}
+
+func (w JudgeWeights) String() string {
+	return fmt.Sprintf("quality %.2f | links %.2f | diversity %.2f | recency %.2f | significance %.2f | impact %.2f",
+		w.Quality, w.LinkHealth, w.Diversity, w.Recency, w.Significance, w.Impact)
+}
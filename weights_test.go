@@ -0,0 +1,70 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseJudgeWeights(t *testing.T) {
+	equal := JudgeWeights{1, 1, 1, 1, 1, 1}
+	tests := []struct {
+		spec    string
+		base    JudgeWeights
+		want    JudgeWeights
+		wantErr bool
+	}{
+		{spec: "", base: judgeWeights, want: judgeWeights},
+		{spec: "quality=1", base: equal, want: JudgeWeights{1.0 / 6, 1.0 / 6, 1.0 / 6, 1.0 / 6, 1.0 / 6, 1.0 / 6}},
+		{spec: "recency=5", base: equal, want: JudgeWeights{0.1, 0.1, 0.1, 0.5, 0.1, 0.1}},
+		{
+			spec: " Quality = 2 , links=0,diversity=0,recency=0,significance=0,impact=0 ",
+			base: equal,
+			want: JudgeWeights{Quality: 1},
+		},
+		{
+			// Defaults already sum to 1, so one override rescales the rest.
+			spec: "recency=0.6",
+			base: judgeWeights,
+			want: JudgeWeights{0.25 / 1.4, 0.10 / 1.4, 0.05 / 1.4, 0.6 / 1.4, 0.20 / 1.4, 0.20 / 1.4},
+		},
+		{spec: "quality", base: equal, wantErr: true},
+		{spec: "speed=1", base: equal, wantErr: true},
+		{spec: "quality=-1", base: equal, wantErr: true},
+		{spec: "quality=abc", base: equal, wantErr: true},
+		{spec: "quality=Inf", base: equal, wantErr: true},
+		{spec: "quality=NaN", base: equal, wantErr: true},
+		{spec: "quality=0,links=0,diversity=0,recency=0,significance=0,impact=0", base: equal, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := parseJudgeWeights(tt.spec, tt.base)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseJudgeWeights(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			gf, wf := got.fields(), tt.want.fields()
+			var sum float64
+			for key, g := range gf {
+				if math.Abs(*g-*wf[key]) > 1e-9 {
+					t.Errorf("%s = %.4f, want %.4f", key, *g, *wf[key])
+				}
+				sum += *g
+			}
+			if math.Abs(sum-1) > 1e-9 {
+				t.Errorf("weights sum to %.6f, want 1", sum)
+			}
+		})
+	}
+}
+
+func TestJudgeWeightsOverall(t *testing.T) {
+	if got := judgeWeights.Overall(10, 10, 10, 10, 10, 10); math.Abs(got-10) > 1e-9 {
+		t.Errorf("Overall(all 10) = %.4f, want 10", got)
+	}
+	w := JudgeWeights{Quality: 0.5, Recency: 0.5}
+	if got := w.Overall(8, 1, 1, 4, 1, 1); math.Abs(got-6) > 1e-9 {
+		t.Errorf("Overall = %.4f, want 6", got)
+	}
+}
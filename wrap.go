@@ -0,0 +1,95 @@
+package main
+
+import (
+	"os"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+// outputWidth forces the wrap width (-width); 0 means detect from the terminal.
+var outputWidth int
+
+// defaultWidth is used when stdout is a terminal whose size can't be read.
+const defaultWidth = 80
+
+// wrapWidth returns the terminal width to wrap to, or 0 when stdout isn't a
+// terminal so redirected output keeps the model's original lines.
+func wrapWidth() int {
+	if outputWidth > 0 {
+		return outputWidth
+	}
+	fd := int(os.Stdout.Fd())
+	if !term.IsTerminal(fd) {
+		return 0
+	}
+	if w, _, err := term.GetSize(fd); err == nil && w > 0 {
+		return w
+	}
+	return defaultWidth
+}
+
+// wrapText splits text into lines of at most width columns, breaking on spaces.
+// Continuation lines keep the original line's indentation and list marker
+// alignment. Words longer than width (URLs) are left whole. width <= 0 disables
+// wrapping.
+func wrapText(text string, width int) []string {
+	lines := strings.Split(text, "\n")
+	if width <= 0 {
+		return lines
+	}
+
+	var out []string
+	for _, line := range lines {
+		if visibleWidth(line) <= width {
+			out = append(out, line)
+			continue
+		}
+
+		hang := strings.Repeat(" ", utf8.RuneCountInString(hangingPrefix(line)))
+		current := ""
+		for _, word := range strings.Fields(line) {
+			switch {
+			case current == "":
+				current = line[:len(line)-len(strings.TrimLeft(line, " \t"))] + word
+			case visibleWidth(current)+1+visibleWidth(word) <= width:
+				current += " " + word
+			default:
+				out = append(out, current)
+				current = hang + word
+			}
+		}
+		out = append(out, current)
+	}
+	return out
+}
+
+// ansiEscapeRegex matches SGR color sequences, which take no columns.
+var ansiEscapeRegex = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+// visibleWidth counts the runes of s that display, ignoring color sequences
+// from -render and the color helpers.
+func visibleWidth(s string) int {
+	if strings.IndexByte(s, '\x1b') < 0 {
+		return utf8.RuneCountInString(s)
+	}
+	return utf8.RuneCountInString(ansiEscapeRegex.ReplaceAllString(s, ""))
+}
+
+// hangingPrefix returns the leading indentation plus any list marker ("- ", "* ",
+// "• ", "1. "), which continuation lines are indented to match.
+func hangingPrefix(line string) string {
+	indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+	rest := line[len(indent):]
+	for _, marker := range []string{"- ", "* ", "• "} {
+		if strings.HasPrefix(rest, marker) {
+			return indent + marker
+		}
+	}
+	if i := strings.Index(rest, ". "); i > 0 && i <= 3 && strings.Trim(rest[:i], "0123456789") == "" {
+		return indent + rest[:i+2]
+	}
+	return indent
+}